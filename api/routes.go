@@ -1,41 +1,94 @@
 package api
 
 import (
+	"demerzel-badges/internal/auth"
 	"demerzel-badges/internal/handlers"
 	"demerzel-badges/internal/middleware"
-	"os"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRoutes() *gin.Engine {
-	r := gin.New()
-
-	if os.Getenv("APP_ENV") == "prod" {
+func SetupRoutes(cfg Config, authClient auth.Client, h *handlers.Handlers) *gin.Engine {
+	if cfg.Release {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	r := gin.New()
+
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
 	r.Use(cors.New(cors.Config{
 		AllowAllOrigins:  true,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization", "Idempotency-Key"},
+		ExposeHeaders:    []string{"Idempotent-Replayed", "ETag"},
 		AllowCredentials: false,
 		MaxAge:           12 * time.Hour,
 	}))
 
 	r.GET("/api/badges/health", handlers.HealthHandler)
 
+	perms := cfg.Permissions
+
 	// All other API routes should be mounted on this route group
 	apiRoutes := r.Group("/api/badges")
-	apiRoutes.POST("/badges", handlers.CreateBadgeHandler)
-	apiRoutes.GET("/user/badges", middleware.CanViewBadge(), handlers.GetBadgesForUserHandler)
-	apiRoutes.POST("/user/badges", middleware.CanAssignBadge(), handlers.AssignBadgeHandler)
-	apiRoutes.GET("/user/badges/skill/:skillId", middleware.CanViewBadge(), handlers.GetUserBadgeBySkill)
-	apiRoutes.GET("/badges/:badge_id", middleware.CanViewBadge(), handlers.GetUserBadgeByIDHandler)
+	apiRoutes.POST("/badges", middleware.RequirePermission(authClient, perms.BadgeCreate), h.CreateBadgeHandler)
+	apiRoutes.GET("/badges", middleware.RequirePermission(authClient, perms.BadgeRead), h.ListBadgesHandler)
+	apiRoutes.GET("/badges/:badge_id", middleware.RequirePermission(authClient, perms.BadgeRead), h.GetBadgeHandler)
+	apiRoutes.PATCH("/badges/:badge_id", middleware.RequirePermission(authClient, perms.BadgeUpdate), h.UpdateBadgeHandler)
+	apiRoutes.DELETE("/badges/:badge_id", middleware.RequirePermission(authClient, perms.BadgeDelete), h.DeleteBadgeHandler)
+	apiRoutes.GET("/badges/:badge_id/image.svg", h.BadgeImageSVGHandler)
+	apiRoutes.GET("/badges/:badge_id/image.png", h.BadgeImagePNGHandler)
+	apiRoutes.GET("/skills/:skill_id/ladder", middleware.RequirePermission(authClient, perms.BadgeRead), h.GetSkillLadderHandler)
+	apiRoutes.PATCH("/skills/:skill_id/ladder", middleware.RequirePermission(authClient, perms.BadgeUpdate), h.UpdateSkillLadderHandler)
+	apiRoutes.GET("/tiers", middleware.RequirePermission(authClient, perms.BadgeRead), h.ListTiersHandler)
+	apiRoutes.POST("/tiers", middleware.RequirePermission(authClient, perms.TierManage), h.CreateTierHandler)
+	apiRoutes.PATCH("/tiers/:tier_id", middleware.RequirePermission(authClient, perms.TierManage), h.UpdateTierHandler)
+	apiRoutes.DELETE("/tiers/:tier_id", middleware.RequirePermission(authClient, perms.TierManage), h.DeleteTierHandler)
+	apiRoutes.GET("/user/badges", middleware.RequirePermission(authClient, perms.BadgeRead), h.GetBadgesForUserHandler)
+	apiRoutes.POST("/user/badges", middleware.RequirePermission(authClient, perms.BadgeAssign), middleware.Idempotent(h.IdempotencyKeys), h.AssignBadgeHandler)
+	apiRoutes.GET("/user/badges/skill/:skillId", middleware.RequirePermission(authClient, perms.BadgeRead), h.GetUserBadgeBySkill)
+	apiRoutes.GET("/user/badges/:badge_id", middleware.RequirePermission(authClient, perms.BadgeRead), h.GetUserBadgeByIDHandler)
+	apiRoutes.POST("/user/badges/:badge_id/revoke", middleware.RequirePermission(authClient, perms.BadgeRevoke), h.RevokeUserBadgeHandler)
+	apiRoutes.GET("/badges/verify/:id", h.VerifyUserBadgeHandler)
+
+	// Open Badges 2.0 hosted documents are public so backpacks can fetch them
+	apiRoutes.GET("/openbadges/issuer", h.OpenBadgesIssuerHandler)
+	apiRoutes.GET("/openbadges/badges/:badge_id", h.OpenBadgesBadgeClassHandler)
+	apiRoutes.GET("/openbadges/assertions/:assertion_id", h.OpenBadgesAssertionHandler)
+	apiRoutes.GET("/openbadges/assertions/:assertion_id/baked.png", h.OpenBadgesBakedPNGHandler)
+	apiRoutes.GET("/openbadges/assertions/:assertion_id/baked.svg", h.OpenBadgesBakedSVGHandler)
+	apiRoutes.POST("/openbadges/unbake", h.UnbakeBadgeHandler)
+
+	// Open Badges 3.0 verifiable credentials
+	apiRoutes.GET("/credentials/:credential_id", h.GetCredentialHandler)
+	apiRoutes.POST("/credentials/verify", h.VerifyCredentialHandler)
+
+	// Outbox of the mails sent by the service
+	apiRoutes.GET("/admin/outbox", middleware.RequirePermission(authClient, perms.OutboxManage), h.ListOutboxHandler)
+	apiRoutes.POST("/admin/outbox/:mail_id/replay", middleware.RequirePermission(authClient, perms.OutboxManage), h.ReplayOutboxHandler)
+
+	// Email templates
+	apiRoutes.GET("/admin/templates/:name/preview", middleware.RequirePermission(authClient, perms.TemplateManage), h.PreviewTemplateHandler)
+
+	// Roles and permissions of the local authorization engine
+	apiRoutes.GET("/admin/roles", middleware.RequirePermission(authClient, perms.RoleManage), h.ListRolesHandler)
+	apiRoutes.POST("/admin/roles", middleware.RequirePermission(authClient, perms.RoleManage), h.CreateRoleHandler)
+	apiRoutes.DELETE("/admin/roles/:role_id", middleware.RequirePermission(authClient, perms.RoleManage), h.DeleteRoleHandler)
+	apiRoutes.POST("/admin/roles/:role_id/permissions", middleware.RequirePermission(authClient, perms.RoleManage), h.GrantRolePermissionHandler)
+	apiRoutes.DELETE("/admin/roles/:role_id/permissions/:permission", middleware.RequirePermission(authClient, perms.RoleManage), h.RevokeRolePermissionHandler)
+	apiRoutes.GET("/admin/users/:user_id/permissions", middleware.RequirePermission(authClient, perms.RoleManage), h.GetUserGrantsHandler)
+	apiRoutes.POST("/admin/users/:user_id/roles", middleware.RequirePermission(authClient, perms.RoleManage), h.AssignUserRoleHandler)
+	apiRoutes.DELETE("/admin/users/:user_id/roles/:role_id", middleware.RequirePermission(authClient, perms.RoleManage), h.UnassignUserRoleHandler)
+	apiRoutes.POST("/admin/users/:user_id/permissions", middleware.RequirePermission(authClient, perms.RoleManage), h.GrantUserPermissionHandler)
+	apiRoutes.DELETE("/admin/users/:user_id/permissions/:permission", middleware.RequirePermission(authClient, perms.RoleManage), h.RevokeUserPermissionHandler)
+
+	// API keys of the services calling the API
+	apiRoutes.GET("/admin/api-keys", middleware.RequirePermission(authClient, perms.APIKeyManage), h.ListAPIKeysHandler)
+	apiRoutes.POST("/admin/api-keys", middleware.RequirePermission(authClient, perms.APIKeyManage), h.CreateAPIKeyHandler)
+	apiRoutes.DELETE("/admin/api-keys/:key_id", middleware.RequirePermission(authClient, perms.APIKeyManage), h.RevokeAPIKeyHandler)
 
 	return r
 }
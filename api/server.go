@@ -1,10 +1,19 @@
 package api
 
 import (
+	"demerzel-badges/internal/middleware"
 	"fmt"
 	"net/http"
 )
 
+// Config is the configuration of the HTTP server. Release runs gin in
+// release mode, and Permissions names the permissions the routes require.
+type Config struct {
+	Port        uint16
+	Release     bool
+	Permissions middleware.Permissions
+}
+
 type Server struct {
 	s *http.Server
 }
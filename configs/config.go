@@ -1,18 +1,295 @@
+// Package configs loads the configuration of the service. Every setting is
+// read once, at startup, from the environment, the .env file of the working
+// directory and an optional YAML file, over the defaults below, and handed to
+// the packages that need it.
 package configs
 
 import (
+	"demerzel-badges/api"
+	"demerzel-badges/internal/auth"
+	"demerzel-badges/internal/credentials"
 	"demerzel-badges/internal/db"
+	"demerzel-badges/internal/handlers"
+	"demerzel-badges/internal/middleware"
+	"demerzel-badges/internal/notifier"
+	"demerzel-badges/internal/openbadges"
+	"demerzel-badges/internal/outbox"
+	"demerzel-badges/internal/rbac"
+	"demerzel-badges/internal/templates"
+	"demerzel-badges/internal/urls"
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/joho/godotenv"
 )
 
-func Load() {
-	// Load env variables
+// defaultConfigFile is the YAML file read when CONFIG_FILE is not set, if it
+// exists.
+const defaultConfigFile = "config.yaml"
+
+// Config is the configuration of the service.
+type Config struct {
+	// Env is ENV, or APP_ENV for older deployments.
+	Env string
+
+	Server    api.Config
+	Database  db.Config
+	Auth      rbac.Config
+	Notifier  notifier.Config
+	Templates templates.Config
+	Outbox    outbox.Config
+	Handlers  handlers.Config
+}
+
+func (c *Config) Production() bool {
+	return isProduction(c.Env)
+}
+
+func isProduction(env string) bool {
+	return env == "production" || env == "prod"
+}
+
+// ValidationError lists every missing or invalid setting.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration: " + strings.Join(e.Problems, "; ")
+}
+
+// Load reads the configuration. Variables of the environment take precedence
+// over those of the .env file, which take precedence over the YAML file named
+// by CONFIG_FILE, config.yaml by default. A secret can also be read from the
+// file named by its variable suffixed with _FILE, such as
+// POSTGRES_PASSWORD_FILE.
+func Load() (*Config, error) {
+	src, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(src)
+}
+
+// LoadDatabase reads the database configuration alone, from the same
+// sources as Load, for the commands that only manage the schema and should
+// not need the settings of the server.
+func LoadDatabase() (*db.Config, error) {
+	src, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseDatabase(src)
+}
+
+func load() (source, error) {
+	// The .env file does not override the variables already set
 	err := godotenv.Load()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return source{}, fmt.Errorf(".env: %w", err)
+	}
+
+	path, required := os.Getenv("CONFIG_FILE"), true
+	if path == "" {
+		path, required = defaultConfigFile, false
+	}
+
+	file, err := readFile(path, required)
 	if err != nil {
-		fmt.Printf("error: cannot find .env file in the project root")
+		return source{}, err
+	}
+
+	return source{env: os.LookupEnv, file: file}, nil
+}
+
+// parse builds the configuration of src, and fails with a ValidationError
+// listing all its problems.
+func parse(src source) (*Config, error) {
+	l := &loader{source: src}
+
+	env := l.env()
+	production := isProduction(env)
+
+	cfg := &Config{Env: env}
+
+	cfg.Server = api.Config{
+		Port:        uint16(l.port("PORT", 8080)),
+		Release:     production,
+		Permissions: l.permissions(),
+	}
+
+	cfg.Database = l.database(production)
+
+	cfg.Auth = rbac.Config{
+		Auth: auth.Config{
+			Mode:             l.oneOf("AUTH_MODE", auth.ModeRemote, auth.ModeJWT, auth.ModeJWTRemote, rbac.ModeRBAC),
+			URL:              l.url("AUTH_URL", "https://staging.zuri.team/api/auth"),
+			Timeout:          l.duration("AUTH_TIMEOUT", 5*time.Second),
+			CacheTTL:         l.duration("AUTH_CACHE_TTL", 30*time.Second),
+			JWKSURL:          l.url("AUTH_JWKS_URL", ""),
+			JWKSFile:         l.string("AUTH_JWKS_FILE", ""),
+			JWKSRefresh:      l.duration("AUTH_JWKS_REFRESH", 10*time.Minute),
+			Issuer:           l.string("AUTH_JWT_ISSUER", ""),
+			Audience:         l.string("AUTH_JWT_AUDIENCE", ""),
+			UserClaim:        l.string("AUTH_JWT_USER_CLAIM", "sub"),
+			PermissionsClaim: l.string("AUTH_JWT_PERMISSIONS_CLAIM", "permissions"),
+			RolesClaim:       l.string("AUTH_JWT_ROLES_CLAIM", "roles"),
+			AdminRoles:       l.list("AUTH_JWT_ADMIN_ROLES", "admin,super_admin"),
+		},
+		CacheTTL: l.duration("RBAC_CACHE_TTL", time.Minute),
+	}
+
+	if cfg.Auth.Auth.Mode != auth.ModeRemote && cfg.Auth.Auth.JWKSURL == "" && cfg.Auth.Auth.JWKSFile == "" {
+		l.problem("AUTH_JWKS_URL or AUTH_JWKS_FILE is required with AUTH_MODE=%s", cfg.Auth.Auth.Mode)
+	}
+
+	cfg.Notifier = notifier.Config{
+		Channels:          notifier.ParseChannels(l.string("NOTIFIERS", "")),
+		MessagingURL:      l.url("MESSAGING_API_URL", "https://team-titan.mrprotocoll.me/api/v1/messaging/assessment/badge"),
+		SMTPHost:          l.string("SMTP_HOST", ""),
+		SMTPPort:          strconv.Itoa(l.port("SMTP_PORT", 587)),
+		SMTPUsername:      l.string("SMTP_USERNAME", ""),
+		SMTPPassword:      l.secret("SMTP_PASSWORD"),
+		SMTPFrom:          l.string("SMTP_FROM", ""),
+		WebhookURL:        l.url("NOTIFY_WEBHOOK_URL", ""),
+		WebhookSecret:     l.secret("NOTIFY_WEBHOOK_SECRET"),
+		SlackWebhookURL:   l.secret("SLACK_WEBHOOK_URL"),
+		DiscordWebhookURL: l.secret("DISCORD_WEBHOOK_URL"),
+	}
+
+	var notifierErrs notifier.Errors
+	if err := cfg.Notifier.Validate(); errors.As(err, &notifierErrs) {
+		for _, err := range notifierErrs {
+			l.problem("%v", err)
+		}
+	}
+
+	cfg.Templates = templates.Config{
+		Dir:           l.string("EMAIL_TEMPLATES_DIR", ""),
+		DefaultLocale: l.string("EMAIL_DEFAULT_LOCALE", ""),
+	}
+
+	cfg.Outbox = outbox.Config{
+		PollInterval: l.positiveDuration("OUTBOX_POLL_INTERVAL", 5*time.Second),
+		MaxAttempts:  l.positiveInt("OUTBOX_MAX_ATTEMPTS", 8),
+		BaseDelay:    l.positiveDuration("OUTBOX_BASE_DELAY", 30*time.Second),
+		MaxDelay:     l.positiveDuration("OUTBOX_MAX_DELAY", 6*time.Hour),
+	}
+
+	cfg.Handlers = handlers.Config{
+		URLs: urls.Builder{
+			PortfolioURL: l.url("PORTFOLIO_URL", "https://zuri.team"),
+			ProfilePath:  l.string("PORTFOLIO_PROFILE_PATH", "/portfolio/{user_id}"),
+			APIURL:       l.url("PUBLIC_API_URL", ""),
+		},
+		Issuer: openbadges.Issuer{
+			Name:        l.string("OPENBADGES_ISSUER_NAME", "Zuri Portfolio"),
+			URL:         l.url("OPENBADGES_ISSUER_URL", "https://zuri.team"),
+			Email:       l.string("OPENBADGES_ISSUER_EMAIL", ""),
+			Description: l.string("OPENBADGES_ISSUER_DESCRIPTION", ""),
+			Image:       l.string("OPENBADGES_ISSUER_IMAGE", ""),
+		},
+		OpenBadgesSalt: l.secret("OPENBADGES_SALT"),
+		APIKeyScopes:   l.list("API_KEY_SCOPES", "badge.read,badge.update.any"),
+		Channels:       cfg.Notifier.Channels,
+		LadderNoGaps:   l.bool("BADGE_LADDER_NO_GAPS", false),
+		Templates:      cfg.Templates,
+	}
+
+	// Links are never derived from the Host header of requests, which the
+	// client chooses
+	if cfg.Handlers.URLs.APIURL == "" {
+		if production {
+			l.problem("PUBLIC_API_URL is required in production")
+		} else {
+			cfg.Handlers.URLs.APIURL = fmt.Sprintf("http://localhost:%d/api/badges", cfg.Server.Port)
+		}
+	}
+
+	// Assertions publish the salt of their recipient hash, derived from this
+	// secret and their ID. The secret only keeps the salts of other
+	// assertions from being derived ahead of time, a fixed one will do
+	// outside production
+	if cfg.Handlers.OpenBadgesSalt == "" {
+		if production {
+			l.problem("OPENBADGES_SALT is required in production")
+		} else {
+			cfg.Handlers.OpenBadgesSalt = "local-openbadges-salt"
+		}
+	}
+
+	if key := l.secret("CREDENTIAL_SIGNING_KEY"); strings.TrimSpace(key) != "" {
+		signingKey, err := credentials.ParsePrivateKey(key)
+		if err != nil {
+			l.problem("CREDENTIAL_SIGNING_KEY: %v", err)
+		}
+		cfg.Handlers.CredentialSigningKey = signingKey
+	}
+
+	if len(l.problems) > 0 {
+		return nil, &ValidationError{Problems: l.problems}
+	}
+
+	return cfg, nil
+}
+
+// parseDatabase builds the database configuration of src, and fails with a
+// ValidationError listing its problems.
+func parseDatabase(src source) (*db.Config, error) {
+	l := &loader{source: src}
+
+	cfg := l.database(isProduction(l.env()))
+
+	if len(l.problems) > 0 {
+		return nil, &ValidationError{Problems: l.problems}
+	}
+
+	return &cfg, nil
+}
+
+// env is ENV, or APP_ENV for older deployments.
+func (l *loader) env() string {
+	return strings.ToLower(l.string("ENV", l.string("APP_ENV", "development")))
+}
+
+func (l *loader) database(production bool) db.Config {
+	return db.Config{
+		Host:        l.required("POSTGRES_HOST"),
+		Port:        strconv.Itoa(l.port("POSTGRES_PORT", 5432)),
+		Username:    l.required("POSTGRES_USERNAME"),
+		Password:    l.secret("POSTGRES_PASSWORD"),
+		Name:        l.required("POSTGRES_DBNAME"),
+		AutoMigrate: l.bool("DB_AUTO_MIGRATE", !production),
+		SchemaCheck: l.oneOf("DB_SCHEMA_CHECK", db.SchemaCheckWarn, db.SchemaCheckStrict, db.SchemaCheckOff),
+	}
+}
+
+func (l *loader) permissions() middleware.Permissions {
+	perms := middleware.DefaultPermissions()
+
+	names := map[string]*string{
+		"PERMISSION_BADGE_READ":      &perms.BadgeRead,
+		"PERMISSION_BADGE_CREATE":    &perms.BadgeCreate,
+		"PERMISSION_BADGE_UPDATE":    &perms.BadgeUpdate,
+		"PERMISSION_BADGE_DELETE":    &perms.BadgeDelete,
+		"PERMISSION_BADGE_ASSIGN":    &perms.BadgeAssign,
+		"PERMISSION_BADGE_REVOKE":    &perms.BadgeRevoke,
+		"PERMISSION_TIER_MANAGE":     &perms.TierManage,
+		"PERMISSION_OUTBOX_MANAGE":   &perms.OutboxManage,
+		"PERMISSION_TEMPLATE_MANAGE": &perms.TemplateManage,
+		"PERMISSION_ROLE_MANAGE":     &perms.RoleManage,
+		"PERMISSION_API_KEY_MANAGE":  &perms.APIKeyManage,
+	}
+	for key, name := range names {
+		*name = l.string(key, *name)
 	}
 
-	//	TODO Setup Database connection.
-	db.SetupDB()
+	return perms
 }
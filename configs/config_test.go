@@ -0,0 +1,185 @@
+package configs
+
+import (
+	"crypto/ed25519"
+	"demerzel-badges/internal/db"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func env(values map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+}
+
+// required returns the settings without a default.
+func required() map[string]string {
+	return map[string]string{
+		"POSTGRES_HOST":     "localhost",
+		"POSTGRES_USERNAME": "badges",
+		"POSTGRES_DBNAME":   "badges",
+	}
+}
+
+func TestParseDefaults(t *testing.T) {
+	cfg, err := parse(source{env: env(required())})
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "development", cfg.Env)
+	assert.Equal(t, uint16(8080), cfg.Server.Port)
+	assert.False(t, cfg.Server.Release)
+	assert.Equal(t, "badge.read", cfg.Server.Permissions.BadgeRead)
+	assert.Equal(t, "5432", cfg.Database.Port)
+	assert.True(t, cfg.Database.AutoMigrate)
+	assert.Equal(t, db.SchemaCheckWarn, cfg.Database.SchemaCheck)
+	assert.Equal(t, "remote", cfg.Auth.Auth.Mode)
+	assert.Equal(t, 5*time.Second, cfg.Auth.Auth.Timeout)
+	assert.Equal(t, []string{"admin", "super_admin"}, cfg.Auth.Auth.AdminRoles)
+	assert.Equal(t, []string{"messaging"}, cfg.Notifier.Channels)
+	assert.NotEmpty(t, cfg.Notifier.MessagingURL)
+	assert.Equal(t, []string{"badge.read", "badge.update.any"}, cfg.Handlers.APIKeyScopes)
+	assert.Equal(t, []string{"messaging"}, cfg.Handlers.Channels)
+	assert.Equal(t, "https://zuri.team", cfg.Handlers.URLs.PortfolioURL)
+	assert.Equal(t, "http://localhost:8080/api/badges", cfg.Handlers.URLs.APIURL)
+	assert.NotEmpty(t, cfg.Handlers.OpenBadgesSalt)
+	assert.Equal(t, 8, cfg.Outbox.MaxAttempts)
+}
+
+func TestParseListsEveryProblem(t *testing.T) {
+	_, err := parse(source{env: env(map[string]string{
+		"ENV":             "production",
+		"PORT":            "eighty",
+		"AUTH_MODE":       "jwt",
+		"AUTH_TIMEOUT":    "5",
+		"NOTIFIERS":       "slack",
+		"DB_SCHEMA_CHECK": "loud",
+		"PORTFOLIO_URL":   "zuri.team",
+	})})
+
+	var invalid *ValidationError
+	if !assert.True(t, errors.As(err, &invalid)) {
+		return
+	}
+
+	assert.ElementsMatch(t, []string{
+		`PORT should be an integer, got "eighty"`,
+		"POSTGRES_HOST is required",
+		"POSTGRES_USERNAME is required",
+		"POSTGRES_DBNAME is required",
+		`DB_SCHEMA_CHECK should be one of warn, strict, off, got "loud"`,
+		`AUTH_TIMEOUT should be a duration such as 30s or 5m, got "5"`,
+		"AUTH_JWKS_URL or AUTH_JWKS_FILE is required with AUTH_MODE=jwt",
+		"slack: SLACK_WEBHOOK_URL is required",
+		`PORTFOLIO_URL should be an http or https URL, got "zuri.team"`,
+		"PUBLIC_API_URL is required in production",
+		"OPENBADGES_SALT is required in production",
+	}, invalid.Problems)
+}
+
+func TestParseDatabaseIgnoresOtherSettings(t *testing.T) {
+	values := required()
+	values["ENV"] = "production"
+	values["AUTH_MODE"] = "jwt"
+
+	cfg, err := parseDatabase(source{env: env(values)})
+	if !assert.NoError(t, err, "the settings of the server are not needed to migrate") {
+		return
+	}
+
+	assert.Equal(t, "localhost", cfg.Host)
+	assert.False(t, cfg.AutoMigrate)
+
+	_, err = parseDatabase(source{env: env(map[string]string{"POSTGRES_HOST": "localhost"})})
+
+	var invalid *ValidationError
+	if assert.True(t, errors.As(err, &invalid)) {
+		assert.ElementsMatch(t, []string{
+			"POSTGRES_USERNAME is required",
+			"POSTGRES_DBNAME is required",
+		}, invalid.Problems)
+	}
+}
+
+func TestParsePrecedence(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "password")
+	assert.NoError(t, os.WriteFile(secret, []byte("s3cret\n"), 0o600))
+
+	values := required()
+	values["APP_ENV"] = "prod"
+	values["PUBLIC_API_URL"] = "https://api.zuri.team/api/badges"
+	values["OPENBADGES_SALT"] = "pepper"
+	values["PORT"] = "9000"
+	values["POSTGRES_PASSWORD_FILE"] = secret
+	values["PERMISSION_BADGE_CREATE"] = "badges.admin"
+	values["OUTBOX_MAX_ATTEMPTS"] = ""
+	values["CREDENTIAL_SIGNING_KEY"] = "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+
+	cfg, err := parse(source{
+		env: env(values),
+		file: map[string]string{
+			"PORT":                "8000",
+			"POSTGRES_PORT":       "6543",
+			"OUTBOX_MAX_ATTEMPTS": "3",
+		},
+	})
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "prod", cfg.Env)
+	assert.True(t, cfg.Server.Release)
+	assert.False(t, cfg.Database.AutoMigrate, "migrations are not applied at startup in production")
+	assert.Equal(t, uint16(9000), cfg.Server.Port, "the environment overrides the file")
+	assert.Equal(t, "6543", cfg.Database.Port)
+	assert.Equal(t, 3, cfg.Outbox.MaxAttempts, "empty variables count as unset")
+	assert.Equal(t, "s3cret", cfg.Database.Password)
+	assert.Equal(t, "badges.admin", cfg.Server.Permissions.BadgeCreate)
+	assert.Equal(t, "badge.read", cfg.Server.Permissions.BadgeRead)
+	assert.Len(t, cfg.Handlers.CredentialSigningKey, ed25519.PrivateKeySize, "the signing key is parsed once")
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+port: 8000
+postgres:
+  host: db
+  port: 5432
+auth:
+  jwt:
+    admin_roles: [admin, owner]
+badge_ladder_no_gaps: true
+`), 0o600))
+
+	settings, err := readFile(path, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"PORT":                 "8000",
+		"POSTGRES_HOST":        "db",
+		"POSTGRES_PORT":        "5432",
+		"AUTH_JWT_ADMIN_ROLES": "admin,owner",
+		"BADGE_LADDER_NO_GAPS": "true",
+	}, settings)
+
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+
+	settings, err = readFile(missing, false)
+	assert.NoError(t, err)
+	assert.Empty(t, settings)
+
+	_, err = readFile(missing, true)
+	assert.Error(t, err)
+}
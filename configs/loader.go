@@ -0,0 +1,247 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// source holds the raw settings, env first and then file. Empty values count
+// as unset.
+type source struct {
+	env  func(key string) (string, bool)
+	file map[string]string
+}
+
+func (s source) lookup(key string) (string, bool) {
+	if s.env != nil {
+		if value, ok := s.env(key); ok && strings.TrimSpace(value) != "" {
+			return value, true
+		}
+	}
+
+	value, ok := s.file[key]
+
+	return value, ok && strings.TrimSpace(value) != ""
+}
+
+// loader reads typed settings, recording the problems instead of stopping at
+// the first one.
+type loader struct {
+	source
+	problems []string
+}
+
+func (l *loader) problem(format string, args ...interface{}) {
+	l.problems = append(l.problems, fmt.Sprintf(format, args...))
+}
+
+func (l *loader) string(key string, fallback string) string {
+	if value, ok := l.lookup(key); ok {
+		return strings.TrimSpace(value)
+	}
+
+	return fallback
+}
+
+func (l *loader) required(key string) string {
+	value := l.string(key, "")
+	if value == "" {
+		l.problem("%s is required", key)
+	}
+
+	return value
+}
+
+// secret reads key, or the file named by key_FILE.
+func (l *loader) secret(key string) string {
+	path, ok := l.lookup(key + "_FILE")
+	if !ok {
+		return l.string(key, "")
+	}
+
+	content, err := os.ReadFile(strings.TrimSpace(path))
+	if err != nil {
+		l.problem("%s_FILE: %v", key, err)
+		return ""
+	}
+
+	return strings.TrimRight(string(content), "\r\n")
+}
+
+func (l *loader) int(key string, fallback int) int {
+	value, ok := l.lookup(key)
+	if !ok {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		l.problem("%s should be an integer, got %q", key, value)
+		return fallback
+	}
+
+	return n
+}
+
+func (l *loader) positiveInt(key string, fallback int) int {
+	n := l.int(key, fallback)
+	if n <= 0 {
+		l.problem("%s should be positive, got %d", key, n)
+		return fallback
+	}
+
+	return n
+}
+
+func (l *loader) port(key string, fallback int) int {
+	port := l.int(key, fallback)
+	if port < 1 || port > 65535 {
+		l.problem("%s should be a port between 1 and 65535, got %d", key, port)
+		return fallback
+	}
+
+	return port
+}
+
+func (l *loader) bool(key string, fallback bool) bool {
+	value, ok := l.lookup(key)
+	if !ok {
+		return fallback
+	}
+
+	b, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		l.problem("%s should be true or false, got %q", key, value)
+		return fallback
+	}
+
+	return b
+}
+
+// duration reads a duration such as 30s or 5m, 0 being allowed.
+func (l *loader) duration(key string, fallback time.Duration) time.Duration {
+	value, ok := l.lookup(key)
+	if !ok {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil || d < 0 {
+		l.problem("%s should be a duration such as 30s or 5m, got %q", key, value)
+		return fallback
+	}
+
+	return d
+}
+
+func (l *loader) positiveDuration(key string, fallback time.Duration) time.Duration {
+	d := l.duration(key, fallback)
+	if d == 0 {
+		l.problem("%s should be positive", key)
+		return fallback
+	}
+
+	return d
+}
+
+// oneOf reads a lowercase value among values, the first one by default.
+func (l *loader) oneOf(key string, values ...string) string {
+	value := strings.ToLower(l.string(key, values[0]))
+	for _, allowed := range values {
+		if value == allowed {
+			return value
+		}
+	}
+
+	l.problem("%s should be one of %s, got %q", key, strings.Join(values, ", "), value)
+
+	return values[0]
+}
+
+// url reads an absolute http or https URL.
+func (l *loader) url(key string, fallback string) string {
+	value := l.string(key, fallback)
+	if value == "" {
+		return value
+	}
+
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		l.problem("%s should be an http or https URL, got %q", key, value)
+	}
+
+	return value
+}
+
+// list reads a comma separated list.
+func (l *loader) list(key string, fallback string) []string {
+	var items []string
+	for _, item := range strings.Split(l.string(key, fallback), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// readFile reads the settings of a YAML file. Nested keys are joined with
+// underscores and upper-cased, so that
+//
+//	postgres:
+//	  host: localhost
+//
+// sets POSTGRES_HOST, and lists are joined with commas. A missing file is
+// only an error when required.
+func readFile(path string, required bool) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	settings := map[string]string{}
+	flatten("", document, settings)
+
+	return settings, nil
+}
+
+func flatten(prefix string, value interface{}, settings map[string]string) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			name := strings.ToUpper(key)
+			if prefix != "" {
+				name = prefix + "_" + name
+			}
+
+			flatten(name, child, settings)
+		}
+	case []interface{}:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, fmt.Sprint(item))
+		}
+
+		settings[prefix] = strings.Join(items, ",")
+	case nil:
+	default:
+		settings[prefix] = fmt.Sprint(value)
+	}
+}
@@ -2,340 +2,903 @@ package main_test
 
 import (
 	"bytes"
+	"context"
+	"crypto/ed25519"
+	"demerzel-badges/api"
+	"demerzel-badges/internal/auth"
+	"demerzel-badges/internal/credentials"
+	"demerzel-badges/internal/handlers"
+	"demerzel-badges/internal/middleware"
+	"demerzel-badges/internal/models"
+	"demerzel-badges/internal/repository"
+	"demerzel-badges/internal/urls"
+	"encoding/json"
 	"errors"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
-
-	"demerzel-badges/internal/handlers"
-	"demerzel-badges/internal/db"
-	"demerzel-badges/internal/models"
-	"demerzel-badges/pkg/response"
 )
 
-type MockDatabase struct {
-	mock.Mock
+const testUserID = "sample_user_id"
+
+// allowAll authorizes every token as the test user.
+type allowAll struct{}
+
+func (allowAll) Authorize(ctx context.Context, token string, permission string) (*auth.Principal, error) {
+	return &auth.Principal{UserID: testUserID}, nil
 }
 
-func (m *MockDatabase) FindSkillById(skillID uint) (*models.Skill, error) {
-	args := m.Called(skillID)
-	return args.Get(0).(*models.Skill), args.Error(1)
+// failingBadges fails to create badges, as when the database is down.
+type failingBadges struct {
+	repository.BadgeRepository
 }
 
-func (m *MockDatabase) BadgeExists(skillID uint, name models.Badge) bool {
-	args := m.Called(skillID, name)
-	return args.Bool(0)
+func (failingBadges) CreateBadge(badge models.SkillBadge) (*models.SkillBadge, error) {
+	return nil, errors.New("database error")
 }
 
-func (m *MockDatabase) CreateBadge(badge models.SkillBadge) (*models.SkillBadge, error) {
-	args := m.Called(badge)
-	return args.Get(0).(*models.SkillBadge), args.Error(1)
+func memoryHandlers(store *repository.MemoryStore) *handlers.Handlers {
+	return &handlers.Handlers{
+		Badges:      store,
+		UserBadges:  store,
+		Skills:      store,
+		Assessments: store,
+
+		Mails:           store,
+		Roles:           store,
+		APIKeys:         store,
+		IdempotencyKeys: store,
+
+		Config: handlers.Config{
+			URLs:     urls.Builder{PortfolioURL: "https://zuri.team", ProfilePath: "/portfolio/{user_id}", APIURL: "http://localhost:8080/api/badges"},
+			Channels: []string{"messaging"},
+		},
+	}
 }
 
-var mockDB = new(MockDatabase)
+func serve(h *handlers.Handlers, method string, path string, payload string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := api.SetupRoutes(api.Config{Permissions: middleware.DefaultPermissions()}, allowAll{}, h)
+
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer token")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
 
-func setupRouter() *gin.Engine {
-	router := gin.Default()
-	router.POST("/api/badges", handlers.CreateBadgeHandler(mockDB))
-	return router
+	return w
 }
 
-var testPayload = []byte(`{"name": "beginner", "min_score": 0, "max_score": 50}`)
+// seedAssessment stores a skill with a beginner badge from 0 to 50 and a
+// passed assessment of the test user scoring 30.
+func seedAssessment(t *testing.T, store *repository.MemoryStore) (models.Skill, models.UserAssessment) {
+	store.AddUser(models.User{ID: testUserID, Username: "sample", Email: "sample@example.com"})
+	skill := store.AddSkill(models.Skill{CategoryName: "Go"})
 
-func TestCreateBadgeEndpoint(t *testing.T) {
-	router := setupRouter()
-	defer TestCleanup(t)
+	tier, err := store.FindTierByName(skill.ID, "beginner")
+	assert.NoError(t, err)
 
-	w := httptest.NewRecorder()
+	_, err = store.CreateBadge(models.SkillBadge{SkillID: skill.ID, TierID: tier.ID, Name: models.Badge(tier.Name), MinScore: 0, MaxScore: 50})
+	assert.NoError(t, err)
 
-	req, _ := http.NewRequest("POST", "/api/badges", bytes.NewBuffer(testPayload))
-	
-    c, _ := gin.CreateTestContext(w)
-    c.Request = req
+	assessment := store.AddAssessment(models.Assessment{SkillID: skill.ID, Status: models.Complete})
+	taken := store.AddUserAssessment(models.UserAssessment{
+		UserID:         testUserID,
+		AssessmentID:   assessment.ID,
+		Score:          30,
+		Status:         models.Complete,
+		SubmissionDate: time.Now(),
+	})
 
-	router.ServeHTTP(w, req)
+	return skill, taken
+}
+
+func TestCreateBadgeHandler_Success(t *testing.T) {
+	store := repository.NewMemoryStore()
+	skill := store.AddSkill(models.Skill{CategoryName: "Go"})
+
+	w := serve(memoryHandlers(store), http.MethodPost, "/api/badges/badges",
+		`{"skill_id": `+strconv.Itoa(int(skill.ID))+`, "name": "beginner", "min_score": 0, "max_score": 50}`)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), "Badge Created Successfully")
+	assert.True(t, store.BadgeExists(skill.ID, 1))
+}
+
+func TestCreateBadgeHandler_InvalidInput(t *testing.T) {
+	store := repository.NewMemoryStore()
+	skill := store.AddSkill(models.Skill{CategoryName: "Go"})
+
+	w := serve(memoryHandlers(store), http.MethodPost, "/api/badges/badges",
+		`{"skill_id": `+strconv.Itoa(int(skill.ID))+`, "name": "beginner", "min_score": -5, "max_score": 50}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "min_score should be at least 0")
+}
+
+func TestCreateBadgeHandler_SkillNotFound(t *testing.T) {
+	w := serve(memoryHandlers(repository.NewMemoryStore()), http.MethodPost, "/api/badges/badges",
+		`{"skill_id": 42, "name": "beginner", "min_score": 0, "max_score": 50}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "no skill found matching provided ID")
+}
+
+func TestCreateBadgeHandler_Exists(t *testing.T) {
+	store := repository.NewMemoryStore()
+	skill, _ := seedAssessment(t, store)
+
+	w := serve(memoryHandlers(store), http.MethodPost, "/api/badges/badges",
+		`{"skill_id": `+strconv.Itoa(int(skill.ID))+`, "name": "beginner", "min_score": 0, "max_score": 50}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Badge already exists")
+}
+
+func TestCreateBadgeHandler_DatabaseError(t *testing.T) {
+	store := repository.NewMemoryStore()
+	skill := store.AddSkill(models.Skill{CategoryName: "Go"})
+
+	h := memoryHandlers(store)
+	h.Badges = failingBadges{store}
+
+	w := serve(h, http.MethodPost, "/api/badges/badges",
+		`{"skill_id": `+strconv.Itoa(int(skill.ID))+`, "name": "beginner", "min_score": 0, "max_score": 50}`)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "Unable to create badge")
+}
+
+func TestAssignBadgeHandler_Success(t *testing.T) {
+	store := repository.NewMemoryStore()
+	_, taken := seedAssessment(t, store)
+
+	w := serve(memoryHandlers(store), http.MethodPost, "/api/badges/user/badges",
+		`{"assessment_id": `+strconv.Itoa(int(taken.ID))+`}`)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), "Badge Assigned Successfully")
+	assert.NotEmpty(t, store.Mails())
+
+	var body struct {
+		Data struct {
+			Outcome models.AssignOutcome `json:"outcome"`
+		} `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, models.AssignCreated, body.Data.Outcome)
+}
+
+func TestAssignBadgeHandler_AssessmentNotFound(t *testing.T) {
+	w := serve(memoryHandlers(repository.NewMemoryStore()), http.MethodPost, "/api/badges/user/badges",
+		`{"assessment_id": 42}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), models.ErrAssessmentNotFound.Error())
+}
+
+func TestAssignBadgeHandler_NotOwned(t *testing.T) {
+	store := repository.NewMemoryStore()
+	_, taken := seedAssessment(t, store)
+	taken.ID = 0
+	taken.UserID = "someone_else"
+	other := store.AddUserAssessment(taken)
+
+	w := serve(memoryHandlers(store), http.MethodPost, "/api/badges/user/badges",
+		`{"assessment_id": `+strconv.Itoa(int(other.ID))+`}`)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, store.Mails())
+}
+
+func TestGetUserBadgeBySkill_Success(t *testing.T) {
+	store := repository.NewMemoryStore()
+	skill, taken := seedAssessment(t, store)
+
+	_, _, err := store.AssignBadge(testUserID, taken.ID, nil)
+	assert.NoError(t, err)
+
+	w := serve(memoryHandlers(store), http.MethodGet, "/api/badges/user/badges/skill/"+strconv.Itoa(int(skill.ID)), "")
 
-	handlers.CreateBadgeHandler(c)
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "User Badge Retrieved Successfully")
 }
 
-func TestCreateBadgeHandler_Success(t *testing.T) {
-	mockDB := new(MockDatabase)
+func TestGetUserBadgeBySkill_NotFound(t *testing.T) {
+	store := repository.NewMemoryStore()
+	skill, _ := seedAssessment(t, store)
+
+	w := serve(memoryHandlers(store), http.MethodGet, "/api/badges/user/badges/skill/"+strconv.Itoa(int(skill.ID)), "")
 
-	router := setupRouter()
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "User Badge not Found")
+}
 
-	req, err := http.NewRequest("POST", "/api/badges", bytes.NewBuffer(testPayload))
+func TestUnbakeBadgeHandler_TooLarge(t *testing.T) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("image", "badge.png")
 	assert.NoError(t, err)
+	part.Write(bytes.Repeat([]byte{0}, 3<<20))
+	form.Close()
+
+	gin.SetMode(gin.TestMode)
+	router := api.SetupRoutes(api.Config{Permissions: middleware.DefaultPermissions()}, allowAll{}, memoryHandlers(repository.NewMemoryStore()))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/badges/openbadges/unbake", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
 
 	w := httptest.NewRecorder()
-	
-	c, _ := gin.CreateTestContext(w)
-	c.Request = req
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "Image is too large")
+}
+
+func TestCreateAPIKeyHandler_ScopeNotAllowed(t *testing.T) {
+	h := memoryHandlers(repository.NewMemoryStore())
+	h.Config.APIKeyScopes = []string{"badge.update.any"}
+
+	w := serve(h, http.MethodPost, "/api/badges/admin/api-keys", `{"name": "assessment-service", "scopes": ["badge.update.any", "apikey.manage"]}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "apikey.manage cannot be granted to an API key")
+}
+
+func TestUpdateTierHandler_BreaksLadder(t *testing.T) {
+	store := repository.NewMemoryStore()
+	skill, _ := seedAssessment(t, store)
+
+	intermediate, err := store.FindTierByName(skill.ID, "intermediate")
+	assert.NoError(t, err)
+	_, err = store.CreateBadge(models.SkillBadge{SkillID: skill.ID, TierID: intermediate.ID, Name: models.Badge(intermediate.Name), MinScore: 50, MaxScore: 80})
+	assert.NoError(t, err)
+
+	beginner, err := store.FindTierByName(skill.ID, "beginner")
+	assert.NoError(t, err)
+
+	w := serve(memoryHandlers(store), http.MethodPatch, "/api/badges/tiers/"+strconv.Itoa(int(beginner.ID)), `{"rank": 4}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid badge ladder")
 
-	mockDB.On("FindSkillById", uint(1)).Return(&models.Skill{ID: 1}, nil)
-	mockDB.On("BadgeExists", uint(1), models.Badge("beginner")).Return(false)
-	mockDB.On("CreateBadge", mock.AnythingOfType("*models.SkillBadge")).Return(nil, nil)
+	beginner, err = store.FindTierByID(beginner.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, beginner.Rank)
+}
+
+func TestAssignBadgeHandler_UnknownUser(t *testing.T) {
+	store := repository.NewMemoryStore()
+	skill := store.AddSkill(models.Skill{CategoryName: "Go"})
+	tier, err := store.FindTierByName(skill.ID, "beginner")
+	assert.NoError(t, err)
+	_, err = store.CreateBadge(models.SkillBadge{SkillID: skill.ID, TierID: tier.ID, Name: models.Badge(tier.Name), MinScore: 0, MaxScore: 50})
+	assert.NoError(t, err)
+
+	assessment := store.AddAssessment(models.Assessment{SkillID: skill.ID, Status: models.Complete})
+	taken := store.AddUserAssessment(models.UserAssessment{
+		UserID:         testUserID,
+		AssessmentID:   assessment.ID,
+		Score:          30,
+		Status:         models.Complete,
+		SubmissionDate: time.Now(),
+	})
+
+	w := serve(memoryHandlers(store), http.MethodPost, "/api/badges/user/badges",
+		`{"assessment_id": `+strconv.Itoa(int(taken.ID))+`}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "badge recipient is not a known user")
+	assert.Empty(t, store.Mails())
+}
+
+func TestListTiersHandler_Success(t *testing.T) {
+	w := serve(memoryHandlers(repository.NewMemoryStore()), http.MethodGet, "/api/badges/tiers", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var data struct {
+		Tiers []struct {
+			Name string `json:"name"`
+			Rank int    `json:"rank"`
+		} `json:"tiers"`
+	}
+	decodeData(t, w.Body.Bytes(), &data)
+	assert.Len(t, data.Tiers, len(models.DefaultTiers))
+	assert.Equal(t, "Beginner", data.Tiers[0].Name)
+}
+
+func TestListTiersHandler_InvalidSkillID(t *testing.T) {
+	w := serve(memoryHandlers(repository.NewMemoryStore()), http.MethodGet, "/api/badges/tiers?skill_id=go", "")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid skill_id")
+}
 
-	handlers.CreateBadgeHandler(c)
+func TestCreateTierHandler_Success(t *testing.T) {
+	store := repository.NewMemoryStore()
+
+	w := serve(memoryHandlers(store), http.MethodPost, "/api/badges/tiers", `{"name": "Master", "rank": 4}`)
 
 	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), "Tier Created Successfully")
 
-	assert.JSONEq(t, `{"status": "success", "message": "Badge created successfully"}`, w.Body.String())
+	tier, err := store.FindTierByName(0, "master")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, tier.Rank)
+}
 
-	mockDB.AssertExpectations(t)
+func TestCreateTierHandler_Exists(t *testing.T) {
+	w := serve(memoryHandlers(repository.NewMemoryStore()), http.MethodPost, "/api/badges/tiers", `{"name": "Novice", "rank": 1}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Tier already exists")
 }
 
-func TestCreateBadgeHandler_CreateBadgeError(t *testing.T) {
-	mockDB := new(MockDatabase)
+func TestUpdateTierHandler_Success(t *testing.T) {
+	store := repository.NewMemoryStore()
+	expert, err := store.FindTierByName(0, "expert")
+	assert.NoError(t, err)
 
-	router := setupRouter()
+	w := serve(memoryHandlers(store), http.MethodPatch, "/api/badges/tiers/"+strconv.Itoa(int(expert.ID)), `{"display_name": "Master"}`)
 
-	req, err := http.NewRequest("POST", "/api/badges", bytes.NewBuffer(testPayload))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Tier Updated Successfully")
+
+	expert, err = store.FindTierByID(expert.ID)
 	assert.NoError(t, err)
+	assert.Equal(t, "Master", expert.DisplayName)
+}
 
-	w := httptest.NewRecorder()
-	
-	c, _ := gin.CreateTestContext(w)
-	c.Request = req
-	
-	mockDB.On("FindSkillById", uint(1)).Return(&models.Skill{ID: 1}, nil)
-	mockDB.On("BadgeExists", uint(1), models.Badge("beginner")).Return(false)
-	mockDB.On("CreateBadge", mock.AnythingOfType("*models.SkillBadge")).Return(nil, errors.New("database error"))
+func TestUpdateTierHandler_NotFound(t *testing.T) {
+	w := serve(memoryHandlers(repository.NewMemoryStore()), http.MethodPatch, "/api/badges/tiers/42", `{"display_name": "Master"}`)
 
-	handlers.CreateBadgeHandler(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Tier Not found")
+}
 
-	assert.Equal(t, http.StatusCreated, w.Code)
+func TestDeleteTierHandler_Success(t *testing.T) {
+	store := repository.NewMemoryStore()
+	expert, err := store.FindTierByName(0, "expert")
+	assert.NoError(t, err)
 
-	assert.Contains(t, w.Body.String(), "Unable to create badge")
+	w := serve(memoryHandlers(store), http.MethodDelete, "/api/badges/tiers/"+strconv.Itoa(int(expert.ID)), "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Tier Deleted Successfully")
+
+	_, err = store.FindTierByID(expert.ID)
+	assert.Error(t, err)
 }
 
-func TestCreateBadgeHandler_InvalidInput(t *testing.T) {
-	mockDB := new(MockDatabase)
+func TestDeleteTierHandler_InUse(t *testing.T) {
+	store := repository.NewMemoryStore()
+	skill, _ := seedAssessment(t, store)
+	beginner, err := store.FindTierByName(skill.ID, "beginner")
+	assert.NoError(t, err)
+
+	w := serve(memoryHandlers(store), http.MethodDelete, "/api/badges/tiers/"+strconv.Itoa(int(beginner.ID)), "")
 
-	router := setupRouter()
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "Tier cannot be deleted")
+}
 
-	req, err := http.NewRequest("POST", "/api/badges", bytes.NewBuffer(testPayload))
+// seedLadder adds an intermediate badge from 50 to 80 to the ladder of
+// seedAssessment and returns the ladder.
+func seedLadder(t *testing.T, store *repository.MemoryStore) (models.Skill, []models.SkillBadge) {
+	skill, _ := seedAssessment(t, store)
+
+	intermediate, err := store.FindTierByName(skill.ID, "intermediate")
+	assert.NoError(t, err)
+	_, err = store.CreateBadge(models.SkillBadge{SkillID: skill.ID, TierID: intermediate.ID, Name: models.Badge(intermediate.Name), MinScore: 50, MaxScore: 80})
 	assert.NoError(t, err)
 
-	w := httptest.NewRecorder()
-	
-	c, _ := gin.CreateTestContext(w)
-	c.Request = req
-	
-	mockDB.On("FindSkillById", uint(1)).Return(&models.Skill{ID: 1}, nil)
-	mockDB.On("BadgeExists", uint(1), models.Badge("beginner")).Return(false)
-	mockDB.On("CreateBadge", mock.AnythingOfType("*models.SkillBadge")).Return(nil, errors.New("Error! Invalid Input"))
+	ladder, err := store.GetSkillLadder(skill.ID)
+	assert.NoError(t, err)
 
-	handlers.CreateBadgeHandler(c)
+	return skill, ladder
+}
 
-	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
-	assert.Contains(t, w.Body.String(), "min_score should be at least 0")
+func TestGetSkillLadderHandler_Success(t *testing.T) {
+	store := repository.NewMemoryStore()
+	skill, _ := seedLadder(t, store)
+
+	w := serve(memoryHandlers(store), http.MethodGet, "/api/badges/skills/"+strconv.Itoa(int(skill.ID))+"/ladder", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var data struct {
+		Ladder struct {
+			Valid    bool    `json:"valid"`
+			MinScore float64 `json:"min_score"`
+			MaxScore float64 `json:"max_score"`
+		} `json:"ladder"`
+	}
+	decodeData(t, w.Body.Bytes(), &data)
+	assert.True(t, data.Ladder.Valid)
+	assert.Equal(t, 0.0, data.Ladder.MinScore)
+	assert.Equal(t, 80.0, data.Ladder.MaxScore)
 }
 
-func TestCreateBadgeHandler_DatabaseError(t *testing.T) {
-	mockDB := new(MockDatabase)
+func TestGetSkillLadderHandler_SkillNotFound(t *testing.T) {
+	w := serve(memoryHandlers(repository.NewMemoryStore()), http.MethodGet, "/api/badges/skills/42/ladder", "")
 
-	router := setupRouter()
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Skill Not found")
+}
 
-	req, err := http.NewRequest("POST", "/api/badges", bytes.NewBuffer(testPayload))
+func TestUpdateSkillLadderHandler_Success(t *testing.T) {
+	store := repository.NewMemoryStore()
+	skill, ladder := seedLadder(t, store)
+
+	w := serve(memoryHandlers(store), http.MethodPatch, "/api/badges/skills/"+strconv.Itoa(int(skill.ID))+"/ladder",
+		`{"badges": [{"badge_id": `+strconv.Itoa(int(ladder[0].ID))+`, "min_score": 0, "max_score": 40}, {"badge_id": `+strconv.Itoa(int(ladder[1].ID))+`, "min_score": 40, "max_score": 80}]}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Skill Ladder Updated Successfully")
+
+	badge, err := store.FindBadgeByID(ladder[0].ID)
 	assert.NoError(t, err)
+	assert.Equal(t, 40.0, badge.MaxScore)
+}
 
-	w := httptest.NewRecorder()
-	
-	c, _ := gin.CreateTestContext(w)
-	c.Request = req
-	
+func TestUpdateSkillLadderHandler_Overlap(t *testing.T) {
+	store := repository.NewMemoryStore()
+	skill, ladder := seedLadder(t, store)
 
-	mockDB.On("FindSkillById", uint(1)).Return(nil, errors.New("database error"))
-	mockDB.On("BadgeExists", uint(1), models.Badge("beginner")).Return(false)
-	mockDB.On("CreateBadge", mock.AnythingOfType("*models.SkillBadge")).Return(nil, errors.New("database error"))
+	w := serve(memoryHandlers(store), http.MethodPatch, "/api/badges/skills/"+strconv.Itoa(int(skill.ID))+"/ladder",
+		`{"badges": [{"badge_id": `+strconv.Itoa(int(ladder[0].ID))+`, "min_score": 0, "max_score": 60}]}`)
 
-	handlers.CreateBadgeHandler(c)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid badge ladder")
 
-	assert.Equal(t, http.StatusInternalServerError, c.Writer.Status())
-	assert.Contains(t, w.Body.String(), "Internal Server Error")
+	badge, err := store.FindBadgeByID(ladder[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 50.0, badge.MaxScore)
 }
 
-func TestCreateBadgeHandler_FindSkillByIdError(t *testing.T) {
-	mockDB := new(MockDatabase)
+// seedUserBadge awards the badge of seedAssessment to the test user.
+func seedUserBadge(t *testing.T, store *repository.MemoryStore) *models.UserBadge {
+	_, taken := seedAssessment(t, store)
 
-	router := setupRouter()
-	
-	req, err := http.NewRequest("POST", "/api/badges", bytes.NewBuffer(testPayload))
+	userBadge, _, err := store.AssignBadge(testUserID, taken.ID, nil)
 	assert.NoError(t, err)
 
-	w := httptest.NewRecorder()
-	
-	c, _ := gin.CreateTestContext(w)
-	c.Request = req
-	
-	mockDB.On("FindSkillById", uint(1)).Return(nil, nil)
-	mockDB.On("BadgeExists", uint(1), models.Badge("beginner")).Return(false)
-	mockDB.On("CreateBadge", mock.AnythingOfType("*models.SkillBadge")).Return(nil, nil)
+	return userBadge
+}
 
-	handlers.CreateBadgeHandler(c)
+func TestRevokeUserBadgeHandler_Success(t *testing.T) {
+	store := repository.NewMemoryStore()
+	userBadge := seedUserBadge(t, store)
 
-	assert.Equal(t, http.StatusCreated, w.Code)
+	w := serve(memoryHandlers(store), http.MethodPost, "/api/badges/user/badges/"+strconv.Itoa(int(userBadge.ID))+"/revoke", `{"reason": "cheated"}`)
 
-	assert.Contains(t, w.Body.String(), "No skill found matching provided ID")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Badge Revoked Successfully")
+
+	revoked, err := store.FindUserBadge(userBadge.ID)
+	assert.NoError(t, err)
+	assert.True(t, revoked.IsRevoked())
+	assert.Equal(t, "cheated", revoked.RevocationReason)
 }
 
+func TestRevokeUserBadgeHandler_AlreadyRevoked(t *testing.T) {
+	store := repository.NewMemoryStore()
+	userBadge := seedUserBadge(t, store)
 
-func TestCleanupCreateBadge(t *testing.T) {
-	defer mockDB.AssertExpectations(t)
-	mockDB.Calls = nil
+	_, err := store.RevokeUserBadge(userBadge.ID, testUserID, "cheated")
+	assert.NoError(t, err)
+
+	w := serve(memoryHandlers(store), http.MethodPost, "/api/badges/user/badges/"+strconv.Itoa(int(userBadge.ID))+"/revoke", `{"reason": "cheated"}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "Badge already revoked")
 }
 
+func TestRevokeUserBadgeHandler_ReasonRequired(t *testing.T) {
+	store := repository.NewMemoryStore()
+	userBadge := seedUserBadge(t, store)
+
+	w := serve(memoryHandlers(store), http.MethodPost, "/api/badges/user/badges/"+strconv.Itoa(int(userBadge.ID))+"/revoke", `{"reason": " "}`)
 
-func setupAssignBadgeRouter() *gin.Engine {
-	router := gin.Default()
-	router.POST("/api/user/badges", handlers.AssignBadgeHandler)
-	return router
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "reason is required")
 }
 
-func TestAssignBadgeHandler_Success(t *testing.T) {
-	mockDB := new(MockDatabase)
-	db.DB = mockDB
+func TestVerifyUserBadgeHandler_Revoked(t *testing.T) {
+	store := repository.NewMemoryStore()
+	userBadge := seedUserBadge(t, store)
 
-	router := setupAssignBadgeRouter()
+	path := "/api/badges/badges/verify/" + strconv.Itoa(int(userBadge.ID))
 
-	testPayload := []byte(`{
-		"user_id": "sample_user_id",
-		"badge_id": 1,
-		"assessment_id": 1
-	}`)
+	var data struct {
+		Verification struct {
+			Status           string `json:"status"`
+			Skill            string `json:"skill"`
+			RevocationReason string `json:"revocation_reason"`
+		} `json:"verification"`
+	}
 
-	mockDB.On("CheckIfBadgeIsValid", uint(1)).Return(true)
-	mockDB.On("VerifyAssessment", uint(1)).Return(true)
-	mockDB.On("AssignBadge", "sample_user_id", uint(1), uint(1)).Return(&models.UserBadge{}, nil)
+	w := serve(memoryHandlers(store), http.MethodGet, path, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	decodeData(t, w.Body.Bytes(), &data)
+	assert.Equal(t, "valid", data.Verification.Status)
+	assert.Equal(t, "Go", data.Verification.Skill)
 
-	req, err := http.NewRequest("POST", "/api/user/badges", bytes.NewBuffer(testPayload))
+	_, err := store.RevokeUserBadge(userBadge.ID, testUserID, "cheated")
 	assert.NoError(t, err)
 
-	w := httptest.NewRecorder()
+	w = serve(memoryHandlers(store), http.MethodGet, path, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	decodeData(t, w.Body.Bytes(), &data)
+	assert.Equal(t, "revoked", data.Verification.Status)
+	assert.Equal(t, "cheated", data.Verification.RevocationReason)
+}
 
-	c, _ := gin.CreateTestContext(w)
-	c.Request = req
+func TestVerifyUserBadgeHandler_NotFound(t *testing.T) {
+	w := serve(memoryHandlers(repository.NewMemoryStore()), http.MethodGet, "/api/badges/badges/verify/42", "")
 
-	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Badge Not found")
+}
 
-	handlers.AssignBadgeHandler(c)
+func TestOpenBadgesAssertionHandler_Success(t *testing.T) {
+	store := repository.NewMemoryStore()
+	userBadge := seedUserBadge(t, store)
 
-	assert.Equal(t, http.StatusCreated, w.Code)
-	assert.Contains(t, w.Body.String(), "Badge Assigned Successfully")
-	mockDB.AssertExpectations(t)
+	w := serve(memoryHandlers(store), http.MethodGet, "/api/badges/openbadges/assertions/"+strconv.Itoa(int(userBadge.ID)), "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var assertion struct {
+		Type      string `json:"type"`
+		ID        string `json:"id"`
+		Recipient struct {
+			Hashed   bool   `json:"hashed"`
+			Identity string `json:"identity"`
+		} `json:"recipient"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &assertion))
+	assert.Equal(t, "Assertion", assertion.Type)
+	assert.Equal(t, "http://localhost:8080/api/badges/openbadges/assertions/"+strconv.Itoa(int(userBadge.ID)), assertion.ID)
+	assert.True(t, assertion.Recipient.Hashed)
+	assert.NotContains(t, assertion.Recipient.Identity, "sample@example.com")
 }
 
-func TestAssignBadgeHandler_AssignBadgeError(t *testing.T) {
-	mockDB := new(MockDatabase)
-	db.DB = mockDB
+func TestOpenBadgesAssertionHandler_Revoked(t *testing.T) {
+	store := repository.NewMemoryStore()
+	userBadge := seedUserBadge(t, store)
 
-	router := setupAssignBadgeRouter()
+	_, err := store.RevokeUserBadge(userBadge.ID, testUserID, "cheated")
+	assert.NoError(t, err)
+
+	w := serve(memoryHandlers(store), http.MethodGet, "/api/badges/openbadges/assertions/"+strconv.Itoa(int(userBadge.ID)), "")
 
-	testPayload := []byte(`{
-		"user_id": "sample_user_id",
-		"badge_id": 1,
-		"assessment_id": 1
-	}`)
+	assert.Equal(t, http.StatusGone, w.Code)
+	assert.Contains(t, w.Body.String(), `"revoked":true`)
+}
 
-	mockDB.On("CheckIfBadgeIsValid", uint(1)).Return(true)
-	mockDB.On("VerifyAssessment", uint(1)).Return(true)
-	mockDB.On("AssignBadge", "sample_user_id", uint(1), uint(1)).Return(nil, errors.New("assign badge error"))
+func TestOpenBadgesAssertionHandler_NotFound(t *testing.T) {
+	w := serve(memoryHandlers(repository.NewMemoryStore()), http.MethodGet, "/api/badges/openbadges/assertions/42", "")
 
-	req, err := http.NewRequest("POST", "/api/user/badges", bytes.NewBuffer(testPayload))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Assertion Not found")
+}
+
+// credentialHandlers are the memory handlers signing credentials with a
+// fixed key.
+func credentialHandlers(store *repository.MemoryStore) *handlers.Handlers {
+	h := memoryHandlers(store)
+	h.Credentials = credentials.NewIssuer(ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)), "Zuri Portfolio", "https://zuri.team")
+
+	return h
+}
+
+func TestCredentialHandlers_JWT(t *testing.T) {
+	store := repository.NewMemoryStore()
+	userBadge := seedUserBadge(t, store)
+	h := credentialHandlers(store)
+
+	w := serve(h, http.MethodGet, "/api/badges/credentials/"+strconv.Itoa(int(userBadge.ID)), "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/vc+jwt", w.Header().Get("Content-Type"))
+
+	token, err := json.Marshal(w.Body.String())
 	assert.NoError(t, err)
 
-	w := httptest.NewRecorder()
+	w = serve(h, http.MethodPost, "/api/badges/credentials/verify", `{"credential": `+string(token)+`}`)
 
-	c, _ := gin.CreateTestContext(w)
-	c.Request = req
+	assert.Equal(t, http.StatusOK, w.Code)
 
-	router.ServeHTTP(w, req)
+	var data struct {
+		Verification struct {
+			Valid        bool   `json:"valid"`
+			Format       string `json:"format"`
+			CredentialID string `json:"credential_id"`
+		} `json:"verification"`
+	}
+	decodeData(t, w.Body.Bytes(), &data)
+	assert.True(t, data.Verification.Valid)
+	assert.Equal(t, "jwt", data.Verification.Format)
+	assert.Equal(t, "http://localhost:8080/api/badges/credentials/"+strconv.Itoa(int(userBadge.ID)), data.Verification.CredentialID)
+}
 
-	handlers.AssignBadgeHandler(c)
+func TestCredentialHandlers_RevokedDocument(t *testing.T) {
+	store := repository.NewMemoryStore()
+	userBadge := seedUserBadge(t, store)
+	h := credentialHandlers(store)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.Contains(t, w.Body.String(), "Unable to assign badge")
-	mockDB.AssertExpectations(t)
+	w := serve(h, http.MethodGet, "/api/badges/credentials/"+strconv.Itoa(int(userBadge.ID))+"?format=json", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	document := w.Body.String()
+
+	_, err := store.RevokeUserBadge(userBadge.ID, testUserID, "cheated")
+	assert.NoError(t, err)
+
+	w = serve(h, http.MethodPost, "/api/badges/credentials/verify", `{"credential": `+document+`}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var data struct {
+		Verification struct {
+			Valid            bool   `json:"valid"`
+			Format           string `json:"format"`
+			Revoked          bool   `json:"revoked"`
+			RevocationReason string `json:"revocation_reason"`
+		} `json:"verification"`
+	}
+	decodeData(t, w.Body.Bytes(), &data)
+	assert.False(t, data.Verification.Valid)
+	assert.Equal(t, "json", data.Verification.Format)
+	assert.True(t, data.Verification.Revoked)
+	assert.Equal(t, "cheated", data.Verification.RevocationReason)
+
+	w = serve(h, http.MethodGet, "/api/badges/credentials/"+strconv.Itoa(int(userBadge.ID)), "")
+
+	assert.Equal(t, http.StatusGone, w.Code)
+	assert.Contains(t, w.Body.String(), "Badge has been revoked")
 }
 
+func TestCredentialHandlers_Disabled(t *testing.T) {
+	store := repository.NewMemoryStore()
+	userBadge := seedUserBadge(t, store)
+
+	w := serve(memoryHandlers(store), http.MethodGet, "/api/badges/credentials/"+strconv.Itoa(int(userBadge.ID)), "")
 
-func TestCleanupAssignBadge(t *testing.T) {
-	defer mockDB.AssertExpectations(t)
-	mockDB.Calls = nil
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "Credential issuance is not available")
 }
 
+// seedOutbox queues a mail still pending and a mail dead-lettered after a
+// failed delivery.
+func seedOutbox(t *testing.T, store *repository.MemoryStore) (pending models.MailLog, failed models.MailLog) {
+	pending = models.MailLog{Email: "sample@example.com", Channel: "messaging"}
+	assert.NoError(t, store.EnqueueMail(&pending))
 
+	failed = models.MailLog{Email: "sample@example.com", Channel: "smtp"}
+	assert.NoError(t, store.EnqueueMail(&failed))
+	assert.NoError(t, store.MarkMailAttemptFailed(&failed, errors.New("connection refused"), nil))
 
-func setupGetUserBadgeRouter() *gin.Engine {
-	router := gin.Default()
-	router.GET("api/user/badges/:userId/skill/:skillId", handlers.GetUserBadgeHandler)
-	return router
+	return pending, failed
 }
 
-func TestGetUserBadgeHandler_Success(t *testing.T) {
-	mockDB := new(MockDatabase)
-	db.DB = mockDB
+func TestListOutboxHandler_Success(t *testing.T) {
+	store := repository.NewMemoryStore()
+	_, failed := seedOutbox(t, store)
 
-	router := setupGetUserBadgeRouter()
+	w := serve(memoryHandlers(store), http.MethodGet, "/api/badges/admin/outbox?status=failed", "")
 
-	userId := "sample_user_id"
-	skillId := "sample_skill_id"
+	assert.Equal(t, http.StatusOK, w.Code)
 
-	mockDB.On("Where", "user_id=? AND skill_id=?", userId, skillId).Return(mockDB)
-	mockDB.On("Find", mock.Anything, "user_id=? AND skill_id=?", userId, skillId).Return(mockDB)
-	mockDB.On("Len", mock.Anything).Return(1)
+	var data struct {
+		Mails []struct {
+			ID        uint              `json:"id"`
+			Status    models.MailStatus `json:"status"`
+			LastError string            `json:"last_error"`
+		} `json:"mails"`
+	}
+	decodeData(t, w.Body.Bytes(), &data)
+	assert.Len(t, data.Mails, 1)
+	assert.Equal(t, failed.ID, data.Mails[0].ID)
+	assert.Equal(t, models.MailFailed, data.Mails[0].Status)
+	assert.Equal(t, "connection refused", data.Mails[0].LastError)
+}
 
-	w := httptest.NewRecorder()
-	req, _ := http.NewRequest("GET", "api/user/badges/"+userId+"/skill/"+skillId, nil)
+func TestListOutboxHandler_InvalidStatus(t *testing.T) {
+	w := serve(memoryHandlers(repository.NewMemoryStore()), http.MethodGet, "/api/badges/admin/outbox?status=sent", "")
 
-	c, _ := gin.CreateTestContext(w)
-	c.Params = append(c.Params, gin.Param{Key: "userId", Value: userId}, gin.Param{Key: "skillId", Value: skillId})
-	c.Request = req
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid status")
+}
 
-	router.ServeHTTP(w, req)
+func TestReplayOutboxHandler_Success(t *testing.T) {
+	store := repository.NewMemoryStore()
+	_, failed := seedOutbox(t, store)
 
-	handlers.GetUserBadgeHandler(c)
+	w := serve(memoryHandlers(store), http.MethodPost, "/api/badges/admin/outbox/"+strconv.Itoa(int(failed.ID))+"/replay", "")
 
 	assert.Equal(t, http.StatusOK, w.Code)
-	assert.Contains(t, w.Body.String(), "User Badge Retrieved Successfully")
-	mockDB.AssertExpectations(t)
+	assert.Contains(t, w.Body.String(), "Mail Queued For Delivery")
+
+	mail, err := store.FindMailLog(failed.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.MailPending, mail.Status)
+	assert.Equal(t, 0, mail.Attempts)
 }
 
-func TestGetUserBadgeHandler_NotFound(t *testing.T) {
-	mockDB := new(MockDatabase)
-	db.DB = mockDB
+func TestReplayOutboxHandler_NotFailed(t *testing.T) {
+	store := repository.NewMemoryStore()
+	pending, _ := seedOutbox(t, store)
 
-	router := setupGetUserBadgeRouter()
+	w := serve(memoryHandlers(store), http.MethodPost, "/api/badges/admin/outbox/"+strconv.Itoa(int(pending.ID))+"/replay", "")
 
-	userId := "non_existent_user_id"
-	skillId := "non_existent_skill_id"
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "Unable to replay mail")
+}
 
-	mockDB.On("Where", "user_id=? AND skill_id=?", userId, skillId).Return(mockDB)
-	mockDB.On("Find", mock.Anything, "user_id=? AND skill_id=?", userId, skillId).Return(mockDB)
-	mockDB.On("Len", mock.Anything).Return(0)
+func TestReplayOutboxHandler_NotFound(t *testing.T) {
+	w := serve(memoryHandlers(repository.NewMemoryStore()), http.MethodPost, "/api/badges/admin/outbox/42/replay", "")
 
-	w := httptest.NewRecorder()
-	req, _ := http.NewRequest("GET", "api/user/badges/"+userId+"/skill/"+skillId, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Mail Not found")
+}
 
-	c, _ := gin.CreateTestContext(w)
-	c.Params = append(c.Params, gin.Param{Key: "userId", Value: userId}, gin.Param{Key: "skillId", Value: skillId})
-	c.Request = req
+// grantsData are the grants answered by the user permission endpoints.
+type grantsData struct {
+	Roles             []string `json:"roles"`
+	DirectPermissions []string `json:"direct_permissions"`
+	Permissions       []string `json:"permissions"`
+}
 
-	router.ServeHTTP(w, req)
+func userGrants(t *testing.T, w *httptest.ResponseRecorder) grantsData {
+	var grants grantsData
+	decodeData(t, w.Body.Bytes(), &grants)
+
+	return grants
+}
+
+func TestCreateRoleHandler_Success(t *testing.T) {
+	store := repository.NewMemoryStore()
+
+	w := serve(memoryHandlers(store), http.MethodPost, "/api/badges/admin/roles", `{"name": "reviewer", "permissions": ["badge.read", "badge.revoke"]}`)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), "Role Created Successfully")
+
+	roles, err := store.ListRoles()
+	assert.NoError(t, err)
+	assert.Len(t, roles, 1)
+	assert.Equal(t, []string{"badge.read", "badge.revoke"}, roles[0].Permissions)
+}
+
+func TestCreateRoleHandler_Exists(t *testing.T) {
+	store := repository.NewMemoryStore()
+	_, err := store.CreateRole("reviewer", nil)
+	assert.NoError(t, err)
+
+	w := serve(memoryHandlers(store), http.MethodPost, "/api/badges/admin/roles", `{"name": "reviewer"}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "Role already exists")
+}
+
+func TestCreateRoleHandler_InvalidPermission(t *testing.T) {
+	w := serve(memoryHandlers(repository.NewMemoryStore()), http.MethodPost, "/api/badges/admin/roles", `{"name": "reviewer", "permissions": ["badge read"]}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), models.ErrInvalidPermission.Error())
+}
+
+func TestDeleteRoleHandler_Success(t *testing.T) {
+	store := repository.NewMemoryStore()
+	role, err := store.CreateRole("reviewer", nil)
+	assert.NoError(t, err)
+
+	w := serve(memoryHandlers(store), http.MethodDelete, "/api/badges/admin/roles/"+strconv.Itoa(int(role.ID)), "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Role Deleted Successfully")
+
+	_, err = store.FindRoleByID(role.ID)
+	assert.Error(t, err)
+}
+
+func TestGrantRolePermissionHandler_Success(t *testing.T) {
+	store := repository.NewMemoryStore()
+	role, err := store.CreateRole("reviewer", []string{"badge.read"})
+	assert.NoError(t, err)
+
+	w := serve(memoryHandlers(store), http.MethodPost, "/api/badges/admin/roles/"+strconv.Itoa(int(role.ID))+"/permissions", `{"permission": "badge.revoke"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Permission Granted Successfully")
+
+	permissions, err := store.RolePermissionNames(role.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"badge.read", "badge.revoke"}, permissions)
+
+	w = serve(memoryHandlers(store), http.MethodDelete, "/api/badges/admin/roles/"+strconv.Itoa(int(role.ID))+"/permissions/badge.read", "")
 
-	handlers.GetUserBadgeHandler(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Permission Revoked Successfully")
+
+	permissions, err = store.RolePermissionNames(role.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"badge.revoke"}, permissions)
+}
+
+func TestGrantRolePermissionHandler_RoleNotFound(t *testing.T) {
+	w := serve(memoryHandlers(repository.NewMemoryStore()), http.MethodPost, "/api/badges/admin/roles/42/permissions", `{"permission": "badge.revoke"}`)
 
 	assert.Equal(t, http.StatusNotFound, w.Code)
-	assert.Contains(t, w.Body.String(), "User Badge not Found")
-	mockDB.AssertExpectations(t)
+	assert.Contains(t, w.Body.String(), "Role Not found")
+}
+
+func TestAssignUserRoleHandler_Success(t *testing.T) {
+	store := repository.NewMemoryStore()
+	role, err := store.CreateRole("reviewer", []string{"badge.read"})
+	assert.NoError(t, err)
+
+	w := serve(memoryHandlers(store), http.MethodPost, "/api/badges/admin/users/user-1/roles", `{"role_id": `+strconv.Itoa(int(role.ID))+`}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Role Assigned Successfully")
+
+	grants := userGrants(t, w)
+	assert.Equal(t, []string{"reviewer"}, grants.Roles)
+	assert.Equal(t, []string{"badge.read"}, grants.Permissions)
+
+	w = serve(memoryHandlers(store), http.MethodPost, "/api/badges/admin/users/user-1/roles", `{"role_id": `+strconv.Itoa(int(role.ID))+`}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "Role already assigned")
+
+	w = serve(memoryHandlers(store), http.MethodDelete, "/api/badges/admin/users/user-1/roles/"+strconv.Itoa(int(role.ID)), "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, userGrants(t, w).Roles)
 }
 
-func TestCleanupGetUserBadge(t *testing.T) {
-	defer mockDB.AssertExpectations(t)
-	mockDB.Calls = nil
+func TestAssignUserRoleHandler_RoleNotFound(t *testing.T) {
+	w := serve(memoryHandlers(repository.NewMemoryStore()), http.MethodPost, "/api/badges/admin/users/user-1/roles", `{"role_id": 42}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "no role found matching provided ID")
+}
+
+func TestGrantUserPermissionHandler_Success(t *testing.T) {
+	store := repository.NewMemoryStore()
+	h := memoryHandlers(store)
+
+	w := serve(h, http.MethodPost, "/api/badges/admin/users/user-1/permissions", `{"permission": "badge.assign"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Permission Granted Successfully")
+	assert.Equal(t, []string{"badge.assign"}, userGrants(t, w).DirectPermissions)
+
+	w = serve(h, http.MethodGet, "/api/badges/admin/users/user-1/permissions", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"badge.assign"}, userGrants(t, w).Permissions)
+
+	w = serve(h, http.MethodDelete, "/api/badges/admin/users/user-1/permissions/badge.assign", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, userGrants(t, w).Permissions)
+}
+
+func TestGrantUserPermissionHandler_InvalidPermission(t *testing.T) {
+	w := serve(memoryHandlers(repository.NewMemoryStore()), http.MethodPost, "/api/badges/admin/users/user-1/permissions", `{"permission": ""}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), models.ErrInvalidPermission.Error())
 }
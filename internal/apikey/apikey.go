@@ -0,0 +1,146 @@
+// Package apikey authorizes the services calling the API with keys created by
+// the admins, instead of user tokens.
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"demerzel-badges/internal/auth"
+	"demerzel-badges/internal/models"
+	"demerzel-badges/pkg/logger"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Prefix starts every key, so they can be told apart from user tokens.
+const Prefix = "bdg_"
+
+// Generate creates a key, formatted as bdg_<id>_<secret>. It returns the key
+// to hand to the service, its public id and the hash to store.
+func Generate() (key string, id string, hash string, err error) {
+	raw := make([]byte, 4+32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", "", err
+	}
+
+	id = hex.EncodeToString(raw[:4])
+	key = Prefix + id + "_" + base64.RawURLEncoding.EncodeToString(raw[4:])
+
+	return key, id, Hash(key), nil
+}
+
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseID returns the public id of a key.
+func ParseID(key string) (string, bool) {
+	if !strings.HasPrefix(key, Prefix) {
+		return "", false
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(key, Prefix), "_", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", false
+	}
+
+	return parts[0], true
+}
+
+// Store looks up the keys and records their use.
+type Store interface {
+	FindKey(ctx context.Context, id string) (*models.APIKey, error)
+	Touch(ctx context.Context, key *models.APIKey, now time.Time) error
+}
+
+// DBStore keeps the keys in the api_key table.
+type DBStore struct {
+	DB *gorm.DB
+}
+
+func (s DBStore) FindKey(ctx context.Context, id string) (*models.APIKey, error) {
+	key, err := models.FindAPIKeyByPrefix(s.DB.WithContext(ctx), id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return key, err
+}
+
+func (s DBStore) Touch(ctx context.Context, key *models.APIKey, now time.Time) error {
+	return models.TouchAPIKey(s.DB.WithContext(ctx), key.ID, now)
+}
+
+// Client authorizes API keys with the permissions they were created with,
+// and hands the other tokens to Next.
+type Client struct {
+	Store Store
+	Next  auth.Client
+
+	now func() time.Time
+}
+
+func NewClient(db *gorm.DB, next auth.Client) *Client {
+	return &Client{Store: DBStore{DB: db}, Next: next, now: time.Now}
+}
+
+func (c *Client) Authorize(ctx context.Context, token string, permission string) (*auth.Principal, error) {
+	if !strings.HasPrefix(token, Prefix) {
+		if c.Next == nil {
+			return nil, &auth.DeniedError{Status: http.StatusUnauthorized, Message: "invalid API key"}
+		}
+
+		return c.Next.Authorize(ctx, token, permission)
+	}
+
+	id, ok := ParseID(token)
+	if !ok {
+		return nil, &auth.DeniedError{Status: http.StatusUnauthorized, Message: "invalid API key"}
+	}
+
+	key, err := c.Store.FindKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if key == nil || subtle.ConstantTimeCompare([]byte(key.Hash), []byte(Hash(token))) != 1 {
+		return nil, &auth.DeniedError{Status: http.StatusUnauthorized, Message: "invalid API key"}
+	}
+
+	now := time.Now()
+	if c.now != nil {
+		now = c.now()
+	}
+
+	if key.Revoked() {
+		return nil, &auth.DeniedError{Status: http.StatusUnauthorized, Message: "API key has been revoked"}
+	}
+
+	if key.Expired(now) {
+		return nil, &auth.DeniedError{Status: http.StatusUnauthorized, Message: "API key has expired"}
+	}
+
+	if !key.HasScope(permission) {
+		return nil, &auth.DeniedError{Status: http.StatusForbidden, Message: "missing permission " + permission}
+	}
+
+	if err := c.Store.Touch(ctx, key, now); err != nil {
+		logger.Warnf("Unable to record the use of API key %s: %v", key.Prefix, err)
+	}
+
+	return &auth.Principal{
+		UserID:      fmt.Sprintf("apikey:%d", key.ID),
+		Permissions: key.Scopes,
+		Machine:     true,
+	}, nil
+}
@@ -0,0 +1,116 @@
+package apikey
+
+import (
+	"context"
+	"demerzel-badges/internal/auth"
+	"demerzel-badges/internal/models"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStore struct {
+	keys    map[string]*models.APIKey
+	touched []uint
+}
+
+func (s *fakeStore) FindKey(ctx context.Context, id string) (*models.APIKey, error) {
+	return s.keys[id], nil
+}
+
+func (s *fakeStore) Touch(ctx context.Context, key *models.APIKey, now time.Time) error {
+	s.touched = append(s.touched, key.ID)
+	return nil
+}
+
+type userClient struct{}
+
+func (userClient) Authorize(ctx context.Context, token string, permission string) (*auth.Principal, error) {
+	return &auth.Principal{UserID: "user-1"}, nil
+}
+
+func newKey(t *testing.T, store *fakeStore, key models.APIKey) string {
+	plain, id, hash, err := Generate()
+	assert.NoError(t, err)
+
+	key.Prefix = id
+	key.Hash = hash
+	store.keys[id] = &key
+
+	return plain
+}
+
+func deniedStatus(t *testing.T, err error) int {
+	var denied *auth.DeniedError
+	if !assert.True(t, errors.As(err, &denied)) {
+		return 0
+	}
+
+	return denied.Status
+}
+
+func TestGenerate(t *testing.T) {
+	key, id, hash, err := Generate()
+	assert.NoError(t, err)
+	assert.Regexp(t, `^bdg_[0-9a-f]{8}_[A-Za-z0-9_-]{43}$`, key)
+	assert.Equal(t, Hash(key), hash)
+
+	parsed, ok := ParseID(key)
+	assert.True(t, ok)
+	assert.Equal(t, id, parsed)
+
+	_, ok = ParseID("bdg_missing")
+	assert.False(t, ok)
+}
+
+func TestClientAuthorize(t *testing.T) {
+	store := &fakeStore{keys: map[string]*models.APIKey{}}
+	client := &Client{Store: store, Next: userClient{}}
+	ctx := context.Background()
+
+	key := newKey(t, store, models.APIKey{ID: 1, Scopes: []string{"badge.update.any"}})
+
+	principal, err := client.Authorize(ctx, key, "badge.update.any")
+	assert.NoError(t, err)
+	assert.Equal(t, "apikey:1", principal.UserID)
+	assert.True(t, principal.Machine)
+	assert.Equal(t, []uint{1}, store.touched)
+
+	_, err = client.Authorize(ctx, key, "badge.create")
+	assert.Equal(t, http.StatusForbidden, deniedStatus(t, err))
+
+	_, err = client.Authorize(ctx, key+"x", "badge.update.any")
+	assert.Equal(t, http.StatusUnauthorized, deniedStatus(t, err))
+
+	// Other tokens are authorized by the next client
+	principal, err = client.Authorize(ctx, "user-token", "badge.read")
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", principal.UserID)
+	assert.False(t, principal.Machine)
+}
+
+func TestClientRevokedAndExpired(t *testing.T) {
+	store := &fakeStore{keys: map[string]*models.APIKey{}}
+	now := time.Now()
+	client := &Client{Store: store, now: func() time.Time { return now }}
+	ctx := context.Background()
+
+	revoked := newKey(t, store, models.APIKey{ID: 1, Scopes: []string{"badge.read"}, RevokedAt: &now})
+	_, err := client.Authorize(ctx, revoked, "badge.read")
+	assert.Equal(t, http.StatusUnauthorized, deniedStatus(t, err))
+
+	expiry := now.Add(time.Hour)
+	expiring := newKey(t, store, models.APIKey{ID: 2, Scopes: []string{"badge.read"}, ExpiresAt: &expiry})
+	_, err = client.Authorize(ctx, expiring, "badge.read")
+	assert.NoError(t, err)
+
+	now = now.Add(2 * time.Hour)
+	_, err = client.Authorize(ctx, expiring, "badge.read")
+	assert.Equal(t, http.StatusUnauthorized, deniedStatus(t, err))
+
+	_, err = client.Authorize(ctx, "user-token", "badge.read")
+	assert.Equal(t, http.StatusUnauthorized, deniedStatus(t, err))
+}
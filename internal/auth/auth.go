@@ -0,0 +1,40 @@
+// Package auth checks that the bearer token of a request grants a
+// permission. Clients are shared by all the authorization middlewares.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Principal is the caller a token belongs to. Permissions and Roles are only
+// known when they are read from the token.
+type Principal struct {
+	UserID      string
+	Permissions []string
+	Roles       []string
+	// Machine is set for services calling with an API key, their UserID is
+	// not a user.
+	Machine bool
+}
+
+// Client authorizes a bearer token for a permission.
+type Client interface {
+	Authorize(ctx context.Context, token string, permission string) (*Principal, error)
+}
+
+// ErrUnavailable wraps the failures to reach the auth service, as opposed to
+// a token being denied.
+var ErrUnavailable = errors.New("auth service unavailable")
+
+// DeniedError is returned when the token does not grant the permission.
+// Status is the HTTP status to answer with.
+type DeniedError struct {
+	Status  int
+	Message interface{}
+}
+
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("permission denied (%d): %v", e.Status, e.Message)
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// maxCacheEntries bounds the memory used by the cache.
+const maxCacheEntries = 10000
+
+type cacheEntry struct {
+	principal *Principal
+	err       error
+	expires   time.Time
+}
+
+// resultCache keeps authorization results for a short time, keyed by the
+// hash of the token and the permission so that tokens are not kept in memory.
+type resultCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+	now     func() time.Time
+}
+
+func newResultCache(ttl time.Duration) *resultCache {
+	return &resultCache{ttl: ttl, entries: map[string]cacheEntry{}, now: time.Now}
+}
+
+func cacheKey(token string, permission string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:]) + ":" + permission
+}
+
+func (c *resultCache) get(key string) (*Principal, error, bool) {
+	if c.ttl <= 0 {
+		return nil, nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, nil, false
+	}
+
+	if c.now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, nil, false
+	}
+
+	return entry.principal, entry.err, true
+}
+
+func (c *resultCache) set(key string, principal *Principal, err error, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if len(c.entries) >= maxCacheEntries {
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+
+	// Still full of live entries, start over rather than grow unbounded.
+	if len(c.entries) >= maxCacheEntries {
+		c.entries = map[string]cacheEntry{}
+	}
+
+	c.entries[key] = cacheEntry{principal: principal, err: err, expires: now.Add(ttl)}
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Modes of AUTH_MODE.
+const (
+	ModeRemote    = "remote"
+	ModeJWT       = "jwt"
+	ModeJWTRemote = "jwt+remote"
+)
+
+// Config is the authorization of the requests, set by the AUTH_* variables.
+type Config struct {
+	Mode string
+
+	// URL of the auth service, its responses are cached for CacheTTL.
+	URL      string
+	Timeout  time.Duration
+	CacheTTL time.Duration
+
+	// JWKSURL or JWKSFile holds the keys verifying the tokens locally.
+	JWKSURL     string
+	JWKSFile    string
+	JWKSRefresh time.Duration
+
+	Issuer           string
+	Audience         string
+	UserClaim        string
+	PermissionsClaim string
+	RolesClaim       string
+	AdminRoles       []string
+}
+
+// New creates the client selected by the mode: remote (default) asks the
+// auth service, jwt verifies tokens locally against the JWKS, and jwt+remote
+// falls back to the auth service for the tokens that cannot be verified
+// locally.
+func New(cfg Config) (Client, error) {
+	mode := strings.ToLower(strings.TrimSpace(cfg.Mode))
+
+	switch mode {
+	case "", ModeRemote:
+		return NewRemoteClient(cfg.URL, cfg.Timeout, cfg.CacheTTL), nil
+	case ModeJWT, ModeJWTRemote:
+		verifier, err := NewVerifier(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		client := &JWTClient{Verifier: verifier}
+		if mode == ModeJWTRemote {
+			client.Fallback = NewRemoteClient(cfg.URL, cfg.Timeout, cfg.CacheTTL)
+		}
+
+		return client, nil
+	default:
+		return nil, fmt.Errorf("unknown AUTH_MODE %q", mode)
+	}
+}
+
+// NewVerifier creates the verifier of the tokens.
+func NewVerifier(cfg Config) (*Verifier, error) {
+	var keys KeySet
+
+	if cfg.JWKSFile != "" {
+		set, err := LoadJWKSFile(cfg.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("AUTH_JWKS_FILE: %w", err)
+		}
+		keys = set
+	} else if cfg.JWKSURL != "" {
+		keys = NewRemoteKeySet(cfg.JWKSURL, cfg.JWKSRefresh, cfg.Timeout)
+	} else {
+		return nil, errors.New("AUTH_JWKS_URL or AUTH_JWKS_FILE is required to verify tokens locally")
+	}
+
+	return &Verifier{
+		Keys:             keys,
+		Issuer:           cfg.Issuer,
+		Audience:         cfg.Audience,
+		UserClaim:        cfg.UserClaim,
+		PermissionsClaim: cfg.PermissionsClaim,
+		RolesClaim:       cfg.RolesClaim,
+		AdminRoles:       cfg.AdminRoles,
+	}, nil
+}
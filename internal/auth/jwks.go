@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+var ErrUnknownKey = errors.New("no key matches the token")
+
+// jwk is a JSON Web Key, only the members used for RSA, EC and OKP keys.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey is a verification key with the algorithm it is used with.
+type publicKey struct {
+	ID  string
+	Alg string
+	Key crypto.PublicKey
+}
+
+// ParseJWKS reads the signing keys of a JSON Web Key Set. Keys of other
+// types, or meant for encryption, are skipped.
+func ParseJWKS(content []byte) ([]publicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := json.Unmarshal(content, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	var keys []publicKey
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", k.Kid, err)
+		}
+
+		if key != nil {
+			keys = append(keys, *key)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS has no signing key")
+	}
+
+	return keys, nil
+}
+
+func (k jwk) publicKey() (*publicKey, error) {
+	switch {
+	case k.Kty == "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return k.checked("RS256", &rsa.PublicKey{N: n, E: int(e.Int64())})
+	case k.Kty == "EC" && k.Crv == "P-256":
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		if !key.Curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+
+		return k.checked("ES256", key)
+	case k.Kty == "OKP" && k.Crv == "Ed25519":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+
+		return k.checked("EdDSA", ed25519.PublicKey(x))
+	default:
+		return nil, nil
+	}
+}
+
+// checked rejects keys whose declared algorithm is not the one supported
+// for their type.
+func (k jwk) checked(alg string, key crypto.PublicKey) (*publicKey, error) {
+	if k.Alg != "" && k.Alg != alg {
+		return nil, nil
+	}
+
+	return &publicKey{ID: k.Kid, Alg: alg, Key: key}, nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(raw) == 0 {
+		return nil, errors.New("invalid key parameter")
+	}
+
+	return new(big.Int).SetBytes(raw), nil
+}
+
+// KeySet provides the keys tokens are verified with.
+type KeySet interface {
+	Key(ctx context.Context, kid string, alg string) (*publicKey, error)
+}
+
+// StaticKeySet is a key set loaded once, from a file for instance.
+type StaticKeySet []publicKey
+
+func LoadJWKSFile(path string) (StaticKeySet, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := ParseJWKS(content)
+
+	return StaticKeySet(keys), err
+}
+
+func (s StaticKeySet) Key(ctx context.Context, kid string, alg string) (*publicKey, error) {
+	return findKey(s, kid, alg)
+}
+
+func findKey(keys []publicKey, kid string, alg string) (*publicKey, error) {
+	for i, key := range keys {
+		if key.Alg != alg {
+			continue
+		}
+
+		// Tokens without kid can only be matched when there is no ambiguity.
+		if kid == key.ID || (kid == "" && len(keys) == 1) {
+			return &keys[i], nil
+		}
+	}
+
+	return nil, ErrUnknownKey
+}
+
+// minRefreshInterval limits how often the JWKS is fetched.
+const minRefreshInterval = 30 * time.Second
+
+// RemoteKeySet fetches the JWKS from a URL and caches it for Refresh. An
+// unknown kid refreshes the keys early, to pick up rotated keys.
+type RemoteKeySet struct {
+	URL     string
+	Refresh time.Duration
+
+	client      *resty.Client
+	mu          sync.Mutex
+	keys        []publicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	now         func() time.Time
+}
+
+func NewRemoteKeySet(url string, refresh time.Duration, timeout time.Duration) *RemoteKeySet {
+	return &RemoteKeySet{
+		URL:     url,
+		Refresh: refresh,
+		client:  resty.New().SetTimeout(timeout),
+		now:     time.Now,
+	}
+}
+
+func (s *RemoteKeySet) Key(ctx context.Context, kid string, alg string) (*publicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.keys == nil || s.now().Sub(s.fetchedAt) > s.Refresh {
+		if err := s.fetch(ctx); err != nil && s.keys == nil {
+			return nil, err
+		}
+	}
+
+	key, err := findKey(s.keys, kid, alg)
+	if errors.Is(err, ErrUnknownKey) {
+		if err := s.fetch(ctx); err != nil {
+			return nil, err
+		}
+
+		return findKey(s.keys, kid, alg)
+	}
+
+	return key, err
+}
+
+// fetch replaces the keys. On failure the previous keys are kept, so that
+// tokens are still verified while the JWKS endpoint is down.
+func (s *RemoteKeySet) fetch(ctx context.Context) error {
+	// Failed or recent fetches are not retried at once, so that an outage or
+	// tokens with made up kids do not hammer the JWKS endpoint.
+	if !s.attemptedAt.IsZero() && s.now().Sub(s.attemptedAt) < minRefreshInterval {
+		if s.keys == nil {
+			return fmt.Errorf("%w: JWKS could not be fetched", ErrUnavailable)
+		}
+		return nil
+	}
+	s.attemptedAt = s.now()
+
+	resp, err := s.client.R().SetContext(ctx).Get(s.URL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	if !resp.IsSuccess() {
+		return fmt.Errorf("%w: JWKS responded with %d", ErrUnavailable, resp.StatusCode())
+	}
+
+	keys, err := ParseJWKS(resp.Body())
+	if err != nil {
+		return err
+	}
+
+	s.keys = keys
+	s.fetchedAt = s.now()
+
+	return nil
+}
@@ -0,0 +1,253 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// leeway tolerates clock skew with the token issuer.
+const leeway = 30 * time.Second
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Verifier validates JWTs signed with RS256, ES256 or EdDSA against a key
+// set and reads the caller from their claims.
+type Verifier struct {
+	Keys KeySet
+	// Issuer and Audience are checked when not empty.
+	Issuer   string
+	Audience string
+	// UserClaim holds the user ID, PermissionsClaim the granted permissions
+	// (an array or a space separated string) and RolesClaim the roles.
+	UserClaim        string
+	PermissionsClaim string
+	RolesClaim       string
+	// AdminRoles are granted every permission.
+	AdminRoles []string
+
+	now func() time.Time
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// Verify checks the signature and the time, issuer and audience claims of
+// the token, and returns its claims.
+func (v *Verifier) Verify(ctx context.Context, token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	key, err := v.Keys.Key(ctx, header.Kid, header.Alg)
+	if err != nil {
+		return nil, err
+	}
+
+	if !verifySignature(key, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, ErrInvalidToken
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if err := v.checkClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func decodeSegment(segment string, value interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(raw)))
+	decoder.UseNumber()
+
+	return decoder.Decode(value)
+}
+
+func verifySignature(key *publicKey, signingInput []byte, signature []byte) bool {
+	digest := sha256.Sum256(signingInput)
+
+	switch pub := key.Key.(type) {
+	case *rsa.PublicKey:
+		return key.Alg == "RS256" && rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature) == nil
+	case *ecdsa.PublicKey:
+		if key.Alg != "ES256" || len(signature) != 64 {
+			return false
+		}
+
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(pub, digest[:], r, s)
+	case ed25519.PublicKey:
+		return key.Alg == "EdDSA" && ed25519.Verify(pub, signingInput, signature)
+	default:
+		return false
+	}
+}
+
+func (v *Verifier) checkClaims(claims map[string]interface{}) error {
+	now := time.Now()
+	if v.now != nil {
+		now = v.now()
+	}
+
+	exp, ok := numericDate(claims["exp"])
+	if !ok || now.After(exp.Add(leeway)) {
+		return errors.New("token has expired")
+	}
+
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(leeway).Before(nbf) {
+		return errors.New("token is not valid yet")
+	}
+
+	if v.Issuer != "" && claims["iss"] != v.Issuer {
+		return errors.New("token has another issuer")
+	}
+
+	if v.Audience != "" && !containsString(stringList(claims["aud"]), v.Audience) {
+		return errors.New("token is meant for another audience")
+	}
+
+	return nil
+}
+
+func numericDate(value interface{}) (time.Time, bool) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(int64(seconds), 0), true
+}
+
+// stringList reads a claim holding a string, a space separated string or an
+// array of strings.
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		var list []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	default:
+		return nil
+	}
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Principal reads the caller from verified claims.
+func (v *Verifier) Principal(claims map[string]interface{}) *Principal {
+	userID, _ := claims[v.UserClaim].(string)
+
+	return &Principal{
+		UserID:      userID,
+		Permissions: stringList(claims[v.PermissionsClaim]),
+		Roles:       stringList(claims[v.RolesClaim]),
+	}
+}
+
+// Grants reports whether the principal has the permission, directly or
+// through an admin role.
+func (v *Verifier) Grants(principal *Principal, permission string) bool {
+	if containsString(principal.Permissions, permission) {
+		return true
+	}
+
+	for _, role := range v.AdminRoles {
+		if containsString(principal.Roles, role) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// JWTClient authorizes tokens locally. With a Fallback, tokens it cannot
+// decide on (keys unavailable, unknown key or no permission claims) are
+// authorized by the fallback client instead.
+type JWTClient struct {
+	Verifier *Verifier
+	Fallback Client
+}
+
+func (j *JWTClient) Authorize(ctx context.Context, token string, permission string) (*Principal, error) {
+	claims, err := j.Verifier.Verify(ctx, token)
+
+	undecided := errors.Is(err, ErrUnavailable) || errors.Is(err, ErrUnknownKey)
+	if err == nil && claims[j.Verifier.PermissionsClaim] == nil && claims[j.Verifier.RolesClaim] == nil {
+		undecided = true
+	}
+
+	if undecided && j.Fallback != nil {
+		return j.Fallback.Authorize(ctx, token, permission)
+	}
+
+	if errors.Is(err, ErrUnavailable) {
+		return nil, err
+	}
+
+	if err != nil {
+		return nil, &DeniedError{Status: http.StatusUnauthorized, Message: err.Error()}
+	}
+
+	principal := j.Verifier.Principal(claims)
+	if principal.UserID == "" {
+		return nil, &DeniedError{Status: http.StatusUnauthorized, Message: "token has no user"}
+	}
+
+	if !j.Verifier.Grants(principal, permission) {
+		return nil, &DeniedError{Status: http.StatusForbidden, Message: "missing permission " + permission}
+	}
+
+	return principal, nil
+}
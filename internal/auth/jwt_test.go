@@ -0,0 +1,235 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testKey struct {
+	kid string
+	alg string
+	key crypto.Signer
+	jwk map[string]string
+}
+
+func b64(raw []byte) string {
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func newTestKeys(t *testing.T) []testKey {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	edPublic, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	return []testKey{
+		{kid: "rsa", alg: "RS256", key: rsaKey, jwk: map[string]string{
+			"kty": "RSA", "kid": "rsa", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+		}},
+		{kid: "ec", alg: "ES256", key: ecKey, jwk: map[string]string{
+			"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32))),
+		}},
+		{kid: "ed", alg: "EdDSA", key: edKey, jwk: map[string]string{
+			"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": b64(edPublic), "use": "sig",
+		}},
+	}
+}
+
+func jwks(keys ...testKey) []byte {
+	var set struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	for _, k := range keys {
+		set.Keys = append(set.Keys, k.jwk)
+	}
+
+	content, _ := json.Marshal(set)
+	return content
+}
+
+func (k testKey) sign(t *testing.T, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": k.alg, "kid": k.kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := b64(header) + "." + b64(payload)
+
+	var signature []byte
+	var err error
+	digest := sha256.Sum256([]byte(input))
+
+	switch key := k.key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, key, digest[:])
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(key, []byte(input))
+	}
+	assert.NoError(t, err)
+
+	return input + "." + b64(signature)
+}
+
+func claims(extra map[string]interface{}) map[string]interface{} {
+	c := map[string]interface{}{
+		"sub":         "u1",
+		"iss":         "https://zuri.team",
+		"aud":         []string{"badges"},
+		"exp":         time.Now().Add(time.Hour).Unix(),
+		"permissions": []string{"badge.read"},
+	}
+	for k, v := range extra {
+		c[k] = v
+	}
+
+	return c
+}
+
+func newVerifier(keys KeySet) *Verifier {
+	return &Verifier{
+		Keys:             keys,
+		Issuer:           "https://zuri.team",
+		Audience:         "badges",
+		UserClaim:        "sub",
+		PermissionsClaim: "permissions",
+		RolesClaim:       "roles",
+		AdminRoles:       []string{"admin"},
+	}
+}
+
+func TestJWTClient(t *testing.T) {
+	keys := newTestKeys(t)
+	set, err := ParseJWKS(jwks(keys...))
+	assert.NoError(t, err)
+
+	client := &JWTClient{Verifier: newVerifier(StaticKeySet(set))}
+
+	for _, key := range keys {
+		principal, err := client.Authorize(context.Background(), key.sign(t, claims(nil)), "badge.read")
+		assert.NoError(t, err, key.alg)
+		assert.Equal(t, "u1", principal.UserID)
+	}
+
+	key := keys[0]
+	denied := func(token string, permission string, status int) {
+		t.Helper()
+
+		_, err := client.Authorize(context.Background(), token, permission)
+		var deniedErr *DeniedError
+		if assert.ErrorAs(t, err, &deniedErr) {
+			assert.Equal(t, status, deniedErr.Status)
+		}
+	}
+
+	denied(key.sign(t, claims(nil)), "badge.revoke", http.StatusForbidden)
+	denied(key.sign(t, claims(map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()})), "badge.read", http.StatusUnauthorized)
+	denied(key.sign(t, claims(map[string]interface{}{"aud": "portfolio"})), "badge.read", http.StatusUnauthorized)
+	denied(key.sign(t, claims(map[string]interface{}{"iss": "https://evil.example.com"})), "badge.read", http.StatusUnauthorized)
+	denied("not-a-token", "badge.read", http.StatusUnauthorized)
+
+	token := key.sign(t, claims(nil))
+	tampered := strings.Replace(token, strings.Split(token, ".")[1], b64([]byte(`{"sub":"u2"}`)), 1)
+	denied(tampered, "badge.read", http.StatusUnauthorized)
+
+	// A token signed by one key but claiming the kid of another is rejected.
+	forged := keys[1]
+	forged.kid = "rsa"
+	denied(forged.sign(t, claims(nil)), "badge.read", http.StatusUnauthorized)
+
+	admin := key.sign(t, claims(map[string]interface{}{"permissions": nil, "roles": "admin"}))
+	_, err = client.Authorize(context.Background(), admin, "badge.revoke")
+	assert.NoError(t, err, "admin roles should grant every permission")
+
+	scoped := key.sign(t, claims(map[string]interface{}{"permissions": "badge.read badge.update.own"}))
+	_, err = client.Authorize(context.Background(), scoped, "badge.update.own")
+	assert.NoError(t, err, "space separated permissions should be accepted")
+}
+
+type stubClient struct {
+	calls int
+}
+
+func (s *stubClient) Authorize(ctx context.Context, token string, permission string) (*Principal, error) {
+	s.calls++
+	return &Principal{UserID: "remote"}, nil
+}
+
+func TestJWTClientFallback(t *testing.T) {
+	keys := newTestKeys(t)
+	set, _ := ParseJWKS(jwks(keys[0]))
+	fallback := &stubClient{}
+
+	client := &JWTClient{Verifier: newVerifier(StaticKeySet(set)), Fallback: fallback}
+
+	principal, err := client.Authorize(context.Background(), keys[1].sign(t, claims(nil)), "badge.read")
+	assert.NoError(t, err)
+	assert.Equal(t, "remote", principal.UserID, "unknown keys should be checked remotely")
+
+	principal, err = client.Authorize(context.Background(), keys[0].sign(t, claims(map[string]interface{}{"permissions": nil})), "badge.read")
+	assert.NoError(t, err)
+	assert.Equal(t, "remote", principal.UserID, "tokens without permissions should be checked remotely")
+
+	_, err = client.Authorize(context.Background(), keys[0].sign(t, claims(nil)), "badge.revoke")
+	assert.Error(t, err, "denials of verified tokens are final")
+	assert.Equal(t, 2, fallback.calls)
+}
+
+func TestRemoteKeySet(t *testing.T) {
+	keys := newTestKeys(t)
+
+	var fetches int32
+	served := jwks(keys[0])
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		w.Write(served)
+	}))
+	defer server.Close()
+
+	set := NewRemoteKeySet(server.URL, time.Hour, time.Second)
+	now := time.Now()
+	set.now = func() time.Time { return now }
+
+	client := &JWTClient{Verifier: newVerifier(set)}
+
+	_, err := client.Authorize(context.Background(), keys[0].sign(t, claims(nil)), "badge.read")
+	assert.NoError(t, err)
+	_, err = client.Authorize(context.Background(), keys[0].sign(t, claims(nil)), "badge.read")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	// Keys are rotated: the unknown kid triggers a refetch once the minimum
+	// interval has passed.
+	served = jwks(keys[2])
+	now = now.Add(time.Minute)
+	_, err = client.Authorize(context.Background(), keys[2].sign(t, claims(nil)), "badge.read")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+
+	// The JWKS endpoint goes down, the cached keys keep being used.
+	server.Close()
+	now = now.Add(2 * time.Hour)
+	_, err = client.Authorize(context.Background(), keys[2].sign(t, claims(nil)), "badge.read")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"golang.org/x/sync/singleflight"
+)
+
+// deniedTTL caps how long a denial is cached, so that a permission granted
+// in the auth service is picked up quickly.
+const deniedTTL = 5 * time.Second
+
+// RemoteClient asks the Zuri auth service to authorize each token.
+// Results are cached for TTL and concurrent identical checks share a
+// single call.
+type RemoteClient struct {
+	BaseURL string
+	TTL     time.Duration
+
+	client *resty.Client
+	cache  *resultCache
+	group  singleflight.Group
+}
+
+func NewRemoteClient(baseURL string, timeout time.Duration, ttl time.Duration) *RemoteClient {
+	return &RemoteClient{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		TTL:     ttl,
+		client:  resty.New().SetTimeout(timeout),
+		cache:   newResultCache(ttl),
+	}
+}
+
+func (r *RemoteClient) Authorize(ctx context.Context, token string, permission string) (*Principal, error) {
+	key := cacheKey(token, permission)
+	if principal, err, ok := r.cache.get(key); ok {
+		return principal, err
+	}
+
+	// The shared call is not tied to the context of the first caller, so that
+	// it cancelling does not fail the others. It is bounded by the timeout.
+	results := r.group.DoChan(key, func() (interface{}, error) {
+		principal, err := r.authorize(context.Background(), token, permission)
+
+		var denied *DeniedError
+		switch {
+		case err == nil:
+			r.cache.set(key, principal, nil, r.TTL)
+		case errors.As(err, &denied):
+			r.cache.set(key, nil, err, minDuration(r.TTL, deniedTTL))
+		}
+
+		return principal, err
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-results:
+		principal, _ := result.Val.(*Principal)
+		return principal, result.Err
+	}
+}
+
+func (r *RemoteClient) authorize(ctx context.Context, token string, permission string) (*Principal, error) {
+	type authRequest struct {
+		Token      string `json:"token"`
+		Permission string `json:"permission"`
+	}
+
+	resp, err := r.client.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetBody(authRequest{Token: token, Permission: permission}).
+		Post(r.BaseURL + "/api/authorize")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	var authResp map[string]interface{}
+	json.Unmarshal(resp.Body(), &authResp)
+
+	if resp.StatusCode() >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("%w: responded with %d", ErrUnavailable, resp.StatusCode())
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, &DeniedError{Status: resp.StatusCode(), Message: authResp["message"]}
+	}
+
+	user, _ := authResp["user"].(map[string]interface{})
+	id, _ := user["id"].(string)
+
+	return &Principal{UserID: id}, nil
+}
+
+func minDuration(a time.Duration, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+
+	return b
+}
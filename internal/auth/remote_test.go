@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// authServer grants "badge.read" to the token "good".
+func authServer(t *testing.T, calls *int32, delay time.Duration) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		time.Sleep(delay)
+
+		var body struct {
+			Token      string `json:"token"`
+			Permission string `json:"permission"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		switch {
+		case body.Token == "down":
+			w.WriteHeader(http.StatusBadGateway)
+		case body.Token == "good" && body.Permission == "badge.read":
+			json.NewEncoder(w).Encode(map[string]interface{}{"user": map[string]interface{}{"id": "u1"}})
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]interface{}{"message": "Forbidden"})
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestRemoteClientCaches(t *testing.T) {
+	var calls int32
+	client := NewRemoteClient(authServer(t, &calls, 0).URL+"/", time.Second, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		principal, err := client.Authorize(context.Background(), "good", "badge.read")
+		assert.NoError(t, err)
+		assert.Equal(t, "u1", principal.UserID)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	_, err := client.Authorize(context.Background(), "good", "badge.revoke")
+	var denied *DeniedError
+	assert.ErrorAs(t, err, &denied)
+	assert.Equal(t, http.StatusForbidden, denied.Status)
+	assert.Equal(t, "Forbidden", denied.Message)
+
+	_, err = client.Authorize(context.Background(), "good", "badge.revoke")
+	assert.ErrorAs(t, err, &denied)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "denials should be cached too")
+
+	// Outages are not cached.
+	for i := 0; i < 2; i++ {
+		_, err = client.Authorize(context.Background(), "down", "badge.read")
+		assert.ErrorIs(t, err, ErrUnavailable)
+	}
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+}
+
+func TestRemoteClientExpires(t *testing.T) {
+	var calls int32
+	client := NewRemoteClient(authServer(t, &calls, 0).URL, time.Second, time.Minute)
+
+	now := time.Now()
+	client.cache.now = func() time.Time { return now }
+
+	client.Authorize(context.Background(), "good", "badge.read")
+	now = now.Add(2 * time.Minute)
+	client.Authorize(context.Background(), "good", "badge.read")
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestRemoteClientDeduplicates(t *testing.T) {
+	var calls int32
+	client := NewRemoteClient(authServer(t, &calls, 50*time.Millisecond).URL, time.Second, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			principal, err := client.Authorize(context.Background(), "good", "badge.read")
+			assert.NoError(t, err)
+			assert.Equal(t, "u1", principal.UserID)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.Authorize(ctx, "good", "badge.read")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRemoteClientTimeout(t *testing.T) {
+	var calls int32
+	client := NewRemoteClient(authServer(t, &calls, 200*time.Millisecond).URL, 20*time.Millisecond, time.Minute)
+
+	_, err := client.Authorize(context.Background(), "good", "badge.read")
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
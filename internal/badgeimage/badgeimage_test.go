@@ -0,0 +1,45 @@
+package badgeimage
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderSVG(t *testing.T) {
+	svg, err := RenderSVG(Badge{Skill: "R&D <Ops>", Tier: "Expert", Rank: 3}, "")
+	assert.NoError(t, err)
+
+	assert.Contains(t, string(svg), "R&amp;D &lt;Ops&gt;")
+	assert.Contains(t, string(svg), namedSchemes["expert"].Primary)
+	assert.Equal(t, 3+2, bytes.Count(svg, []byte("<polygon")))
+
+	long, err := RenderSVG(Badge{Skill: "Distributed Systems Engineering", Tier: "Gold"}, "hexagon")
+	assert.NoError(t, err)
+	assert.Contains(t, string(long), "Distributed Syste…")
+
+	_, err = RenderSVG(Badge{Skill: "Backend"}, "triangle")
+	assert.ErrorIs(t, err, ErrUnknownLayout)
+}
+
+func TestRenderPNG(t *testing.T) {
+	for _, layout := range []string{"shield", "circle", "hexagon"} {
+		content, err := RenderPNG(Badge{Skill: "Backend", Tier: "Beginner", Rank: 1}, layout)
+		assert.NoError(t, err)
+
+		img, err := png.Decode(bytes.NewReader(content))
+		assert.NoError(t, err)
+		assert.Equal(t, Size, img.Bounds().Dx())
+
+		_, _, _, alpha := img.At(0, 0).RGBA()
+		assert.Zero(t, alpha, "corners of %s should be transparent", layout)
+	}
+}
+
+func TestSchemeFor(t *testing.T) {
+	assert.Equal(t, namedSchemes["intermediate"], SchemeFor(Badge{Tier: "Intermediate", Rank: 2}))
+	assert.Equal(t, rankSchemes[1], SchemeFor(Badge{Tier: "Journeyman", Rank: 2}))
+	assert.Equal(t, rankSchemes[0], SchemeFor(Badge{Tier: "Novice", Rank: 6}))
+}
@@ -0,0 +1,133 @@
+package badgeimage
+
+import (
+	"errors"
+	"math"
+)
+
+// Size is the width and height of the artwork.
+const Size = 256
+
+var ErrUnknownLayout = errors.New("unknown badge layout")
+
+type point struct {
+	X, Y float64
+}
+
+// Layout is the geometry of a badge: its outline, the band the tier name is
+// written on and where the skill name goes.
+type Layout struct {
+	Name    string
+	Outline []point
+	// Inset is the scale of the inner shape drawn inside the outline.
+	Inset  float64
+	Ribbon [4]float64
+	SkillY float64
+	StarsY float64
+}
+
+const DefaultLayout = "shield"
+
+var layouts = map[string]Layout{
+	"shield": {
+		Name: "shield",
+		Outline: []point{
+			{128, 8}, {236, 44}, {228, 150}, {128, 248}, {28, 150}, {20, 44},
+		},
+		Inset:  0.86,
+		Ribbon: [4]float64{8, 150, 240, 40},
+		SkillY: 118,
+		StarsY: 62,
+	},
+	"circle": {
+		Name:    "circle",
+		Outline: regularPolygon(64, 120, 0),
+		Inset:   0.86,
+		Ribbon:  [4]float64{4, 156, 248, 40},
+		SkillY:  122,
+		StarsY:  64,
+	},
+	"hexagon": {
+		Name:    "hexagon",
+		Outline: regularPolygon(6, 122, math.Pi/6),
+		Inset:   0.86,
+		Ribbon:  [4]float64{4, 152, 248, 40},
+		SkillY:  120,
+		StarsY:  62,
+	},
+}
+
+func LayoutFor(name string) (Layout, error) {
+	if name == "" {
+		name = DefaultLayout
+	}
+
+	layout, ok := layouts[name]
+	if !ok {
+		return Layout{}, ErrUnknownLayout
+	}
+
+	return layout, nil
+}
+
+// inner returns the outline scaled around the centre of the canvas.
+func (l Layout) inner() []point {
+	scaled := make([]point, len(l.Outline))
+	for i, p := range l.Outline {
+		scaled[i] = point{
+			X: Size/2 + (p.X-Size/2)*l.Inset,
+			Y: Size/2 + (p.Y-Size/2)*l.Inset,
+		}
+	}
+
+	return scaled
+}
+
+// stars returns the centres of the rank stars, one per rank up to five.
+func (l Layout) stars(rank int) []point {
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > 5 {
+		rank = 5
+	}
+
+	const spacing = 22.0
+	start := Size/2 - spacing*float64(rank-1)/2
+
+	centres := make([]point, rank)
+	for i := range centres {
+		centres[i] = point{X: start + spacing*float64(i), Y: l.StarsY}
+	}
+
+	return centres
+}
+
+func regularPolygon(sides int, radius float64, rotation float64) []point {
+	points := make([]point, sides)
+	for i := range points {
+		angle := rotation + 2*math.Pi*float64(i)/float64(sides) - math.Pi/2
+		points[i] = point{
+			X: Size/2 + radius*math.Cos(angle),
+			Y: Size/2 + radius*math.Sin(angle),
+		}
+	}
+
+	return points
+}
+
+// star returns the outline of a five pointed star.
+func star(centre point, radius float64) []point {
+	points := make([]point, 10)
+	for i := range points {
+		r := radius
+		if i%2 == 1 {
+			r = radius * 0.45
+		}
+
+		angle := math.Pi*float64(i)/5 - math.Pi/2
+		points[i] = point{X: centre.X + r*math.Cos(angle), Y: centre.Y + r*math.Sin(angle)}
+	}
+
+	return points
+}
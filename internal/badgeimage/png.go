@@ -0,0 +1,93 @@
+package badgeimage
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// RenderPNG rasterises the same artwork as RenderSVG.
+func RenderPNG(badge Badge, layoutName string) ([]byte, error) {
+	layout, err := LayoutFor(layoutName)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := SchemeFor(badge)
+	canvas := image.NewRGBA(image.Rect(0, 0, Size, Size))
+
+	fillPolygon(canvas, layout.Outline, parseHex(scheme.Primary))
+	fillPolygon(canvas, layout.inner(), parseHex(scheme.Secondary))
+	for _, centre := range layout.stars(badge.Rank) {
+		fillPolygon(canvas, star(centre, 9), parseHex(scheme.Primary))
+	}
+
+	ribbon := layout.Ribbon
+	fillPolygon(canvas, []point{
+		{ribbon[0], ribbon[1]},
+		{ribbon[0] + ribbon[2], ribbon[1]},
+		{ribbon[0] + ribbon[2], ribbon[1] + ribbon[3]},
+		{ribbon[0], ribbon[1] + ribbon[3]},
+	}, parseHex(scheme.Accent))
+
+	drawText(canvas, truncate(badge.Skill), layout.SkillY, parseHex(scheme.Accent))
+	drawText(canvas, truncate(badge.Tier), ribbon[1]+ribbon[3]/2, parseHex(scheme.Text))
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, canvas); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func fillPolygon(dst *image.RGBA, points []point, c color.RGBA) {
+	rasterizer := vector.NewRasterizer(Size, Size)
+	rasterizer.MoveTo(float32(points[0].X), float32(points[0].Y))
+	for _, p := range points[1:] {
+		rasterizer.LineTo(float32(p.X), float32(p.Y))
+	}
+	rasterizer.ClosePath()
+
+	rasterizer.Draw(dst, dst.Bounds(), image.NewUniform(c), image.Point{})
+}
+
+// drawText writes the text centred horizontally on the line y, with the
+// built-in bitmap font scaled up to remain legible.
+func drawText(dst *image.RGBA, text string, y float64, c color.RGBA) {
+	text = strings.ReplaceAll(text, "…", "..")
+	if text == "" {
+		return
+	}
+
+	face := basicfont.Face7x13
+	drawer := font.Drawer{Face: face}
+	width := drawer.MeasureString(text).Ceil()
+	height := face.Height
+
+	glyphs := image.NewRGBA(image.Rect(0, 0, width, height))
+	drawer.Dst = glyphs
+	drawer.Src = image.NewUniform(c)
+	drawer.Dot = fixed.P(0, face.Ascent)
+	drawer.DrawString(text)
+
+	scale := 2
+	if width*scale > Size-16 {
+		scale = 1
+	}
+
+	x := (Size - width*scale) / 2
+	top := int(y) - height*scale/2
+	target := image.Rect(x, top, x+width*scale, top+height*scale)
+
+	xdraw.NearestNeighbor.Scale(dst, target, glyphs, glyphs.Bounds(), draw.Over, nil)
+}
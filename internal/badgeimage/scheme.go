@@ -0,0 +1,83 @@
+// Package badgeimage renders the artwork of skill badges as SVG and PNG, so
+// that emails, portfolios and Open Badges documents all show the same image.
+package badgeimage
+
+import (
+	"demerzel-badges/internal/models"
+	"fmt"
+	"image/color"
+	"strings"
+)
+
+// Badge holds what is drawn on a badge.
+type Badge struct {
+	Skill string
+	Tier  string
+	Rank  int
+}
+
+func FromSkillBadge(badge models.SkillBadge) Badge {
+	b := Badge{Tier: string(badge.Name), Rank: 1}
+
+	if badge.Skill != nil {
+		b.Skill = badge.Skill.CategoryName
+	}
+
+	if badge.Tier != nil {
+		b.Rank = badge.Tier.Rank
+		if badge.Tier.DisplayName != "" {
+			b.Tier = badge.Tier.DisplayName
+		}
+	}
+
+	return b
+}
+
+// Scheme is the colour scheme of a tier, as hex colours.
+type Scheme struct {
+	Primary   string
+	Secondary string
+	Accent    string
+	Text      string
+}
+
+// namedSchemes match well known tier names, other tiers are coloured by rank.
+var namedSchemes = map[string]Scheme{
+	"beginner":     {Primary: "#2E7D32", Secondary: "#A5D6A7", Accent: "#1B5E20", Text: "#FFFFFF"},
+	"intermediate": {Primary: "#1565C0", Secondary: "#90CAF9", Accent: "#0D47A1", Text: "#FFFFFF"},
+	"expert":       {Primary: "#6A1B9A", Secondary: "#CE93D8", Accent: "#4A148C", Text: "#FFFFFF"},
+	"bronze":       {Primary: "#8D5524", Secondary: "#E0A96D", Accent: "#5D3A1A", Text: "#FFFFFF"},
+	"silver":       {Primary: "#757575", Secondary: "#E0E0E0", Accent: "#424242", Text: "#FFFFFF"},
+	"gold":         {Primary: "#C79A00", Secondary: "#FFE082", Accent: "#7F6000", Text: "#FFFFFF"},
+	"platinum":     {Primary: "#455A64", Secondary: "#CFD8DC", Accent: "#263238", Text: "#FFFFFF"},
+}
+
+var rankSchemes = []Scheme{
+	namedSchemes["bronze"],
+	namedSchemes["silver"],
+	namedSchemes["gold"],
+	namedSchemes["platinum"],
+	{Primary: "#00838F", Secondary: "#B2EBF2", Accent: "#004D40", Text: "#FFFFFF"},
+}
+
+func SchemeFor(badge Badge) Scheme {
+	if scheme, ok := namedSchemes[strings.ToLower(badge.Tier)]; ok {
+		return scheme
+	}
+
+	rank := badge.Rank
+	if rank < 1 {
+		rank = 1
+	}
+
+	return rankSchemes[(rank-1)%len(rankSchemes)]
+}
+
+func parseHex(hex string) color.RGBA {
+	var c color.RGBA
+	c.A = 0xff
+
+	fmt.Sscanf(strings.TrimPrefix(hex, "#"), "%02x%02x%02x", &c.R, &c.G, &c.B)
+
+	return c
+}
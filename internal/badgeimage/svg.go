@@ -0,0 +1,77 @@
+package badgeimage
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+var svgTemplate = template.Must(template.New("badge").Funcs(template.FuncMap{
+	"escape": template.HTMLEscapeString,
+	"points": svgPoints,
+	"star":   func(p point) string { return svgPoints(star(p, 9)) },
+}).Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Size}}" height="{{.Size}}" viewBox="0 0 {{.Size}} {{.Size}}" role="img" aria-label="{{escape .Label}}">
+  <title>{{escape .Label}}</title>
+  <polygon points="{{points .Layout.Outline}}" fill="{{.Scheme.Primary}}"/>
+  <polygon points="{{points .Inner}}" fill="{{.Scheme.Secondary}}"/>
+  {{- range .Stars}}
+  <polygon points="{{star .}}" fill="{{$.Scheme.Primary}}"/>
+  {{- end}}
+  <text x="{{.Half}}" y="{{.Layout.SkillY}}" text-anchor="middle" dominant-baseline="middle" font-family="Helvetica, Arial, sans-serif" font-weight="bold" font-size="{{.SkillFontSize}}" fill="{{.Scheme.Accent}}">{{escape .Skill}}</text>
+  <rect x="{{index .Layout.Ribbon 0}}" y="{{index .Layout.Ribbon 1}}" width="{{index .Layout.Ribbon 2}}" height="{{index .Layout.Ribbon 3}}" rx="6" fill="{{.Scheme.Accent}}"/>
+  <text x="{{.Half}}" y="{{.TierY}}" text-anchor="middle" dominant-baseline="middle" font-family="Helvetica, Arial, sans-serif" font-weight="bold" font-size="20" fill="{{.Scheme.Text}}">{{escape .Tier}}</text>
+</svg>
+`))
+
+// maxLabelLength is the longest text drawn on a badge before truncation.
+const maxLabelLength = 18
+
+// RenderSVG draws the badge with the given layout, the default one if empty.
+func RenderSVG(badge Badge, layoutName string) ([]byte, error) {
+	layout, err := LayoutFor(layoutName)
+	if err != nil {
+		return nil, err
+	}
+
+	skill := truncate(badge.Skill)
+	fontSize := 22
+	if len(skill) > 12 {
+		fontSize = 264 / len(skill)
+	}
+
+	var buf bytes.Buffer
+	err = svgTemplate.Execute(&buf, map[string]interface{}{
+		"Size":          Size,
+		"Half":          Size / 2,
+		"Label":         strings.TrimSpace(badge.Skill + " " + badge.Tier),
+		"Layout":        layout,
+		"Inner":         layout.inner(),
+		"Stars":         layout.stars(badge.Rank),
+		"Scheme":        SchemeFor(badge),
+		"Skill":         skill,
+		"SkillFontSize": fontSize,
+		"Tier":          truncate(badge.Tier),
+		"TierY":         layout.Ribbon[1] + layout.Ribbon[3]/2,
+	})
+
+	return buf.Bytes(), err
+}
+
+func svgPoints(points []point) string {
+	parts := make([]string, len(points))
+	for i, p := range points {
+		parts[i] = fmt.Sprintf("%.1f,%.1f", p.X, p.Y)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func truncate(text string) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= maxLabelLength {
+		return string(runes)
+	}
+
+	return string(runes[:maxLabelLength-1]) + "…"
+}
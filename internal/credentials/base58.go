@@ -0,0 +1,68 @@
+package credentials
+
+import (
+	"errors"
+	"math/big"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var errInvalidBase58 = errors.New("invalid base58 string")
+
+// encodeBase58 encodes bytes with the bitcoin alphabet, as used by the
+// base58btc multibase encoding.
+func encodeBase58(input []byte) string {
+	value := new(big.Int).SetBytes(input)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+
+	var encoded []byte
+	for value.Sign() > 0 {
+		value.DivMod(value, radix, mod)
+		encoded = append(encoded, base58Alphabet[mod.Int64()])
+	}
+
+	for _, b := range input {
+		if b != 0 {
+			break
+		}
+		encoded = append(encoded, base58Alphabet[0])
+	}
+
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+
+	return string(encoded)
+}
+
+func decodeBase58(input string) ([]byte, error) {
+	value := new(big.Int)
+	radix := big.NewInt(58)
+
+	for _, r := range input {
+		index := -1
+		for i, a := range base58Alphabet {
+			if a == r {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, errInvalidBase58
+		}
+
+		value.Mul(value, radix)
+		value.Add(value, big.NewInt(int64(index)))
+	}
+
+	decoded := value.Bytes()
+	for _, r := range input {
+		if r != rune(base58Alphabet[0]) {
+			break
+		}
+		decoded = append([]byte{0}, decoded...)
+	}
+
+	return decoded, nil
+}
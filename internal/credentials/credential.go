@@ -0,0 +1,147 @@
+// Package credentials issues badges as Open Badges 3.0 OpenBadgeCredentials,
+// W3C verifiable credentials signed with the issuer's Ed25519 key, either as
+// a VC-JWT or with an embedded eddsa-jcs-2022 Data Integrity proof.
+package credentials
+
+import (
+	"crypto/ed25519"
+	"demerzel-badges/internal/models"
+	"demerzel-badges/internal/openbadges"
+	"time"
+)
+
+var Contexts = []string{
+	"https://www.w3.org/ns/credentials/v2",
+	"https://purl.imsglobal.org/spec/ob/v3p0/context-3.0.3.json",
+}
+
+type Profile struct {
+	ID   string   `json:"id"`
+	Type []string `json:"type"`
+	Name string   `json:"name"`
+	URL  string   `json:"url,omitempty"`
+}
+
+type Image struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+type Criteria struct {
+	Narrative string `json:"narrative"`
+}
+
+type Achievement struct {
+	ID          string   `json:"id"`
+	Type        []string `json:"type"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Criteria    Criteria `json:"criteria"`
+	Image       *Image   `json:"image,omitempty"`
+}
+
+type IdentityObject struct {
+	Type         string `json:"type"`
+	IdentityHash string `json:"identityHash"`
+	IdentityType string `json:"identityType"`
+	Hashed       bool   `json:"hashed"`
+	Salt         string `json:"salt,omitempty"`
+}
+
+type AchievementSubject struct {
+	Type        []string         `json:"type"`
+	Identifier  []IdentityObject `json:"identifier"`
+	Achievement Achievement      `json:"achievement"`
+}
+
+type Credential struct {
+	Context           []string           `json:"@context"`
+	ID                string             `json:"id"`
+	Type              []string           `json:"type"`
+	Issuer            Profile            `json:"issuer"`
+	ValidFrom         string             `json:"validFrom"`
+	ValidUntil        string             `json:"validUntil,omitempty"`
+	Name              string             `json:"name"`
+	CredentialSubject AchievementSubject `json:"credentialSubject"`
+}
+
+// Issuer signs and verifies credentials. Its identifier is the did:key of
+// its public key, so verifiers need nothing but the credential itself.
+type Issuer struct {
+	key  ed25519.PrivateKey
+	did  string
+	Name string
+	URL  string
+
+	now func() time.Time
+}
+
+func NewIssuer(key ed25519.PrivateKey, name string, url string) *Issuer {
+	return &Issuer{
+		key:  key,
+		did:  DIDKey(key.Public().(ed25519.PublicKey)),
+		Name: name,
+		URL:  url,
+		now:  time.Now,
+	}
+}
+
+func (i *Issuer) DID() string {
+	return i.did
+}
+
+// VerificationMethod is the key identifier referenced by signatures.
+func (i *Issuer) VerificationMethod() string {
+	return i.did + "#" + multibaseKey(i.key.Public().(ed25519.PublicKey))
+}
+
+// Credential builds the unsigned credential of a user badge. The user badge
+// must have its User, Badge, Badge.Skill and Badge.Tier loaded.
+func (i *Issuer) Credential(builder openbadges.Builder, userBadge models.UserBadge, id string) (Credential, error) {
+	assertion, err := builder.Assertion(userBadge)
+	if err != nil {
+		return Credential{}, err
+	}
+
+	badge := models.SkillBadge{ID: userBadge.BadgeID}
+	if userBadge.Badge != nil {
+		badge = *userBadge.Badge
+	}
+	badgeClass := builder.BadgeClass(badge)
+
+	achievement := Achievement{
+		ID:          badgeClass.ID,
+		Type:        []string{"Achievement"},
+		Name:        badgeClass.Name,
+		Description: badgeClass.Description,
+		Criteria:    Criteria{Narrative: badgeClass.Criteria.Narrative},
+	}
+	if badgeClass.Image != "" {
+		achievement.Image = &Image{ID: badgeClass.Image, Type: "Image"}
+	}
+
+	return Credential{
+		Context: Contexts,
+		ID:      id,
+		Type:    []string{"VerifiableCredential", "OpenBadgeCredential"},
+		Issuer: Profile{
+			ID:   i.did,
+			Type: []string{"Profile"},
+			Name: i.Name,
+			URL:  i.URL,
+		},
+		ValidFrom: userBadge.CreatedAt.UTC().Format(time.RFC3339),
+		Name:      badgeClass.Name,
+		CredentialSubject: AchievementSubject{
+			Type: []string{"AchievementSubject"},
+			Identifier: []IdentityObject{{
+				Type:         "IdentityObject",
+				IdentityHash: assertion.Recipient.Identity,
+				IdentityType: "emailAddress",
+				Hashed:       true,
+				Salt:         assertion.Recipient.Salt,
+			}},
+			Achievement: achievement,
+		},
+	}, nil
+}
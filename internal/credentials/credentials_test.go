@@ -0,0 +1,131 @@
+package credentials
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testIssuer(t *testing.T) *Issuer {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return NewIssuer(key, "Zuri Portfolio", "https://zuri.team")
+}
+
+func testCredential(issuer *Issuer) Credential {
+	return Credential{
+		Context:   Contexts,
+		ID:        "https://badges.example.com/api/badges/credentials/12",
+		Type:      []string{"VerifiableCredential", "OpenBadgeCredential"},
+		Issuer:    Profile{ID: issuer.DID(), Type: []string{"Profile"}, Name: issuer.Name},
+		ValidFrom: "2023-09-20T17:28:42Z",
+		Name:      "Backend Expert",
+		CredentialSubject: AchievementSubject{
+			Type: []string{"AchievementSubject"},
+			Achievement: Achievement{
+				ID:   "https://badges.example.com/api/badges/openbadges/badges/3",
+				Type: []string{"Achievement"},
+				Name: "Backend Expert <Gold>",
+			},
+		},
+	}
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	fromSeed, err := ParsePrivateKey(base64.StdEncoding.EncodeToString(key.Seed()))
+	assert.NoError(t, err)
+	assert.Equal(t, key, fromSeed)
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	fromPEM, err := ParsePrivateKey(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})))
+	assert.NoError(t, err)
+	assert.Equal(t, key, fromPEM)
+
+	_, err = ParsePrivateKey("bm90IGEga2V5")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestDIDKeyRoundTrip(t *testing.T) {
+	issuer := testIssuer(t)
+
+	assert.True(t, strings.HasPrefix(issuer.DID(), "did:key:z6Mk"))
+
+	publicKey, err := PublicKeyFromDID(issuer.VerificationMethod())
+	assert.NoError(t, err)
+	assert.Equal(t, issuer.key.Public(), publicKey)
+}
+
+func TestJWT(t *testing.T) {
+	issuer := testIssuer(t)
+
+	token, err := issuer.SignJWT(testCredential(issuer))
+	require.NoError(t, err)
+
+	claims, err := issuer.VerifyJWT(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://badges.example.com/api/badges/credentials/12", CredentialID(claims))
+
+	parts := strings.Split(token, ".")
+	tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"x"}`)) + "." + parts[2]
+	_, err = issuer.VerifyJWT(tampered)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	_, err = testIssuer(t).VerifyJWT(token)
+	assert.ErrorIs(t, err, ErrUnknownIssuer)
+}
+
+func TestJWTValidity(t *testing.T) {
+	issuer := testIssuer(t)
+
+	credential := testCredential(issuer)
+	credential.ValidUntil = "2024-09-20T17:28:42Z"
+	token, err := issuer.SignJWT(credential)
+	require.NoError(t, err)
+
+	issuer.now = func() time.Time { return time.Date(2023, 9, 20, 17, 28, 42, 0, time.UTC) }
+	_, err = issuer.VerifyJWT(token)
+	assert.NoError(t, err)
+
+	issuer.now = func() time.Time { return time.Date(2023, 9, 19, 0, 0, 0, 0, time.UTC) }
+	_, err = issuer.VerifyJWT(token)
+	assert.ErrorIs(t, err, ErrNotYetValid)
+
+	issuer.now = func() time.Time { return time.Date(2024, 9, 21, 0, 0, 0, 0, time.UTC) }
+	_, err = issuer.VerifyJWT(token)
+	assert.ErrorIs(t, err, ErrExpired)
+}
+
+func TestEmbeddedProof(t *testing.T) {
+	issuer := testIssuer(t)
+
+	document, err := issuer.SignDocument(testCredential(issuer), time.Now())
+	require.NoError(t, err)
+	assert.NoError(t, issuer.VerifyDocument(document))
+
+	document["name"] = "Frontend Expert"
+	assert.ErrorIs(t, issuer.VerifyDocument(document), ErrInvalidSignature)
+
+	other := testIssuer(t)
+	document, err = other.SignDocument(testCredential(other), time.Now())
+	require.NoError(t, err)
+	assert.ErrorIs(t, issuer.VerifyDocument(document), ErrUnknownIssuer)
+}
+
+func TestCanonicalize(t *testing.T) {
+	canonical, err := canonicalize(map[string]interface{}{"b": "<&>", "a": []int{1, 2}})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":[1,2],"b":"<&>"}`, string(canonical))
+}
@@ -0,0 +1,91 @@
+package credentials
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidKey = errors.New("invalid Ed25519 signing key")
+	ErrInvalidDID = errors.New("unsupported or invalid did:key")
+)
+
+// ed25519Multicodec prefixes Ed25519 public keys in did:key identifiers.
+var ed25519Multicodec = []byte{0xed, 0x01}
+
+// ParsePrivateKey reads an Ed25519 private key from a PKCS#8 PEM block, or
+// from the base64 encoding of either the 32 byte seed or the 64 byte key.
+func ParsePrivateKey(encoded string) (ed25519.PrivateKey, error) {
+	encoded = strings.TrimSpace(encoded)
+
+	if block, _ := pem.Decode([]byte(encoded)); block != nil {
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidKey, err.Error())
+		}
+
+		privateKey, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, ErrInvalidKey
+		}
+
+		return privateKey, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		raw, err = base64.RawURLEncoding.DecodeString(encoded)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKey, err.Error())
+	}
+
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		privateKey := ed25519.PrivateKey(raw)
+		derived := ed25519.NewKeyFromSeed(privateKey.Seed())
+		if !bytes.Equal(derived, privateKey) {
+			return nil, ErrInvalidKey
+		}
+		return privateKey, nil
+	}
+
+	return nil, ErrInvalidKey
+}
+
+// DIDKey returns the did:key identifier of an Ed25519 public key.
+func DIDKey(publicKey ed25519.PublicKey) string {
+	return "did:key:" + multibaseKey(publicKey)
+}
+
+func multibaseKey(publicKey ed25519.PublicKey) string {
+	return "z" + encodeBase58(append(append([]byte{}, ed25519Multicodec...), publicKey...))
+}
+
+// PublicKeyFromDID extracts the Ed25519 public key of a did:key identifier
+// or verification method.
+func PublicKeyFromDID(did string) (ed25519.PublicKey, error) {
+	did = strings.SplitN(did, "#", 2)[0]
+	if !strings.HasPrefix(did, "did:key:z") {
+		return nil, ErrInvalidDID
+	}
+
+	decoded, err := decodeBase58(strings.TrimPrefix(did, "did:key:z"))
+	if err != nil {
+		return nil, ErrInvalidDID
+	}
+
+	if len(decoded) != len(ed25519Multicodec)+ed25519.PublicKeySize || !bytes.HasPrefix(decoded, ed25519Multicodec) {
+		return nil, ErrInvalidDID
+	}
+
+	return ed25519.PublicKey(decoded[len(ed25519Multicodec):]), nil
+}
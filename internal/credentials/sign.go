@@ -0,0 +1,329 @@
+package credentials
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	ProofType   = "DataIntegrityProof"
+	Cryptosuite = "eddsa-jcs-2022"
+)
+
+var (
+	ErrMalformedCredential = errors.New("malformed credential")
+	ErrInvalidSignature    = errors.New("invalid credential signature")
+	ErrUnknownIssuer       = errors.New("credential was not issued by this service")
+	ErrNotYetValid         = errors.New("credential is not valid yet")
+	ErrExpired             = errors.New("credential has expired")
+)
+
+// leeway tolerates clock skew with the verifiers of the credentials.
+const leeway = 30 * time.Second
+
+// SignJWT encodes the credential as a VC-JWT. The JWT claims are the
+// credential itself along with the iss, jti and nbf registered claims, and
+// exp when the credential has a validUntil date.
+func (i *Issuer) SignJWT(credential Credential) (string, error) {
+	claims, err := toMap(credential)
+	if err != nil {
+		return "", err
+	}
+
+	validFrom, err := time.Parse(time.RFC3339, credential.ValidFrom)
+	if err != nil {
+		return "", err
+	}
+
+	claims["iss"] = i.did
+	claims["jti"] = credential.ID
+	claims["nbf"] = validFrom.Unix()
+
+	if credential.ValidUntil != "" {
+		validUntil, err := time.Parse(time.RFC3339, credential.ValidUntil)
+		if err != nil {
+			return "", err
+		}
+		claims["exp"] = validUntil.Unix()
+	}
+
+	header, err := json.Marshal(map[string]string{
+		"alg": "EdDSA",
+		"typ": "JWT",
+		"kid": i.VerificationMethod(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	signature := ed25519.Sign(i.key, []byte(signingInput))
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// VerifyJWT checks the signature of a VC-JWT against the did:key it names,
+// that this issuer signed it and that it is valid at the current time, and
+// returns its claims.
+func (i *Issuer) VerifyJWT(token string) (map[string]interface{}, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedCredential
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+
+	if header.Alg != "EdDSA" {
+		return nil, ErrInvalidSignature
+	}
+
+	publicKey, err := PublicKeyFromDID(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !ed25519.Verify(publicKey, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	if iss, _ := claims["iss"].(string); iss != i.did || !strings.HasPrefix(header.Kid, i.did) {
+		return claims, ErrUnknownIssuer
+	}
+
+	now := i.now()
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(leeway).Before(nbf) {
+		return claims, ErrNotYetValid
+	}
+
+	if exp, ok := numericDate(claims["exp"]); ok && now.After(exp.Add(leeway)) {
+		return claims, ErrExpired
+	}
+
+	return claims, nil
+}
+
+// SignDocument returns the credential with an embedded eddsa-jcs-2022 Data
+// Integrity proof.
+func (i *Issuer) SignDocument(credential Credential, created time.Time) (map[string]interface{}, error) {
+	document, err := toMap(credential)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := map[string]interface{}{
+		"type":               ProofType,
+		"cryptosuite":        Cryptosuite,
+		"created":            created.UTC().Format(time.RFC3339),
+		"verificationMethod": i.VerificationMethod(),
+		"proofPurpose":       "assertionMethod",
+	}
+
+	hashData, err := proofHashData(document, proof)
+	if err != nil {
+		return nil, err
+	}
+
+	proof["proofValue"] = "z" + encodeBase58(ed25519.Sign(i.key, hashData))
+	document["proof"] = proof
+
+	return document, nil
+}
+
+// VerifyDocument checks the embedded proof of a credential against the
+// did:key it names and that this issuer signed it.
+func (i *Issuer) VerifyDocument(document map[string]interface{}) error {
+	proof, ok := document["proof"].(map[string]interface{})
+	if !ok {
+		return ErrMalformedCredential
+	}
+
+	if proof["type"] != ProofType || proof["cryptosuite"] != Cryptosuite {
+		return ErrInvalidSignature
+	}
+
+	proofValue, _ := proof["proofValue"].(string)
+	verificationMethod, _ := proof["verificationMethod"].(string)
+	if !strings.HasPrefix(proofValue, "z") {
+		return ErrMalformedCredential
+	}
+
+	signature, err := decodeBase58(strings.TrimPrefix(proofValue, "z"))
+	if err != nil {
+		return ErrMalformedCredential
+	}
+
+	publicKey, err := PublicKeyFromDID(verificationMethod)
+	if err != nil {
+		return err
+	}
+
+	unsecured := make(map[string]interface{}, len(document))
+	for key, value := range document {
+		if key != "proof" {
+			unsecured[key] = value
+		}
+	}
+
+	config := make(map[string]interface{}, len(proof))
+	for key, value := range proof {
+		if key != "proofValue" {
+			config[key] = value
+		}
+	}
+
+	hashData, err := proofHashData(unsecured, config)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(publicKey, hashData, signature) {
+		return ErrInvalidSignature
+	}
+
+	if issuerID(document) != i.did || !strings.HasPrefix(verificationMethod, i.did) {
+		return ErrUnknownIssuer
+	}
+
+	return nil
+}
+
+// proofHashData hashes the proof configuration and the document as
+// eddsa-jcs-2022 requires. The proof configuration shares the document's
+// context.
+func proofHashData(document map[string]interface{}, proof map[string]interface{}) ([]byte, error) {
+	config := make(map[string]interface{}, len(proof)+1)
+	for key, value := range proof {
+		config[key] = value
+	}
+	if ctx, ok := document["@context"]; ok {
+		config["@context"] = ctx
+	}
+
+	canonicalConfig, err := canonicalize(config)
+	if err != nil {
+		return nil, err
+	}
+
+	canonicalDocument, err := canonicalize(document)
+	if err != nil {
+		return nil, err
+	}
+
+	configHash := sha256.Sum256(canonicalConfig)
+	documentHash := sha256.Sum256(canonicalDocument)
+
+	return append(configHash[:], documentHash[:]...), nil
+}
+
+// canonicalize serialises a value following the JSON Canonicalization Scheme
+// (RFC 8785): sorted object keys, no insignificant whitespace and no escaping
+// beyond what JSON requires.
+func canonicalize(value interface{}) ([]byte, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(generic); err != nil {
+		return nil, err
+	}
+
+	canonical := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	canonical = bytes.ReplaceAll(canonical, []byte(`\u2028`), []byte("\u2028"))
+	canonical = bytes.ReplaceAll(canonical, []byte(`\u2029`), []byte("\u2029"))
+
+	return canonical, nil
+}
+
+func toMap(value interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var result map[string]interface{}
+	err = json.Unmarshal(raw, &result)
+
+	return result, err
+}
+
+func decodeSegment(segment string, value interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrMalformedCredential
+	}
+
+	if err := json.Unmarshal(raw, value); err != nil {
+		return ErrMalformedCredential
+	}
+
+	return nil
+}
+
+// numericDate reads a JWT date claim, a number of seconds since the epoch.
+func numericDate(value interface{}) (time.Time, bool) {
+	seconds, ok := value.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return time.Unix(int64(seconds), 0), true
+}
+
+// issuerID returns the issuer of a credential, given either as a URL or as a
+// profile object.
+func issuerID(credential map[string]interface{}) string {
+	switch issuer := credential["issuer"].(type) {
+	case string:
+		return issuer
+	case map[string]interface{}:
+		id, _ := issuer["id"].(string)
+		return id
+	}
+
+	return ""
+}
+
+// CredentialID returns the id of a credential or of the claims of a VC-JWT.
+func CredentialID(credential map[string]interface{}) string {
+	if id, ok := credential["id"].(string); ok {
+		return id
+	}
+
+	id, _ := credential["jti"].(string)
+
+	return id
+}
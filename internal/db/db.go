@@ -5,28 +5,40 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"log"
-	"os"
 )
 
 var DB *gorm.DB
 
-func SetupDB() {
-	dbUsername := os.Getenv("POSTGRES_USERNAME")
-	dbPass := os.Getenv("POSTGRES_PASSWORD")
-	dbHost := os.Getenv("POSTGRES_HOST")
-	dbName := os.Getenv("POSTGRES_DBNAME")
-	dbPortStr := os.Getenv("POSTGRES_PORT")
+// Config is the database of the POSTGRES_* variables. AutoMigrate applies
+// the pending migrations at startup, and SchemaCheck is the mode of the
+// startup schema check.
+type Config struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	Name     string
+
+	AutoMigrate bool
+	SchemaCheck string
+}
 
+// Connect opens the database of cfg.
+func Connect(cfg Config) (*gorm.DB, error) {
 	dsn := fmt.Sprintf(
 		"host=%s user=%s password=%s dbname=%s port=%s TimeZone=Africa/Lagos",
-		dbHost,
-		dbUsername,
-		dbPass,
-		dbName,
-		dbPortStr,
+		cfg.Host,
+		cfg.Username,
+		cfg.Password,
+		cfg.Name,
+		cfg.Port,
 	)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	return gorm.Open(postgres.Open(dsn), &gorm.Config{})
+}
+
+func SetupDB(cfg Config) {
+	db, err := Connect(cfg)
 
 	if err != nil {
 		log.Fatal(err)
@@ -34,8 +46,15 @@ func SetupDB() {
 
 	DB = db
 
-	err = Migrate()
+	if cfg.AutoMigrate {
+		err = Migrate()
+		if err != nil {
+			log.Fatal("Failed to migrate DB:", err)
+		}
+	}
+
+	err = VerifySchema(cfg.SchemaCheck)
 	if err != nil {
-		log.Fatal("Failed to migrate DB:", err)
+		log.Fatal("Failed to verify DB schema:", err)
 	}
 }
@@ -0,0 +1,41 @@
+// Package dbtest opens the Postgres database the tests needing one run
+// against. It is named by TEST_DATABASE_URL and wiped by every test, the
+// tests are skipped when it is not set.
+package dbtest
+
+import (
+	"os"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Open connects to the test database and empties it.
+func Open(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("unable to open the test database: %v", err)
+	}
+
+	err = db.Exec(`DROP SCHEMA public CASCADE; CREATE SCHEMA public`).Error
+	if err != nil {
+		t.Fatalf("unable to empty the test database: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	return db
+}
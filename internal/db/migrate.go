@@ -1,28 +1,330 @@
 package db
 
 import (
-	"demerzel-badges/internal/models"
-	"os"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"gorm.io/gorm"
 )
 
-func Migrate() error {
-	environment := os.Getenv("ENV")
-	if strings.ToLower(environment) == "production" {
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
+// migrationLockID identifies the advisory lock held while migrating, so that
+// instances starting together do not apply the same migrations.
+const migrationLockID = 4_170_525_102
+
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+var ErrUnknownMigration = errors.New("applied migration is not known to this version of the service")
+
+// Migration is one change of the schema, Down reverts Up.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus tells whether a migration has been applied.
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// SchemaMigration records an applied migration.
+type SchemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(255);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (m SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// LoadMigrations reads the migrations of files, named
+// <version>_<name>.up.sql and <version>_<name>.down.sql, ordered by version.
+func LoadMigrations(files fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(files, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, name := range names {
+		match := migrationFile.FindStringSubmatch(path.Base(name))
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: name should be <version>_<name>.up.sql or .down.sql", name)
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", name, err)
+		}
+
+		content, err := fs.ReadFile(files, name)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.Up) == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", migration.Version, migration.Name)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Migrations returns the migrations embedded in the service.
+func Migrations() ([]Migration, error) {
+	files, err := fs.Sub(embeddedMigrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadMigrations(files)
+}
+
+// Migrator applies and reverts migrations, recording them in the
+// schema_migrations table.
+type Migrator struct {
+	DB         *gorm.DB
+	Migrations []Migration
+}
+
+// NewMigrator returns a migrator of the embedded migrations.
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{DB: db, Migrations: migrations}, nil
+}
+
+// Up applies the pending migrations in order and returns them.
+func (m *Migrator) Up() ([]Migration, error) {
+	var done []Migration
+
+	err := m.locked(func(conn *gorm.DB) error {
+		applied, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range pendingMigrations(m.Migrations, applied) {
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Up).Error; err != nil {
+					return err
+				}
+
+				return tx.Create(&SchemaMigration{
+					Version:   migration.Version,
+					Name:      migration.Name,
+					AppliedAt: time.Now(),
+				}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			done = append(done, migration)
+		}
+
 		return nil
+	})
+
+	return done, err
+}
+
+// Down reverts the last steps applied migrations and returns them.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	var done []Migration
+
+	err := m.locked(func(conn *gorm.DB) error {
+		applied, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		rollback, err := rollbackMigrations(m.Migrations, applied, steps)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range rollback {
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if strings.TrimSpace(migration.Down) != "" {
+					if err := tx.Exec(migration.Down).Error; err != nil {
+						return err
+					}
+				}
+
+				return tx.Delete(&SchemaMigration{}, migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			done = append(done, migration)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Status lists the migrations with the time they were applied at, if they
+// were.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+
+	err := m.locked(func(conn *gorm.DB) error {
+		var records []SchemaMigration
+		if err := conn.Find(&records).Error; err != nil {
+			return err
+		}
+
+		appliedAt := map[int64]time.Time{}
+		for _, record := range records {
+			appliedAt[record.Version] = record.AppliedAt
+		}
+
+		for _, migration := range m.Migrations {
+			status := MigrationStatus{Migration: migration}
+			if at, ok := appliedAt[migration.Version]; ok {
+				status.AppliedAt = &at
+			}
+
+			statuses = append(statuses, status)
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// locked runs fn on a single connection holding the migration advisory lock,
+// once the schema_migrations table exists.
+func (m *Migrator) locked(fn func(conn *gorm.DB) error) error {
+	return m.DB.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockID).Error; err != nil {
+			return err
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockID)
+
+		err := conn.Exec(`CREATE TABLE IF NOT EXISTS "schema_migrations" (
+			"version" BIGINT PRIMARY KEY,
+			"name" VARCHAR(255) NOT NULL,
+			"applied_at" TIMESTAMP NOT NULL
+		)`).Error
+		if err != nil {
+			return err
+		}
+
+		return fn(conn)
+	})
+}
+
+func appliedVersions(db *gorm.DB) (map[int64]bool, error) {
+	var versions []int64
+	if err := db.Model(&SchemaMigration{}).Pluck("version", &versions).Error; err != nil {
+		return nil, err
+	}
+
+	applied := map[int64]bool{}
+	for _, version := range versions {
+		applied[version] = true
+	}
+
+	return applied, nil
+}
+
+// pendingMigrations returns the migrations not applied yet, in order.
+func pendingMigrations(migrations []Migration, applied map[int64]bool) []Migration {
+	var pending []Migration
+	for _, migration := range migrations {
+		if !applied[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending
+}
+
+// rollbackMigrations returns the last steps applied migrations, latest first.
+// A migration applied by a newer version of the service cannot be reverted.
+func rollbackMigrations(migrations []Migration, applied map[int64]bool, steps int) ([]Migration, error) {
+	known := map[int64]Migration{}
+	for _, migration := range migrations {
+		known[migration.Version] = migration
+	}
+
+	versions := make([]int64, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+	var rollback []Migration
+	for _, version := range versions {
+		if len(rollback) == steps {
+			break
+		}
+
+		migration, ok := known[version]
+		if !ok {
+			return nil, fmt.Errorf("%w: version %d", ErrUnknownMigration, version)
+		}
+
+		rollback = append(rollback, migration)
+	}
+
+	return rollback, nil
+}
+
+// Migrate applies the pending migrations to DB.
+func Migrate() error {
+	migrator, err := NewMigrator(DB)
+	if err != nil {
+		return err
 	}
 
-	return DB.AutoMigrate(
-		&models.User{},
-		&models.Role{},
-		&models.Permission{},
-		&models.UserPermission{},
-		&models.RolePermission{},
-		&models.Skill{},
-		&models.Assessment{},
-		&models.UserAssessment{},
-		&models.SkillBadge{},
-		&models.UserBadge{},
-	)
+	_, err = migrator.Up()
 
+	return err
 }
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"demerzel-badges/internal/db/dbtest"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func file(content string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(content)}
+}
+
+func TestLoadMigrationsOrdersByVersion(t *testing.T) {
+	migrations, err := LoadMigrations(fstest.MapFS{
+		"0010_later.up.sql":   file("CREATE TABLE later ();"),
+		"0002_first.up.sql":   file("CREATE TABLE first ();"),
+		"0002_first.down.sql": file("DROP TABLE first;"),
+		"0010_later.down.sql": file("DROP TABLE later;"),
+		"0003_no_down.up.sql": file("SELECT 1;"),
+	})
+
+	assert.NoError(t, err)
+	if assert.Len(t, migrations, 3) {
+		assert.Equal(t, int64(2), migrations[0].Version)
+		assert.Equal(t, "first", migrations[0].Name)
+		assert.Equal(t, "DROP TABLE first;", migrations[0].Down)
+		assert.Equal(t, int64(3), migrations[1].Version)
+		assert.Empty(t, migrations[1].Down)
+		assert.Equal(t, int64(10), migrations[2].Version)
+	}
+}
+
+func TestLoadMigrationsRejectsInvalidSets(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"bad name":      {"first.up.sql": file("SELECT 1;")},
+		"no up":         {"0001_first.down.sql": file("SELECT 1;")},
+		"name conflict": {"0001_first.up.sql": file("SELECT 1;"), "0001_other.down.sql": file("SELECT 1;")},
+	}
+
+	for name, files := range cases {
+		_, err := LoadMigrations(files)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := Migrations()
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+	for i, migration := range migrations {
+		assert.Equal(t, int64(i+1), migration.Version, "versions should follow each other")
+		assert.NotEmpty(t, migration.Down, "migration %d_%s should have a down script", migration.Version, migration.Name)
+	}
+}
+
+func TestPendingMigrations(t *testing.T) {
+	migrations := []Migration{{Version: 1}, {Version: 2}, {Version: 3}}
+
+	pending := pendingMigrations(migrations, map[int64]bool{1: true, 3: true})
+
+	if assert.Len(t, pending, 1) {
+		assert.Equal(t, int64(2), pending[0].Version)
+	}
+	assert.Len(t, pendingMigrations(migrations, map[int64]bool{}), 3)
+}
+
+func TestRollbackMigrations(t *testing.T) {
+	migrations := []Migration{{Version: 1}, {Version: 2}, {Version: 3}}
+	applied := map[int64]bool{1: true, 2: true, 3: true}
+
+	rollback, err := rollbackMigrations(migrations, applied, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, rollback, 2) {
+		assert.Equal(t, int64(3), rollback[0].Version)
+		assert.Equal(t, int64(2), rollback[1].Version)
+	}
+
+	rollback, err = rollbackMigrations(migrations, applied, 10)
+	assert.NoError(t, err)
+	assert.Len(t, rollback, 3)
+
+	_, err = rollbackMigrations(migrations, map[int64]bool{1: true, 4: true}, 1)
+	assert.True(t, errors.Is(err, ErrUnknownMigration))
+}
+
+func TestMigrationsCollapseDuplicateUserBadges(t *testing.T) {
+	conn := dbtest.Open(t)
+
+	migrations, err := Migrations()
+	require.NoError(t, err)
+
+	_, err = (&Migrator{DB: conn, Migrations: migrations[:1]}).Up()
+	require.NoError(t, err)
+
+	// The badge tables as the platform dump created them, with an award
+	// stored twice by a retried request
+	require.NoError(t, conn.Exec(`
+		CREATE TABLE "skill_badge" (
+		    "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+		    "skill_id" INT REFERENCES "skill" ("id"),
+		    "name" VARCHAR(255),
+		    "min_score" INT,
+		    "max_score" INT,
+		    "created_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP),
+		    "updated_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP)
+		);
+
+		CREATE TABLE "user_badge" (
+		    "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+		    "user_id" UUID REFERENCES "user" ("id"),
+		    "badge_id" INT REFERENCES "skill_badge" ("id"),
+		    "assessment_id" INT REFERENCES "assessment" ("id"),
+		    "created_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP),
+		    "updated_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP)
+		);
+
+		INSERT INTO "user" ("id", "username", "first_name", "last_name", "email", "refresh_token")
+		VALUES ('6f1c7a8e-3b1d-4c8e-9a51-0c2f5d7e9b10', 'sample', 'Sample', 'User', 'sample@example.com', '');
+		INSERT INTO "skill" ("id", "category_name") VALUES (1, 'Go');
+		INSERT INTO "assessment" ("id", "skill_id", "title") VALUES (1, 1, 'Go');
+		INSERT INTO "user_assessment" ("id", "user_id", "assessment_id", "score", "status", "submission_date")
+		VALUES (7, '6f1c7a8e-3b1d-4c8e-9a51-0c2f5d7e9b10', 1, 30, 'complete', '2023-09-20 10:00');
+		INSERT INTO "skill_badge" ("id", "skill_id", "name", "min_score", "max_score") VALUES (1, 1, 'Beginner', 0, 50);
+		INSERT INTO "user_badge" ("id", "user_id", "badge_id", "assessment_id", "created_at") VALUES
+		    (2, '6f1c7a8e-3b1d-4c8e-9a51-0c2f5d7e9b10', 1, 1, '2023-09-20 10:06'),
+		    (1, '6f1c7a8e-3b1d-4c8e-9a51-0c2f5d7e9b10', 1, 1, '2023-09-20 10:05');
+	`).Error)
+
+	_, err = (&Migrator{DB: conn, Migrations: migrations}).Up()
+	require.NoError(t, err)
+
+	var kept []struct {
+		ID               uint
+		UserAssessmentID uint
+	}
+	require.NoError(t, conn.Table("user_badge").Select("id", "user_assessment_id").Order("id").Scan(&kept).Error)
+
+	if assert.Len(t, kept, 1, "the duplicate award should be deleted") {
+		assert.Equal(t, uint(1), kept[0].ID, "the earliest award should be kept")
+		assert.Equal(t, uint(7), kept[0].UserAssessmentID)
+	}
+}
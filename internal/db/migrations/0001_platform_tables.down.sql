@@ -0,0 +1 @@
+-- The platform tables are shared with other services and never dropped.
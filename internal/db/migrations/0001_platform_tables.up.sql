@@ -0,0 +1,92 @@
+-- Tables owned by the platform that the service reads. They already exist in
+-- the shared database and are only created here for local databases.
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'STATUS') THEN
+        CREATE TYPE "STATUS" AS ENUM ('pending', 'complete', 'failed');
+    END IF;
+END
+$$;
+
+CREATE TABLE IF NOT EXISTS "user" (
+    "id" UUID PRIMARY KEY NOT NULL,
+    "username" VARCHAR(255) NOT NULL,
+    "first_name" VARCHAR(255) NOT NULL,
+    "last_name" VARCHAR(255) NOT NULL,
+    "email" VARCHAR(255) NOT NULL,
+    "section_order" TEXT,
+    "password" VARCHAR(255),
+    "provider" VARCHAR(255),
+    "profile_pic" TEXT,
+    "refresh_token" VARCHAR(255) NOT NULL,
+    "created_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP),
+    "updated_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP)
+);
+
+CREATE TABLE IF NOT EXISTS "skill" (
+    "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "category_name" VARCHAR(100) NOT NULL,
+    "description" TEXT,
+    "parent_skill_id" INT REFERENCES "skill" ("id"),
+    "created_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP),
+    "updated_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP)
+);
+
+CREATE TABLE IF NOT EXISTS "assessment" (
+    "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "skill_id" INT REFERENCES "skill" ("id"),
+    "title" VARCHAR(255) NOT NULL,
+    "description" TEXT,
+    "start_date" TIMESTAMP,
+    "end_date" TIMESTAMP,
+    "duration_minutes" INT,
+    "pass_score" NUMERIC(10, 2),
+    "status" "STATUS" DEFAULT 'pending',
+    "created_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP),
+    "updated_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP)
+);
+
+CREATE TABLE IF NOT EXISTS "user_assessment" (
+    "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "user_id" UUID REFERENCES "user" ("id"),
+    "assessment_id" INT REFERENCES "assessment" ("id"),
+    "score" NUMERIC(10, 2),
+    "time_spent" INT,
+    "status" "STATUS" DEFAULT 'pending',
+    "submission_date" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP),
+    "updated_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP)
+);
+
+CREATE TABLE IF NOT EXISTS "roles" (
+    "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "name" VARCHAR(225)
+);
+
+CREATE TABLE IF NOT EXISTS "permissions" (
+    "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "name" VARCHAR(225),
+    "created_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP)
+);
+
+CREATE TABLE IF NOT EXISTS "user_roles" (
+    "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "role_id" INT REFERENCES "roles" ("id"),
+    "user_id" UUID REFERENCES "user" ("id"),
+    "created_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP)
+);
+
+CREATE TABLE IF NOT EXISTS "user_permissions" (
+    "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "user_id" UUID REFERENCES "user" ("id"),
+    "permission_id" INT REFERENCES "permissions" ("id"),
+    "created_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP)
+);
+
+CREATE TABLE IF NOT EXISTS "roles_permissions" (
+    "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY NOT NULL,
+    "role_id" INT REFERENCES "roles" ("id"),
+    "permission_id" INT REFERENCES "permissions" ("id"),
+    "created_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP)
+);
@@ -0,0 +1,13 @@
+-- Only what 0002 added is reverted, user_badge and skill_badge may predate it
+-- and are kept with their rows.
+DROP INDEX IF EXISTS "idx_user_badge_badge";
+DROP INDEX IF EXISTS "idx_user_badge_assessment";
+
+ALTER TABLE "user_badge" DROP COLUMN IF EXISTS "revocation_reason";
+ALTER TABLE "user_badge" DROP COLUMN IF EXISTS "revoked_by";
+ALTER TABLE "user_badge" DROP COLUMN IF EXISTS "revoked_at";
+ALTER TABLE "user_badge" DROP COLUMN IF EXISTS "superseded_by_id";
+ALTER TABLE "user_badge" DROP COLUMN IF EXISTS "superseded_at";
+
+ALTER TABLE "skill_badge" DROP COLUMN IF EXISTS "tier_id";
+DROP TABLE IF EXISTS "badge_tier";
@@ -0,0 +1,137 @@
+-- Badge tiers, the badges of each skill and the badges awarded to users.
+-- Databases created from the platform dump or by AutoMigrate already have
+-- some of these tables, they are brought to the same shape.
+
+CREATE TABLE IF NOT EXISTS "badge_tier" (
+    "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "skill_id" INT REFERENCES "skill" ("id"),
+    "name" VARCHAR(255) NOT NULL,
+    "display_name" VARCHAR(255),
+    "description" TEXT,
+    "rank" INT NOT NULL,
+    "created_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP),
+    "updated_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP)
+);
+
+CREATE TABLE IF NOT EXISTS "skill_badge" (
+    "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "skill_id" INT REFERENCES "skill" ("id"),
+    "tier_id" INT REFERENCES "badge_tier" ("id"),
+    "name" VARCHAR(255),
+    "min_score" DOUBLE PRECISION,
+    "max_score" DOUBLE PRECISION,
+    "created_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP),
+    "updated_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP)
+);
+
+ALTER TABLE "skill_badge" ADD COLUMN IF NOT EXISTS "tier_id" INT REFERENCES "badge_tier" ("id");
+ALTER TABLE "skill_badge" ALTER COLUMN "min_score" TYPE DOUBLE PRECISION;
+ALTER TABLE "skill_badge" ALTER COLUMN "max_score" TYPE DOUBLE PRECISION;
+
+CREATE TABLE IF NOT EXISTS "user_badge" (
+    "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "user_id" UUID REFERENCES "user" ("id"),
+    "badge_id" INT REFERENCES "skill_badge" ("id"),
+    "user_assessment_id" INT REFERENCES "user_assessment" ("id"),
+    "superseded_at" TIMESTAMP,
+    "superseded_by_id" INT REFERENCES "user_badge" ("id"),
+    "revoked_at" TIMESTAMP,
+    "revoked_by" VARCHAR(255),
+    "revocation_reason" TEXT,
+    "created_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP),
+    "updated_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP)
+);
+
+-- The platform dump names the assessment of a user badge assessment_id and
+-- points it at the assessment, while it is the assessment taken by the user.
+-- Existing rows are pointed at the assessment the user took, the latest
+-- submission made by the time the badge was created when it was taken several
+-- times. Rows whose user never took the assessment cannot be pointed at
+-- anything, and stop the migration until they are fixed or deleted.
+DO $$
+DECLARE
+    unmatched TEXT;
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = current_schema() AND table_name = 'user_badge' AND column_name = 'assessment_id')
+       AND NOT EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = current_schema() AND table_name = 'user_badge' AND column_name = 'user_assessment_id') THEN
+        SELECT string_agg("user_badge"."id"::TEXT, ', ' ORDER BY "user_badge"."id") INTO unmatched
+        FROM "user_badge"
+        WHERE NOT EXISTS (SELECT 1 FROM "user_assessment"
+                          WHERE "user_assessment"."assessment_id" = "user_badge"."assessment_id"
+                            AND "user_assessment"."user_id" = "user_badge"."user_id");
+
+        IF unmatched IS NOT NULL THEN
+            RAISE EXCEPTION 'user_badge rows % have no user_assessment of their user for their assessment_id, fix or delete them before migrating', unmatched;
+        END IF;
+
+        ALTER TABLE "user_badge" DROP CONSTRAINT IF EXISTS "user_badge_assessment_id_fkey";
+        UPDATE "user_badge" SET "assessment_id" = (
+            SELECT "user_assessment"."id" FROM "user_assessment"
+            WHERE "user_assessment"."assessment_id" = "user_badge"."assessment_id"
+              AND "user_assessment"."user_id" = "user_badge"."user_id"
+            ORDER BY ("user_assessment"."submission_date" <= "user_badge"."created_at") DESC NULLS LAST,
+                     "user_assessment"."submission_date" DESC NULLS LAST,
+                     "user_assessment"."id" DESC
+            LIMIT 1
+        );
+        ALTER TABLE "user_badge" RENAME COLUMN "assessment_id" TO "user_assessment_id";
+        ALTER TABLE "user_badge" ADD FOREIGN KEY ("user_assessment_id") REFERENCES "user_assessment" ("id");
+    END IF;
+END
+$$;
+
+ALTER TABLE "user_badge" ADD COLUMN IF NOT EXISTS "superseded_at" TIMESTAMP;
+ALTER TABLE "user_badge" ADD COLUMN IF NOT EXISTS "superseded_by_id" INT REFERENCES "user_badge" ("id");
+ALTER TABLE "user_badge" ADD COLUMN IF NOT EXISTS "revoked_at" TIMESTAMP;
+ALTER TABLE "user_badge" ADD COLUMN IF NOT EXISTS "revoked_by" VARCHAR(255);
+ALTER TABLE "user_badge" ADD COLUMN IF NOT EXISTS "revocation_reason" TEXT;
+
+-- Retried requests awarded some badges twice before the unique indexes below
+-- existed. The earliest award is kept and its duplicates deleted, the badges
+-- they superseded pointing at the kept award instead. Awards of the same
+-- assessment are collapsed first, then awards of the same badge.
+UPDATE "user_badge" SET "superseded_by_id" = NULLIF("duplicates"."kept_id", "user_badge"."id")
+FROM (
+    SELECT "id", FIRST_VALUE("id") OVER (PARTITION BY "user_id", "user_assessment_id" ORDER BY "created_at" NULLS LAST, "id") AS "kept_id"
+    FROM "user_badge" WHERE "user_id" IS NOT NULL AND "user_assessment_id" IS NOT NULL
+) AS "duplicates"
+WHERE "user_badge"."superseded_by_id" = "duplicates"."id" AND "duplicates"."id" <> "duplicates"."kept_id";
+
+DELETE FROM "user_badge" USING (
+    SELECT "id", FIRST_VALUE("id") OVER (PARTITION BY "user_id", "user_assessment_id" ORDER BY "created_at" NULLS LAST, "id") AS "kept_id"
+    FROM "user_badge" WHERE "user_id" IS NOT NULL AND "user_assessment_id" IS NOT NULL
+) AS "duplicates"
+WHERE "user_badge"."id" = "duplicates"."id" AND "duplicates"."id" <> "duplicates"."kept_id";
+
+UPDATE "user_badge" SET "superseded_by_id" = NULLIF("duplicates"."kept_id", "user_badge"."id")
+FROM (
+    SELECT "id", FIRST_VALUE("id") OVER (PARTITION BY "user_id", "badge_id" ORDER BY "created_at" NULLS LAST, "id") AS "kept_id"
+    FROM "user_badge" WHERE "user_id" IS NOT NULL AND "badge_id" IS NOT NULL
+) AS "duplicates"
+WHERE "user_badge"."superseded_by_id" = "duplicates"."id" AND "duplicates"."id" <> "duplicates"."kept_id";
+
+DELETE FROM "user_badge" USING (
+    SELECT "id", FIRST_VALUE("id") OVER (PARTITION BY "user_id", "badge_id" ORDER BY "created_at" NULLS LAST, "id") AS "kept_id"
+    FROM "user_badge" WHERE "user_id" IS NOT NULL AND "badge_id" IS NOT NULL
+) AS "duplicates"
+WHERE "user_badge"."id" = "duplicates"."id" AND "duplicates"."id" <> "duplicates"."kept_id";
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_badge_assessment" ON "user_badge" ("user_id", "user_assessment_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_badge_badge" ON "user_badge" ("user_id", "badge_id");
+
+-- Default ladder, used by the skills without tiers of their own.
+INSERT INTO "badge_tier" ("name", "display_name", "description", "rank")
+SELECT "name", "name", "description", "rank" FROM (VALUES
+    ('Beginner', 'Has a working knowledge of the skill', 1),
+    ('Intermediate', 'Applies the skill independently', 2),
+    ('Expert', 'Has mastered the skill', 3)
+) AS defaults ("name", "description", "rank")
+WHERE NOT EXISTS (SELECT 1 FROM "badge_tier" WHERE "skill_id" IS NULL);
+
+-- Link the badges created before tiers existed to the default tier with the
+-- same name.
+UPDATE "skill_badge" SET "tier_id" = "badge_tier"."id" FROM "badge_tier"
+WHERE "badge_tier"."skill_id" IS NULL AND LOWER("badge_tier"."name") = LOWER("skill_badge"."name")
+  AND ("skill_badge"."tier_id" IS NULL OR "skill_badge"."tier_id" = 0);
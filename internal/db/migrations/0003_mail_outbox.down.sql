@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS "idx_mail_log_due";
+
+ALTER TABLE "mail_log" DROP COLUMN IF EXISTS "delivered_at";
+ALTER TABLE "mail_log" DROP COLUMN IF EXISTS "last_error";
+ALTER TABLE "mail_log" DROP COLUMN IF EXISTS "next_attempt_at";
+ALTER TABLE "mail_log" DROP COLUMN IF EXISTS "attempts";
+ALTER TABLE "mail_log" DROP COLUMN IF EXISTS "user_badge_id";
+ALTER TABLE "mail_log" DROP COLUMN IF EXISTS "channel";
@@ -0,0 +1,22 @@
+-- mail_log is the outbox of the notifications. Its delivery columns are
+-- added to the platform table when it already exists.
+
+CREATE TABLE IF NOT EXISTS "mail_log" (
+    "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY NOT NULL,
+    "email" VARCHAR(225),
+    "message_data" JSON,
+    "message_type" INT,
+    "status" VARCHAR(20) DEFAULT 'pending',
+    "request_origin" VARCHAR(225),
+    "created_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP),
+    "updated_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP)
+);
+
+ALTER TABLE "mail_log" ADD COLUMN IF NOT EXISTS "channel" VARCHAR(32);
+ALTER TABLE "mail_log" ADD COLUMN IF NOT EXISTS "user_badge_id" INT;
+ALTER TABLE "mail_log" ADD COLUMN IF NOT EXISTS "attempts" INT NOT NULL DEFAULT 0;
+ALTER TABLE "mail_log" ADD COLUMN IF NOT EXISTS "next_attempt_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP);
+ALTER TABLE "mail_log" ADD COLUMN IF NOT EXISTS "last_error" TEXT;
+ALTER TABLE "mail_log" ADD COLUMN IF NOT EXISTS "delivered_at" TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS "idx_mail_log_due" ON "mail_log" ("status", "next_attempt_at");
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS "idempotency_key" (
+    "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "key" VARCHAR(255),
+    "user_id" VARCHAR(255),
+    "method" VARCHAR(10),
+    "path" TEXT,
+    "request_hash" VARCHAR(64),
+    "status_code" INT NOT NULL DEFAULT 0,
+    "response_body" TEXT,
+    "created_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP),
+    "updated_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_idempotency_key_user" ON "idempotency_key" ("key", "user_id");
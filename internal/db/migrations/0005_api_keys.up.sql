@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS "api_key" (
+    "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY NOT NULL,
+    "name" VARCHAR(225),
+    "prefix" VARCHAR(32) UNIQUE,
+    "hash" VARCHAR(64),
+    "scopes" JSON,
+    "created_by" VARCHAR(225),
+    "expires_at" TIMESTAMP,
+    "last_used_at" TIMESTAMP,
+    "revoked_at" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP),
+    "updated_at" TIMESTAMP DEFAULT (CURRENT_TIMESTAMP)
+);
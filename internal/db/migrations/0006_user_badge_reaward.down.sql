@@ -0,0 +1,3 @@
+-- Fails while a revoked badge has been awarded again.
+DROP INDEX IF EXISTS "idx_user_badge_badge";
+CREATE UNIQUE INDEX "idx_user_badge_badge" ON "user_badge" ("user_id", "badge_id");
@@ -0,0 +1,4 @@
+-- A revoked badge can be earned again, so only the badges a user holds are
+-- unique.
+DROP INDEX IF EXISTS "idx_user_badge_badge";
+CREATE UNIQUE INDEX "idx_user_badge_badge" ON "user_badge" ("user_id", "badge_id") WHERE "revoked_at" IS NULL;
@@ -0,0 +1,380 @@
+package db
+
+import (
+	"demerzel-badges/internal/models"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Models are the structs mapped to the tables of the database, the schema
+// check compares them with the live columns and indexes.
+var Models = []interface{}{
+	&models.User{},
+	&models.Role{},
+	&models.UserRole{},
+	&models.Permission{},
+	&models.UserPermission{},
+	&models.RolePermission{},
+	&models.Skill{},
+	&models.Assessment{},
+	&models.UserAssessment{},
+	&models.BadgeTier{},
+	&models.SkillBadge{},
+	&models.UserBadge{},
+	&models.IdempotencyKey{},
+	&models.MailLog{},
+	&models.APIKey{},
+}
+
+// Modes of the startup schema check, DB_SCHEMA_CHECK.
+const (
+	SchemaCheckWarn   = "warn"
+	SchemaCheckStrict = "strict"
+	SchemaCheckOff    = "off"
+)
+
+const (
+	MissingTable  = "missing_table"
+	MissingColumn = "missing_column"
+	TypeMismatch  = "type_mismatch"
+	MissingIndex  = "missing_index"
+)
+
+var ErrSchemaDrift = errors.New("database schema does not match the models")
+
+// SchemaIssue is a difference between a model and its table.
+type SchemaIssue struct {
+	Table  string
+	Column string
+	Kind   string
+	Detail string
+}
+
+func (i SchemaIssue) String() string {
+	if i.Column == "" {
+		return fmt.Sprintf("%s: %s %s", i.Table, i.Kind, i.Detail)
+	}
+
+	return fmt.Sprintf("%s.%s: %s %s", i.Table, i.Column, i.Kind, i.Detail)
+}
+
+type tableSpec struct {
+	Name    string
+	Columns []columnSpec
+	Indexes []indexSpec
+}
+
+// columnSpec is a column with the family of its type, see typeFamily.
+type columnSpec struct {
+	Name string
+	Type string
+}
+
+type indexSpec struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+// liveTable is a table as described by the database, Columns maps the column
+// names to their type.
+type liveTable struct {
+	Columns map[string]string
+	Indexes []indexSpec
+}
+
+// CheckSchema compares the Models with the tables of the current schema and
+// returns the missing tables, columns and indexes, and the columns whose
+// type cannot hold their field.
+func CheckSchema(db *gorm.DB) ([]SchemaIssue, error) {
+	expected, err := expectedSchema(Models, db.NamingStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(expected))
+	for _, table := range expected {
+		names = append(names, table.Name)
+	}
+
+	live, err := liveSchema(db, names)
+	if err != nil {
+		return nil, err
+	}
+
+	return compareSchema(expected, live), nil
+}
+
+// expectedSchema describes the tables of the models.
+func expectedSchema(dests []interface{}, namer schema.Namer) ([]tableSpec, error) {
+	cache := &sync.Map{}
+
+	var tables []tableSpec
+	for _, dest := range dests {
+		sch, err := schema.Parse(dest, cache, namer)
+		if err != nil {
+			return nil, err
+		}
+
+		table := tableSpec{Name: sch.Table}
+		for _, name := range sch.DBNames {
+			table.Columns = append(table.Columns, columnSpec{
+				Name: name,
+				Type: fieldFamily(sch.FieldsByDBName[name]),
+			})
+		}
+
+		indexes := sch.ParseIndexes()
+		indexNames := make([]string, 0, len(indexes))
+		for name := range indexes {
+			indexNames = append(indexNames, name)
+		}
+		sort.Strings(indexNames)
+
+		for _, name := range indexNames {
+			index := indexes[name]
+			spec := indexSpec{Name: name, Unique: index.Class == "UNIQUE"}
+			for _, option := range index.Fields {
+				spec.Columns = append(spec.Columns, option.DBName)
+			}
+
+			table.Indexes = append(table.Indexes, spec)
+		}
+
+		tables = append(tables, table)
+	}
+
+	return tables, nil
+}
+
+// liveSchema reads the columns and indexes of tables in the current schema.
+func liveSchema(db *gorm.DB, tables []string) (map[string]*liveTable, error) {
+	var columns []struct {
+		TableName  string
+		ColumnName string
+		DataType   string
+	}
+
+	err := db.Raw(`SELECT table_name, column_name, data_type
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name IN ?`, tables).
+		Scan(&columns).Error
+	if err != nil {
+		return nil, err
+	}
+
+	live := map[string]*liveTable{}
+	for _, column := range columns {
+		table, ok := live[column.TableName]
+		if !ok {
+			table = &liveTable{Columns: map[string]string{}}
+			live[column.TableName] = table
+		}
+
+		table.Columns[column.ColumnName] = column.DataType
+	}
+
+	var indexes []struct {
+		TableName string
+		IndexName string
+		IsUnique  bool
+		Columns   string
+	}
+
+	err = db.Raw(`SELECT t.relname AS table_name, i.relname AS index_name, ix.indisunique AS is_unique,
+			array_to_string(ARRAY(
+				SELECT a.attname FROM unnest(ix.indkey) WITH ORDINALITY AS k(attnum, position)
+				JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+				ORDER BY k.position
+			), ',') AS columns
+		FROM pg_index ix
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = current_schema() AND t.relname IN ?`, tables).
+		Scan(&indexes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, index := range indexes {
+		table, ok := live[index.TableName]
+		if !ok {
+			continue
+		}
+
+		table.Indexes = append(table.Indexes, indexSpec{
+			Name:    index.IndexName,
+			Columns: strings.Split(index.Columns, ","),
+			Unique:  index.IsUnique,
+		})
+	}
+
+	return live, nil
+}
+
+// compareSchema returns the differences between the expected tables and the
+// live ones. Indexes are matched on their columns rather than their names, so
+// that a UNIQUE constraint stands for a unique index.
+func compareSchema(expected []tableSpec, live map[string]*liveTable) []SchemaIssue {
+	var issues []SchemaIssue
+
+	for _, table := range expected {
+		actual, ok := live[table.Name]
+		if !ok {
+			issues = append(issues, SchemaIssue{Table: table.Name, Kind: MissingTable})
+			continue
+		}
+
+		for _, column := range table.Columns {
+			dataType, ok := actual.Columns[column.Name]
+			if !ok {
+				issues = append(issues, SchemaIssue{Table: table.Name, Column: column.Name, Kind: MissingColumn})
+				continue
+			}
+
+			if !compatibleTypes(column.Type, typeFamily(dataType)) {
+				issues = append(issues, SchemaIssue{
+					Table:  table.Name,
+					Column: column.Name,
+					Kind:   TypeMismatch,
+					Detail: fmt.Sprintf("(model expects %s, column is %s)", column.Type, dataType),
+				})
+			}
+		}
+
+		for _, index := range table.Indexes {
+			if !hasIndex(actual.Indexes, index) {
+				kind := "index"
+				if index.Unique {
+					kind = "unique index"
+				}
+
+				issues = append(issues, SchemaIssue{
+					Table:  table.Name,
+					Kind:   MissingIndex,
+					Detail: fmt.Sprintf("%s (%s %s)", index.Name, kind, strings.Join(index.Columns, ", ")),
+				})
+			}
+		}
+	}
+
+	return issues
+}
+
+func hasIndex(indexes []indexSpec, want indexSpec) bool {
+	for _, index := range indexes {
+		if want.Unique && !index.Unique {
+			continue
+		}
+
+		if strings.Join(index.Columns, ",") == strings.Join(want.Columns, ",") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fieldFamily returns the family of the column type a field needs.
+func fieldFamily(field *schema.Field) string {
+	switch field.DataType {
+	case schema.Bool:
+		return "bool"
+	case schema.Int, schema.Uint:
+		return "int"
+	case schema.Float:
+		return "float"
+	case schema.String:
+		return "text"
+	case schema.Time:
+		return "time"
+	case schema.Bytes:
+		return "bytes"
+	}
+
+	return typeFamily(string(field.DataType))
+}
+
+// typeFamily groups the SQL types that scan into the same Go types.
+func typeFamily(sqlType string) string {
+	sqlType = strings.ToLower(strings.TrimSpace(sqlType))
+
+	switch {
+	case sqlType == "text", sqlType == "uuid", sqlType == "user-defined", strings.Contains(sqlType, "char"):
+		return "text"
+	case strings.HasPrefix(sqlType, "timestamp"), strings.HasPrefix(sqlType, "date"), strings.HasPrefix(sqlType, "time"):
+		return "time"
+	case strings.HasPrefix(sqlType, "bool"):
+		return "bool"
+	case sqlType == "json", sqlType == "jsonb":
+		return "json"
+	case sqlType == "bytea":
+		return "bytes"
+	case strings.HasPrefix(sqlType, "numeric"), strings.HasPrefix(sqlType, "decimal"):
+		return "numeric"
+	case sqlType == "real", strings.HasPrefix(sqlType, "double"), strings.HasPrefix(sqlType, "float"):
+		return "float"
+	case strings.Contains(sqlType, "int"), strings.Contains(sqlType, "serial"):
+		return "int"
+	}
+
+	return sqlType
+}
+
+// compatibleTypes reports whether a column of the live family can hold a field
+// of the expected one. Numeric columns only fit float fields, an integer field
+// would fail on the first fractional value.
+func compatibleTypes(expected, live string) bool {
+	if expected == live {
+		return true
+	}
+
+	switch expected {
+	case "float":
+		return live == "numeric" || live == "int"
+	case "numeric":
+		return live == "float" || live == "int"
+	case "json":
+		return live == "text"
+	case "bytes":
+		return live == "json" || live == "text"
+	}
+
+	return false
+}
+
+// VerifySchema checks the schema at startup in the given mode: warn logs the
+// drift, strict also refuses to start on it and off skips the check.
+func VerifySchema(mode string) error {
+	if mode == SchemaCheckOff {
+		return nil
+	}
+
+	issues, err := CheckSchema(DB)
+	if err != nil {
+		if mode == SchemaCheckStrict {
+			return err
+		}
+
+		log.Printf("Failed to check the schema: %v", err)
+		return nil
+	}
+
+	for _, issue := range issues {
+		log.Printf("Schema drift: %s", issue)
+	}
+
+	if len(issues) > 0 && mode == SchemaCheckStrict {
+		return fmt.Errorf("%w: %d issue(s)", ErrSchemaDrift, len(issues))
+	}
+
+	return nil
+}
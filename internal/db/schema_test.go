@@ -0,0 +1,104 @@
+package db
+
+import (
+	"demerzel-badges/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm/schema"
+)
+
+func TestExpectedSchemaOfModels(t *testing.T) {
+	tables, err := expectedSchema(Models, schema.NamingStrategy{})
+
+	assert.NoError(t, err)
+	assert.Len(t, tables, len(Models))
+}
+
+func TestExpectedSchemaOfUserBadge(t *testing.T) {
+	tables, err := expectedSchema([]interface{}{&models.UserBadge{}}, schema.NamingStrategy{})
+
+	assert.NoError(t, err)
+	if !assert.Len(t, tables, 1) {
+		return
+	}
+
+	table := tables[0]
+	assert.Equal(t, "user_badge", table.Name)
+	assert.Contains(t, table.Columns, columnSpec{Name: "user_id", Type: "text"})
+	assert.Contains(t, table.Columns, columnSpec{Name: "revoked_at", Type: "time"})
+	assert.Len(t, table.Columns, 11, "relations are not columns")
+	assert.Equal(t, []indexSpec{
+		{Name: "idx_user_badge_assessment", Columns: []string{"user_id", "user_assessment_id"}, Unique: true},
+		{Name: "idx_user_badge_badge", Columns: []string{"user_id", "badge_id"}, Unique: true},
+	}, table.Indexes)
+}
+
+func TestCompareSchema(t *testing.T) {
+	expected := []tableSpec{
+		{
+			Name: "api_key",
+			Columns: []columnSpec{
+				{Name: "id", Type: "int"},
+				{Name: "prefix", Type: "text"},
+				{Name: "scopes", Type: "json"},
+				{Name: "expires_at", Type: "time"},
+			},
+			Indexes: []indexSpec{{Name: "idx_api_key_prefix", Columns: []string{"prefix"}, Unique: true}},
+		},
+		{Name: "mail_log", Columns: []columnSpec{{Name: "id", Type: "int"}}},
+	}
+
+	live := map[string]*liveTable{
+		"api_key": {
+			Columns: map[string]string{
+				"id":         "integer",
+				"prefix":     "character varying",
+				"scopes":     "text",
+				"expires_at": "numeric",
+			},
+			Indexes: []indexSpec{{Name: "api_key_prefix_key", Columns: []string{"prefix"}, Unique: true}},
+		},
+	}
+
+	issues := compareSchema(expected, live)
+
+	assert.Equal(t, []SchemaIssue{
+		{Table: "api_key", Column: "expires_at", Kind: TypeMismatch, Detail: "(model expects time, column is numeric)"},
+		{Table: "mail_log", Kind: MissingTable},
+	}, issues)
+
+	live["api_key"].Indexes = []indexSpec{{Name: "idx_api_key_prefix", Columns: []string{"prefix"}}}
+	delete(live["api_key"].Columns, "scopes")
+
+	issues = compareSchema(expected[:1], live)
+
+	assert.Equal(t, []SchemaIssue{
+		{Table: "api_key", Column: "scopes", Kind: MissingColumn},
+		{Table: "api_key", Column: "expires_at", Kind: TypeMismatch, Detail: "(model expects time, column is numeric)"},
+		{Table: "api_key", Kind: MissingIndex, Detail: "idx_api_key_prefix (unique index prefix)"},
+	}, issues, "a plain index does not stand for a unique one")
+}
+
+func TestCompatibleTypes(t *testing.T) {
+	cases := []struct {
+		expected string
+		column   string
+		ok       bool
+	}{
+		{"text", "uuid", true},
+		{"text", "USER-DEFINED", true},
+		{"int", "bigint", true},
+		{"int", "numeric", false},
+		{"float", "numeric", true},
+		{"float", "double precision", true},
+		{"time", "timestamp with time zone", true},
+		{"json", "jsonb", true},
+		{"bool", "integer", false},
+		{typeFamily("varchar(20)"), "character varying", true},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.ok, compatibleTypes(c.expected, typeFamily(c.column)), "%s in %s", c.expected, c.column)
+	}
+}
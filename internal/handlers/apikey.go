@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"demerzel-badges/internal/apikey"
+	"demerzel-badges/internal/models"
+	"demerzel-badges/internal/views"
+	"demerzel-badges/pkg/response"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func (h *Handlers) ListAPIKeysHandler(c *gin.Context) {
+	keys, err := h.APIKeys.ListAPIKeys()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to list API keys", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response.Success(c, http.StatusOK, "API Keys", map[string]interface{}{
+		"api_keys": views.NewAPIKeyViews(keys),
+	})
+}
+
+func (h *Handlers) CreateAPIKeyHandler(c *gin.Context) {
+	type CreateAPIKeyRequest struct {
+		Name      string     `json:"name"`
+		Scopes    []string   `json:"scopes"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	var input CreateAPIKeyRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Unable to parse payload: %s", err.Error()), map[string]interface{}{})
+		return
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		response.Error(c, http.StatusUnprocessableEntity, "Invalid input", map[string]interface{}{
+			"name": "name is required",
+		})
+		return
+	}
+
+	if len(input.Scopes) == 0 {
+		response.Error(c, http.StatusUnprocessableEntity, "Invalid input", map[string]interface{}{
+			"scopes": "at least one scope is required",
+		})
+		return
+	}
+
+	for _, scope := range input.Scopes {
+		if !containsString(h.Config.APIKeyScopes, scope) {
+			response.Error(c, http.StatusUnprocessableEntity, "Invalid input", map[string]interface{}{
+				"scopes": fmt.Sprintf("%s cannot be granted to an API key, scopes should be among %s", scope, strings.Join(h.Config.APIKeyScopes, ", ")),
+			})
+			return
+		}
+	}
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		response.Error(c, http.StatusUnprocessableEntity, "Invalid input", map[string]interface{}{
+			"expires_at": "expires_at should be in the future",
+		})
+		return
+	}
+
+	key, id, hash, err := apikey.Generate()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to generate API key", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	created, err := h.APIKeys.CreateAPIKey(models.APIKey{
+		Name:      input.Name,
+		Prefix:    id,
+		Hash:      hash,
+		Scopes:    input.Scopes,
+		CreatedBy: c.GetString("user_id"),
+		ExpiresAt: input.ExpiresAt,
+	})
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to create API key", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	// The key is only ever shown in this response
+	response.Success(c, http.StatusCreated, "API Key Created Successfully", map[string]interface{}{
+		"api_key": views.NewAPIKeyView(created),
+		"key":     key,
+	})
+}
+
+func (h *Handlers) RevokeAPIKeyHandler(c *gin.Context) {
+	keyID, err := strconv.ParseUint(c.Param("key_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid keyID", map[string]interface{}{})
+		return
+	}
+
+	key, err := h.APIKeys.RevokeAPIKey(uint(keyID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		response.Error(c, http.StatusNotFound, "API Key Not found", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if errors.Is(err, models.ErrAPIKeyRevoked) {
+		response.Error(c, http.StatusConflict, "API Key cannot be revoked", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to revoke API key", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	response.Success(c, http.StatusOK, "API Key Revoked Successfully", map[string]interface{}{
+		"api_key": views.NewAPIKeyView(key),
+	})
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,20 +1,34 @@
 package handlers
 
 import (
-	"demerzel-badges/internal/db"
+	"demerzel-badges/internal/middleware"
 	"demerzel-badges/internal/models"
+	"demerzel-badges/internal/notifier"
+	"demerzel-badges/internal/urls"
+	"demerzel-badges/internal/views"
 	"demerzel-badges/pkg/response"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-resty/resty/v2"
+	"gorm.io/gorm"
 )
 
-func CreateBadgeHandler(c *gin.Context) {
+// The notifications of a badge cannot be built without its recipient and
+// skill, the badge is not awarded then.
+var (
+	errUnknownRecipient = errors.New("badge recipient is not a known user")
+	errUnknownBadge     = errors.New("badge or its skill could not be found")
+)
+
+func (h *Handlers) CreateBadgeHandler(c *gin.Context) {
 	type CreateBadgeRequest struct {
 		SkillID  uint    `json:"skill_id"`
+		TierID   uint    `json:"tier_id"`
 		Name     string  `json:"name"`
 		MinScore float64 `json:"min_score"`
 		MaxScore float64 `json:"max_score"`
@@ -43,7 +57,7 @@ func CreateBadgeHandler(c *gin.Context) {
 		return
 	}
 
-	existingSkill, err := models.FindSkillById(db.DB, input.SkillID)
+	existingSkill, err := h.Skills.FindSkillById(input.SkillID)
 	if err != nil || existingSkill == nil {
 		response.Error(c, http.StatusUnprocessableEntity, "Invalid input", map[string]interface{}{
 			"skill": "no skill found matching provided ID",
@@ -52,8 +66,17 @@ func CreateBadgeHandler(c *gin.Context) {
 		return
 	}
 
-	badgeName, _ := models.GetValidBadgeName(input.Name)
-	if !badgeName.IsValid() {
+	var tier *models.BadgeTier
+	if input.TierID != 0 {
+		tier, err = h.Badges.FindTierByID(input.TierID)
+		if err == nil && !h.Badges.TierInLadder(tier, input.SkillID) {
+			tier, err = nil, fmt.Errorf("tier is not part of the skill's ladder")
+		}
+	} else {
+		tier, err = h.Badges.FindTierByName(input.SkillID, input.Name)
+	}
+
+	if err != nil || tier == nil {
 		response.Error(c, http.StatusUnprocessableEntity, "invalid input", map[string]interface{}{
 			"name": "invalid badge name",
 		})
@@ -61,7 +84,7 @@ func CreateBadgeHandler(c *gin.Context) {
 		return
 	}
 
-	badgeExists := models.BadgeExists(db.DB, input.SkillID, badgeName)
+	badgeExists := h.Badges.BadgeExists(input.SkillID, tier.ID)
 	if badgeExists {
 		response.Error(c, http.StatusBadRequest, "Badge already exists", map[string]interface{}{
 			"error": "Badge with name already exists for specified skill",
@@ -70,13 +93,16 @@ func CreateBadgeHandler(c *gin.Context) {
 		return
 	}
 
-	newBadge, err := models.CreateBadge(db.DB, models.SkillBadge{
+	proposed := models.SkillBadge{
 		SkillID:  input.SkillID,
-		Name:     badgeName,
+		TierID:   tier.ID,
+		Name:     models.Badge(tier.Name),
 		MinScore: input.MinScore,
 		MaxScore: input.MaxScore,
-	})
+		Tier:     tier,
+	}
 
+	ladder, err := h.Badges.GetSkillLadder(input.SkillID)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "Unable to create badge", map[string]interface{}{
 			"err": err.Error(),
@@ -85,12 +111,212 @@ func CreateBadgeHandler(c *gin.Context) {
 		return
 	}
 
+	if !h.validLadder(c, models.ReplaceInLadder(ladder, proposed)) {
+		return
+	}
+
+	newBadge, err := h.Badges.CreateBadge(proposed)
+
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to create badge", map[string]interface{}{
+			"err": err.Error(),
+		})
+
+		return
+	}
+
+	newBadge.Tier = tier
+
 	response.Success(c, http.StatusCreated, "Badge Created Successfully", map[string]interface{}{
-		"badge": newBadge,
+		"badge": views.NewSkillBadgeView(newBadge),
+	})
+}
+
+func (h *Handlers) ListBadgesHandler(c *gin.Context) {
+	var skillID uint64
+	if skillIDQuery := c.Query("skill_id"); skillIDQuery != "" {
+		var err error
+		skillID, err = strconv.ParseUint(skillIDQuery, 10, 64)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid skill_id", map[string]interface{}{})
+			return
+		}
+	}
+
+	badges, err := h.Badges.ListBadges(uint(skillID))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to list badges", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Badges", map[string]interface{}{
+		"badges": views.NewSkillBadgeViews(badges),
+	})
+}
+
+func (h *Handlers) GetBadgeHandler(c *gin.Context) {
+	badgeID, err := strconv.ParseUint(c.Param("badge_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid badgeID", map[string]interface{}{})
+		return
+	}
+
+	badge, err := h.Badges.FindBadgeByID(uint(badgeID))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "Badge Not found", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Badge", map[string]interface{}{
+		"badge": views.NewSkillBadgeView(badge),
+	})
+}
+
+func (h *Handlers) UpdateBadgeHandler(c *gin.Context) {
+	type UpdateBadgeRequest struct {
+		MinScore *float64 `json:"min_score"`
+		MaxScore *float64 `json:"max_score"`
+	}
+	var input UpdateBadgeRequest
+
+	badgeID, err := strconv.ParseUint(c.Param("badge_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid badgeID", map[string]interface{}{})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Unable to parse payload: %s", err.Error()), map[string]interface{}{})
+		return
+	}
+
+	badge, err := h.Badges.FindBadgeByID(uint(badgeID))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "Badge Not found", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	minScore, maxScore := badge.MinScore, badge.MaxScore
+	if input.MinScore != nil {
+		minScore = *input.MinScore
+	}
+	if input.MaxScore != nil {
+		maxScore = *input.MaxScore
+	}
+
+	if minScore < 0 {
+		response.Error(c, http.StatusUnprocessableEntity, "Invalid input", map[string]interface{}{
+			"min_score": "min_score should be at least 0",
+		})
+
+		return
+	}
+
+	if minScore >= maxScore {
+		response.Error(c, http.StatusUnprocessableEntity, "Invalid input", map[string]interface{}{
+			"max_score": "max_score should be greater than min score",
+		})
+
+		return
+	}
+
+	ladder, err := h.Badges.GetSkillLadder(badge.SkillID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to update badge", map[string]interface{}{
+			"err": err.Error(),
+		})
+
+		return
+	}
+
+	proposed := *badge
+	proposed.MinScore, proposed.MaxScore = minScore, maxScore
+	if !h.validLadder(c, models.ReplaceInLadder(ladder, proposed)) {
+		return
+	}
+
+	if err := h.Badges.UpdateBadgeScores(badge, minScore, maxScore); err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to update badge", map[string]interface{}{
+			"err": err.Error(),
+		})
+
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Badge Updated Successfully", map[string]interface{}{
+		"badge": views.NewSkillBadgeView(badge),
 	})
 }
 
-func GetBadgesForUserHandler(c *gin.Context) {
+func (h *Handlers) DeleteBadgeHandler(c *gin.Context) {
+	badgeID, err := strconv.ParseUint(c.Param("badge_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid badgeID", map[string]interface{}{})
+		return
+	}
+
+	if _, err := h.Badges.FindBadgeByID(uint(badgeID)); err != nil {
+		response.Error(c, http.StatusNotFound, "Badge Not found", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	err = h.Badges.DeleteBadge(uint(badgeID))
+	if errors.Is(err, models.ErrBadgeInUse) {
+		response.Error(c, http.StatusConflict, "Badge cannot be deleted", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to delete badge", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Badge Deleted Successfully", nil)
+}
+
+// assessmentErrorStatus returns the status answering the refusal to award a
+// badge for an assessment.
+func assessmentErrorStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, models.ErrAssessmentNotOwned):
+		return http.StatusForbidden, true
+	case errors.Is(err, models.ErrAssessmentUnderReview):
+		return http.StatusConflict, true
+	case errors.Is(err, models.ErrAssessmentNotFound),
+		errors.Is(err, models.ErrAssessmentFailed),
+		errors.Is(err, models.ErrAssessmentCancelled),
+		errors.Is(err, models.ErrAssessmentOutsideWindow):
+		return http.StatusUnprocessableEntity, true
+	}
+
+	return 0, false
+}
+
+// includes reports whether the comma separated include query parameter
+// contains the given value.
+func includes(c *gin.Context, value string) bool {
+	for _, include := range strings.Split(c.Query("include"), ",") {
+		if strings.TrimSpace(include) == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *Handlers) GetBadgesForUserHandler(c *gin.Context) {
 
 	badgeName := c.Query("badge")
 	if badgeName == "" {
@@ -99,7 +325,11 @@ func GetBadgesForUserHandler(c *gin.Context) {
 
 	userID := c.GetString("user_id")
 
-	badges, err := models.GetUserBadges(db.DB, userID, badgeName)
+	badges, err := h.UserBadges.GetUserBadges(userID, models.UserBadgeFilter{
+		BadgeName:      badgeName,
+		IncludeHistory: includes(c, "history"),
+		IncludeRevoked: includes(c, "revoked"),
+	})
 
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "Unable to list badges", map[string]string{
@@ -109,11 +339,11 @@ func GetBadgesForUserHandler(c *gin.Context) {
 	}
 
 	response.Success(c, http.StatusOK, "User Badges", map[string]interface{}{
-		"badges": badges,
+		"badges": views.NewUserBadgeViews(badges),
 	})
 }
 
-func GetUserBadgeByIDHandler(c *gin.Context) {
+func (h *Handlers) GetUserBadgeByIDHandler(c *gin.Context) {
 	badgeIDQuery := c.Param("badge_id")
 	badgeID, err := strconv.ParseInt(badgeIDQuery, 10, 64)
 	if err != nil {
@@ -122,7 +352,7 @@ func GetUserBadgeByIDHandler(c *gin.Context) {
 	}
 
 	userId := c.GetString("user_id")
-	badge, err := models.GetUserBadgeByID(db.DB, uint(badgeID), userId)
+	badge, err := h.UserBadges.GetUserBadgeByID(uint(badgeID), userId, includes(c, "revoked"))
 	if err != nil {
 		response.Error(c, http.StatusNotFound, "Badge Not found", map[string]interface{}{
 			"error": err.Error(),
@@ -131,25 +361,18 @@ func GetUserBadgeByIDHandler(c *gin.Context) {
 	}
 
 	response.Success(c, http.StatusOK, "User Badge", map[string]interface{}{
-		"badge": badge,
+		"badge": views.NewUserBadgeView(badge),
+		"links": h.userBadgeLinks(badge),
 	})
 }
 
-func AssignBadgeHandler(c *gin.Context) {
+func (h *Handlers) AssignBadgeHandler(c *gin.Context) {
 
 	type AssignBadgeReq struct {
 		UserID       string `json:"user_id"`
 		AssessmentID uint   `json:"assessment_id"`
 	}
 
-	type SendNewBadgeEmail struct {
-		Recipient       string `json:"recipient"`
-		Name            string `json:"name"`
-		Skill           string `json:"skill"`
-		BadgeName       string `json:"badge_name"`
-		UserProfileLink string `json:"user_profile_link"`
-	}
-
 	var body AssignBadgeReq
 
 	if err := c.ShouldBindJSON(&body); err != nil {
@@ -157,18 +380,57 @@ func AssignBadgeHandler(c *gin.Context) {
 		return
 	}
 
-	isValidAssessment := models.VerifyAssessment(db.DB, body.AssessmentID)
+	// Assigning to someone else needs the permission for any user. Services
+	// calling with an API key always assign to the user of the body.
+	userID := c.GetString("user_id")
+	if middleware.IsMachine(c) && body.UserID == "" {
+		response.Error(c, http.StatusUnprocessableEntity, "Invalid input", map[string]interface{}{
+			"user_id": "user_id is required when assigning with an API key",
+		})
+		return
+	}
+
+	if body.UserID != "" && body.UserID != userID {
+		if middleware.Scope(c) != middleware.ScopeAny {
+			response.Error(c, http.StatusForbidden, "You are not Authorized to access this resource", map[string]interface{}{
+				"error": "You can only assign badges to yourself",
+			})
+			return
+		}
+
+		userID = body.UserID
+	}
+
+	// Assessments that cannot earn a badge are refused before assigning,
+	// AssignBadge checks them again while holding a lock on them.
+	err := h.checkAssessment(body.AssessmentID, userID)
+
+	var userBadge *models.UserBadge
+	var outcome models.AssignOutcome
+	if err == nil {
+		// Notifications are queued together with the badge, and sent by the
+		// outbox dispatcher.
+		locale := requestLocale(c)
+		userBadge, outcome, err = h.UserBadges.AssignBadge(userID, body.AssessmentID, func(userBadge *models.UserBadge, outcome models.AssignOutcome) ([]models.MailLog, error) {
+			return badgeNotifications(userBadge, outcome, h.Config.URLs, locale, h.Config.Channels)
+		})
+	}
 
-	if !isValidAssessment {
-		response.Error(c, http.StatusBadRequest, "Invalid Assessment", map[string]interface{}{
-			"error": "This assessment is not valid or is under review",
+	if status, ok := assessmentErrorStatus(err); ok {
+		response.Error(c, status, "Invalid Assessment", map[string]interface{}{
+			"error": err.Error(),
 		})
+
 		return
 	}
 
-	userID := c.GetString("user_id")
+	if errors.Is(err, models.ErrNoBadgeForScore) || errors.Is(err, errUnknownRecipient) || errors.Is(err, errUnknownBadge) {
+		response.Error(c, http.StatusUnprocessableEntity, "Unable to assign badge", map[string]interface{}{
+			"error": err.Error(),
+		})
 
-	userBadge, err := models.AssignBadge(db.DB, userID, body.AssessmentID)
+		return
+	}
 
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "Unable to assign badge", map[string]interface{}{
@@ -178,32 +440,109 @@ func AssignBadgeHandler(c *gin.Context) {
 		return
 	}
 
-	emailReq := SendNewBadgeEmail{
-		Recipient:       userBadge.User.Email,
-		Name:            userBadge.User.FirstName,
-		Skill:           userBadge.Badge.Skill.CategoryName,
-		BadgeName:       string(userBadge.Badge.Name),
-		UserProfileLink: "https://example.com",
+	switch outcome {
+	case models.AssignExisting:
+		response.Success(c, http.StatusOK, "Badge Already Assigned", map[string]interface{}{
+			"badge":   views.NewUserBadgeView(userBadge),
+			"outcome": outcome,
+			"links":   h.userBadgeLinks(userBadge),
+		})
+		return
+	case models.AssignRetained:
+		response.Success(c, http.StatusOK, "Badge Retained, a higher or equal badge is already held for this skill", map[string]interface{}{
+			"badge":   views.NewUserBadgeView(userBadge),
+			"outcome": outcome,
+			"links":   h.userBadgeLinks(userBadge),
+		})
+		return
+	}
+
+	message := "Badge Assigned Successfully"
+	if outcome == models.AssignUpgraded {
+		message = "Badge Upgraded Successfully"
+	}
+
+	response.Success(c, http.StatusCreated, message, map[string]interface{}{
+		"badge":   views.NewUserBadgeView(userBadge),
+		"outcome": outcome,
+		"links":   h.userBadgeLinks(userBadge),
+	})
+}
+
+// requestLocale is the preferred language of the Accept-Language header.
+func requestLocale(c *gin.Context) string {
+	locale, _, _ := strings.Cut(c.GetHeader("Accept-Language"), ",")
+	locale, _, _ = strings.Cut(locale, ";")
+	locale = strings.TrimSpace(locale)
+
+	if locale == "*" {
+		return ""
 	}
 
-	client := resty.New().R()
-	client.SetHeader("Content-Type", "application/json")
-	client.SetBody(&emailReq)
-	res, err := client.Post("https://team-titan.mrprotocoll.me/api/v1/messaging/assessment/badge")
+	return locale
+}
+
+// checkAssessment returns why the user cannot be awarded a badge for the
+// assessment they took, if they cannot.
+func (h *Handlers) checkAssessment(assessmentID uint, userID string) error {
+	taken, err := h.Assessments.FindUserAssessment(assessmentID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.ErrAssessmentNotFound
+	}
 
 	if err != nil {
-		response.Error(c, 500, "Something went wrong", err)
-		return
+		return err
 	}
 
-	if res.StatusCode() != 200 {
-		response.Success(c, http.StatusCreated, "Badge Assigned Successfully, Email not Sent", map[string]interface{}{
-			"badge": userBadge,
-		})
-		return
+	return models.CheckAssessment(*taken, userID)
+}
+
+// badgeNotifications returns the badge event mails, one for each
+// notification channel.
+func badgeNotifications(userBadge *models.UserBadge, outcome models.AssignOutcome, links urls.Builder, locale string, channels []string) ([]models.MailLog, error) {
+	if userBadge.User == nil {
+		return nil, errUnknownRecipient
+	}
+
+	if userBadge.Badge == nil || userBadge.Badge.Skill == nil {
+		return nil, errUnknownBadge
 	}
 
-	response.Success(c, http.StatusCreated, "Badge Assigned Successfully", map[string]interface{}{
-		"badge": userBadge,
+	eventType := notifier.EventBadgeAwarded
+	if outcome == models.AssignUpgraded {
+		eventType = notifier.EventBadgeUpgraded
+	}
+
+	messageData, err := json.Marshal(notifier.Event{
+		Type: eventType,
+		Recipient: notifier.Recipient{
+			UserID: userBadge.UserID,
+			Email:  userBadge.User.Email,
+			Name:   userBadge.User.FirstName,
+		},
+		UserBadgeID:      userBadge.ID,
+		BadgeID:          userBadge.BadgeID,
+		Skill:            userBadge.Badge.Skill.CategoryName,
+		BadgeName:        string(userBadge.Badge.Name),
+		ProfileLink:      links.Profile(userBadge.UserID, userBadge.User.Username),
+		VerificationLink: links.Verification(userBadge.ID),
+		ImageLink:        links.BadgeImage(userBadge.BadgeID),
+		Locale:           locale,
+		OccurredAt:       userBadge.CreatedAt,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	var mails []models.MailLog
+	for _, channel := range channels {
+		mails = append(mails, models.MailLog{
+			Email:       userBadge.User.Email,
+			MessageData: messageData,
+			Channel:     channel,
+			UserBadgeID: &userBadge.ID,
+		})
+	}
+
+	return mails, nil
 }
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"demerzel-badges/internal/badgeimage"
+	"demerzel-badges/pkg/response"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type imageRenderer func(badge badgeimage.Badge, layout string) ([]byte, error)
+
+func (h *Handlers) BadgeImageSVGHandler(c *gin.Context) {
+	h.renderBadgeImage(c, "image/svg+xml", badgeimage.RenderSVG)
+}
+
+func (h *Handlers) BadgeImagePNGHandler(c *gin.Context) {
+	h.renderBadgeImage(c, "image/png", badgeimage.RenderPNG)
+}
+
+func (h *Handlers) renderBadgeImage(c *gin.Context, contentType string, render imageRenderer) {
+	badgeID, err := strconv.ParseUint(c.Param("badge_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid badgeID", map[string]interface{}{})
+		return
+	}
+
+	badge, err := h.Badges.FindBadgeByID(uint(badgeID))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "Badge Not found", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	content, err := render(badgeimage.FromSkillBadge(*badge), c.Query("layout"))
+	if errors.Is(err, badgeimage.ErrUnknownLayout) {
+		response.Error(c, http.StatusBadRequest, "Invalid layout", map[string]interface{}{
+			"layout": "layout should be shield, circle or hexagon",
+		})
+		return
+	}
+
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to render badge", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	writeCacheable(c, contentType, content)
+}
+
+// writeCacheable sends content with an ETag derived from it, answering with
+// 304 Not Modified when the client already has it.
+func writeCacheable(c *gin.Context, contentType string, content []byte) {
+	sum := sha256.Sum256(content)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=3600")
+
+	for _, match := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		match = strings.TrimSpace(match)
+		if match == etag || match == "W/"+etag || match == "*" {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	c.Data(http.StatusOK, contentType, content)
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"demerzel-badges/internal/badgeimage"
+	"demerzel-badges/internal/models"
+	"demerzel-badges/internal/openbadges"
+	"demerzel-badges/pkg/response"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxBakedImageSize is the largest image accepted by the unbake endpoint.
+	maxBakedImageSize = 2 << 20
+
+	// maxUnbakeRequestSize leaves room for the multipart headers around the
+	// image.
+	maxUnbakeRequestSize = maxBakedImageSize + 64<<10
+)
+
+func (h *Handlers) OpenBadgesBakedPNGHandler(c *gin.Context) {
+	h.bakedBadgeHandler(c, "png")
+}
+
+func (h *Handlers) OpenBadgesBakedSVGHandler(c *gin.Context) {
+	h.bakedBadgeHandler(c, "svg")
+}
+
+func (h *Handlers) bakedBadgeHandler(c *gin.Context, format string) {
+	assertionID, err := strconv.ParseUint(c.Param("assertion_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid assertionID", map[string]interface{}{})
+		return
+	}
+
+	userBadge, err := h.UserBadges.FindUserBadge(uint(assertionID))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "Assertion Not found", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	builder := h.openBadgesBuilder()
+	if userBadge.IsRevoked() {
+		response.LinkedData(c, http.StatusGone, builder.RevokedAssertion(*userBadge))
+		return
+	}
+
+	assertion, err := builder.Assertion(*userBadge)
+	if err != nil {
+		response.Error(c, http.StatusUnprocessableEntity, "Unable to build assertion", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// The assertion itself is embedded by default, embed=url only embeds its
+	// URL so that backpacks fetch the hosted assertion.
+	var embedded []byte
+	switch c.DefaultQuery("embed", "assertion") {
+	case "assertion":
+		embedded, _ = json.Marshal(assertion)
+	case "url":
+	default:
+		response.Error(c, http.StatusBadRequest, "Invalid embed", map[string]interface{}{
+			"embed": "embed should be assertion or url",
+		})
+		return
+	}
+
+	var baked []byte
+	badge := badgeimage.FromSkillBadge(*userBadge.Badge)
+	contentType := "image/png"
+
+	if format == "png" {
+		content := embedded
+		if content == nil {
+			content = []byte(assertion.ID)
+		}
+
+		var image []byte
+		image, err = badgeimage.RenderPNG(badge, c.Query("layout"))
+		if err == nil {
+			baked, err = openbadges.BakePNG(image, content)
+		}
+	} else {
+		contentType = "image/svg+xml"
+
+		var image []byte
+		image, err = badgeimage.RenderSVG(badge, c.Query("layout"))
+		if err == nil {
+			baked, err = openbadges.BakeSVG(image, assertion.ID, embedded)
+		}
+	}
+
+	if errors.Is(err, badgeimage.ErrUnknownLayout) {
+		response.Error(c, http.StatusBadRequest, "Invalid layout", map[string]interface{}{
+			"layout": "layout should be shield, circle or hexagon",
+		})
+		return
+	}
+
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to bake badge", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="badge-%d.%s"`, userBadge.ID, format))
+	c.Data(http.StatusOK, contentType, baked)
+}
+
+func (h *Handlers) UnbakeBadgeHandler(c *gin.Context) {
+	type UnbakeResult struct {
+		Valid            bool                  `json:"valid"`
+		Format           string                `json:"format"`
+		AssertionID      string                `json:"assertion_id,omitempty"`
+		Assertion        *openbadges.Assertion `json:"assertion,omitempty"`
+		Revoked          bool                  `json:"revoked"`
+		RevocationReason string                `json:"revocation_reason,omitempty"`
+		Error            string                `json:"error,omitempty"`
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUnbakeRequestSize)
+
+	file, err := c.FormFile("image")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) || (err == nil && file.Size > maxBakedImageSize) {
+		response.Error(c, http.StatusRequestEntityTooLarge, "Image is too large", map[string]interface{}{
+			"image": fmt.Sprintf("image should be at most %d bytes", maxBakedImageSize),
+		})
+		return
+	}
+
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Unable to read image", map[string]interface{}{
+			"image": "image is required",
+		})
+		return
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Unable to read image", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	defer reader.Close()
+
+	image, err := io.ReadAll(io.LimitReader(reader, maxBakedImageSize))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Unable to read image", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	content, format, err := openbadges.Unbake(image)
+	if errors.Is(err, openbadges.ErrUnsupportedImage) {
+		response.Error(c, http.StatusUnsupportedMediaType, "Unsupported image", map[string]interface{}{
+			"image": err.Error(),
+		})
+		return
+	}
+
+	result := UnbakeResult{Format: format}
+	builder := h.openBadgesBuilder()
+
+	var userBadge *models.UserBadge
+	if err == nil {
+		result.AssertionID, result.Assertion, err = openbadges.ParseBaked(content)
+	}
+
+	if err == nil {
+		userBadgeID, ok := builder.AssertionIDFromURL(result.AssertionID)
+		if !ok {
+			err = errors.New("assertion was not issued by this service")
+		} else if userBadge, err = h.UserBadges.FindUserBadge(userBadgeID); err != nil {
+			err = errors.New("assertion does not match any badge")
+		}
+	}
+
+	if err == nil && userBadge.IsRevoked() {
+		result.Revoked = true
+		result.RevocationReason = userBadge.RevocationReason
+		err = errors.New("badge has been revoked")
+	}
+
+	if err == nil && result.Assertion != nil {
+		var expected openbadges.Assertion
+		expected, err = builder.Assertion(*userBadge)
+		if err == nil && !result.Assertion.Matches(expected) {
+			err = errors.New("embedded assertion does not match the hosted one")
+		}
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Valid = true
+	}
+
+	response.Success(c, http.StatusOK, "Badge Verification", map[string]interface{}{
+		"verification": result,
+	})
+}
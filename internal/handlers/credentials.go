@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"bytes"
+	"demerzel-badges/internal/credentials"
+	"demerzel-badges/internal/models"
+	"demerzel-badges/internal/urls"
+	"demerzel-badges/pkg/response"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errCredentialsDisabled = errors.New("no credential signing key is configured")
+
+func (h *Handlers) GetCredentialHandler(c *gin.Context) {
+	issuer := h.Credentials
+	if issuer == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Credential issuance is not available", map[string]interface{}{
+			"error": errCredentialsDisabled.Error(),
+		})
+		return
+	}
+
+	credentialID, err := strconv.ParseUint(c.Param("credential_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid credentialID", map[string]interface{}{})
+		return
+	}
+
+	userBadge, err := h.UserBadges.FindUserBadge(uint(credentialID))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "Credential Not found", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if userBadge.IsRevoked() {
+		response.Error(c, http.StatusGone, "Badge has been revoked", map[string]interface{}{
+			"revocation_reason": userBadge.RevocationReason,
+		})
+		return
+	}
+
+	credential, err := issuer.Credential(h.openBadgesBuilder(), *userBadge, h.Config.URLs.Credential(userBadge.ID))
+	if err != nil {
+		response.Error(c, http.StatusUnprocessableEntity, "Unable to build credential", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	switch c.DefaultQuery("format", "jwt") {
+	case "jwt":
+		token, err := issuer.SignJWT(credential)
+		if err != nil {
+			response.Error(c, http.StatusInternalServerError, "Unable to sign credential", map[string]interface{}{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.Data(http.StatusOK, "application/vc+jwt", []byte(token))
+	case "json":
+		document, err := issuer.SignDocument(credential, time.Now())
+		if err != nil {
+			response.Error(c, http.StatusInternalServerError, "Unable to sign credential", map[string]interface{}{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		response.LinkedData(c, http.StatusOK, document)
+	default:
+		response.Error(c, http.StatusBadRequest, "Invalid format", map[string]interface{}{
+			"format": "format should be jwt or json",
+		})
+	}
+}
+
+func (h *Handlers) VerifyCredentialHandler(c *gin.Context) {
+	type VerifyCredentialRequest struct {
+		Credential json.RawMessage `json:"credential"`
+	}
+
+	type VerificationResult struct {
+		Valid            bool   `json:"valid"`
+		Format           string `json:"format"`
+		Issuer           string `json:"issuer"`
+		CredentialID     string `json:"credential_id,omitempty"`
+		Revoked          bool   `json:"revoked"`
+		RevocationReason string `json:"revocation_reason,omitempty"`
+		Error            string `json:"error,omitempty"`
+	}
+
+	var input VerifyCredentialRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Unable to parse payload: %s", err.Error()), map[string]interface{}{})
+		return
+	}
+
+	issuer := h.Credentials
+	if issuer == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Credential verification is not available", map[string]interface{}{
+			"error": errCredentialsDisabled.Error(),
+		})
+		return
+	}
+
+	result := VerificationResult{Issuer: issuer.DID()}
+	var claims map[string]interface{}
+	var err error
+
+	raw := bytes.TrimSpace(input.Credential)
+	switch {
+	case bytes.HasPrefix(raw, []byte(`"`)):
+		var token string
+		if err := json.Unmarshal(raw, &token); err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid credential", map[string]interface{}{})
+			return
+		}
+
+		result.Format = "jwt"
+		claims, err = issuer.VerifyJWT(token)
+	case bytes.HasPrefix(raw, []byte(`{`)):
+		if err := json.Unmarshal(raw, &claims); err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid credential", map[string]interface{}{})
+			return
+		}
+
+		result.Format = "json"
+		err = issuer.VerifyDocument(claims)
+	default:
+		response.Error(c, http.StatusBadRequest, "Invalid credential", map[string]interface{}{
+			"credential": "credential should be a VC-JWT string or a JSON credential with an embedded proof",
+		})
+		return
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+		response.Success(c, http.StatusOK, "Credential Verification", map[string]interface{}{
+			"verification": result,
+		})
+		return
+	}
+
+	result.CredentialID = credentials.CredentialID(claims)
+	userBadgeID, ok := h.Config.URLs.ID(result.CredentialID, urls.CredentialPath)
+
+	var userBadge *models.UserBadge
+	if ok {
+		userBadge, err = h.UserBadges.FindUserBadge(userBadgeID)
+	}
+
+	switch {
+	case err != nil || userBadge == nil:
+		result.Error = "credential does not match any badge"
+	case userBadge.IsRevoked():
+		result.Revoked = true
+		result.RevocationReason = userBadge.RevocationReason
+		result.Error = "badge has been revoked"
+	default:
+		result.Valid = true
+	}
+
+	response.Success(c, http.StatusOK, "Credential Verification", map[string]interface{}{
+		"verification": result,
+	})
+}
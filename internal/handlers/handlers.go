@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"crypto/ed25519"
+	"demerzel-badges/internal/credentials"
+	"demerzel-badges/internal/openbadges"
+	"demerzel-badges/internal/repository"
+	"demerzel-badges/internal/templates"
+	"demerzel-badges/internal/urls"
+
+	"gorm.io/gorm"
+)
+
+// Config is the part of the configuration used by the handlers.
+type Config struct {
+	// URLs builds the links handed out.
+	URLs urls.Builder
+
+	Issuer         openbadges.Issuer
+	OpenBadgesSalt string
+
+	// CredentialSigningKey signs the verifiable credentials, none are issued
+	// without it.
+	CredentialSigningKey ed25519.PrivateKey
+
+	// APIKeyScopes are the only permissions an API key can be created with,
+	// so that a key never grants more than a service needs.
+	APIKeyScopes []string
+
+	// Channels are the notification channels a mail is queued for.
+	Channels []string
+
+	// LadderNoGaps refuses skill ladders leaving score ranges uncovered by
+	// any badge.
+	LadderNoGaps bool
+
+	Templates templates.Config
+}
+
+// Handlers holds the dependencies of the API handlers.
+type Handlers struct {
+	Badges      repository.BadgeRepository
+	UserBadges  repository.UserBadgeRepository
+	Skills      repository.SkillRepository
+	Assessments repository.AssessmentRepository
+
+	Mails           repository.MailRepository
+	Roles           repository.RoleRepository
+	APIKeys         repository.APIKeyRepository
+	IdempotencyKeys repository.IdempotencyRepository
+
+	// Credentials signs the verifiable credentials, nil when no signing key
+	// is configured.
+	Credentials *credentials.Issuer
+
+	Config Config
+}
+
+// New returns the handlers working on the database.
+func New(db *gorm.DB, cfg Config) *Handlers {
+	store := repository.DBStore{DB: db}
+
+	h := &Handlers{
+		Badges:      store,
+		UserBadges:  store,
+		Skills:      store,
+		Assessments: store,
+
+		Mails:           store,
+		Roles:           store,
+		APIKeys:         store,
+		IdempotencyKeys: store,
+
+		Config: cfg,
+	}
+
+	if cfg.CredentialSigningKey != nil {
+		h.Credentials = credentials.NewIssuer(cfg.CredentialSigningKey, cfg.Issuer.Name, cfg.Issuer.URL)
+	}
+
+	return h
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"demerzel-badges/internal/models"
+	"demerzel-badges/internal/openbadges"
+	"demerzel-badges/internal/urls"
+	"demerzel-badges/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) userBadgeLinks(userBadge *models.UserBadge) urls.Links {
+	var username string
+	if userBadge.User != nil {
+		username = userBadge.User.Username
+	}
+
+	return h.Config.URLs.UserBadgeLinks(userBadge.ID, userBadge.BadgeID, userBadge.UserID, username)
+}
+
+func (h *Handlers) openBadgesBuilder() openbadges.Builder {
+	return openbadges.Builder{
+		URLs:   h.Config.URLs,
+		Issuer: h.Config.Issuer,
+		Salt:   h.Config.OpenBadgesSalt,
+	}
+}
+
+func (h *Handlers) OpenBadgesIssuerHandler(c *gin.Context) {
+	response.LinkedData(c, http.StatusOK, h.openBadgesBuilder().Profile())
+}
+
+func (h *Handlers) OpenBadgesBadgeClassHandler(c *gin.Context) {
+	badgeID, err := strconv.ParseUint(c.Param("badge_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid badgeID", map[string]interface{}{})
+		return
+	}
+
+	badge, err := h.Badges.FindBadgeByID(uint(badgeID))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "Badge Not found", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response.LinkedData(c, http.StatusOK, h.openBadgesBuilder().BadgeClass(*badge))
+}
+
+func (h *Handlers) OpenBadgesAssertionHandler(c *gin.Context) {
+	assertionID, err := strconv.ParseUint(c.Param("assertion_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid assertionID", map[string]interface{}{})
+		return
+	}
+
+	userBadge, err := h.UserBadges.FindUserBadge(uint(assertionID))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "Assertion Not found", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	builder := h.openBadgesBuilder()
+	if userBadge.IsRevoked() {
+		response.LinkedData(c, http.StatusGone, builder.RevokedAssertion(*userBadge))
+		return
+	}
+
+	assertion, err := builder.Assertion(*userBadge)
+	if err != nil {
+		response.Error(c, http.StatusUnprocessableEntity, "Unable to build assertion", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response.LinkedData(c, http.StatusOK, assertion)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"demerzel-badges/internal/models"
+	"demerzel-badges/internal/views"
+	"demerzel-badges/pkg/response"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) ListOutboxHandler(c *gin.Context) {
+	status := models.MailStatus(c.Query("status"))
+	switch status {
+	case "", models.MailPending, models.MailComplete, models.MailFailed:
+	default:
+		response.Error(c, http.StatusBadRequest, "Invalid status", map[string]interface{}{
+			"status": "status should be pending, complete or failed",
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 200 {
+		response.Error(c, http.StatusBadRequest, "Invalid limit", map[string]interface{}{
+			"limit": "limit should be between 1 and 200",
+		})
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		response.Error(c, http.StatusBadRequest, "Invalid offset", map[string]interface{}{})
+		return
+	}
+
+	mails, err := h.Mails.ListMailLogs(models.MailLogFilter{
+		Status: status,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to list outbox", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Outbox", map[string]interface{}{
+		"mails": views.NewMailViews(mails),
+	})
+}
+
+func (h *Handlers) ReplayOutboxHandler(c *gin.Context) {
+	mailID, err := strconv.ParseUint(c.Param("mail_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid mailID", map[string]interface{}{})
+		return
+	}
+
+	mail, err := h.Mails.FindMailLog(uint(mailID))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "Mail Not found", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	err = h.Mails.ReplayMail(mail)
+	if errors.Is(err, models.ErrMailNotReplayable) {
+		response.Error(c, http.StatusConflict, "Unable to replay mail", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to replay mail", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Mail Queued For Delivery", map[string]interface{}{
+		"mail": views.NewMailView(mail),
+	})
+}
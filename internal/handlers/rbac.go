@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"demerzel-badges/internal/models"
+	"demerzel-badges/internal/rbac"
+	"demerzel-badges/internal/views"
+	"demerzel-badges/pkg/response"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func (h *Handlers) ListRolesHandler(c *gin.Context) {
+	roles, err := h.Roles.ListRoles()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to list roles", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Roles", map[string]interface{}{
+		"roles": views.NewRoleViews(roles),
+	})
+}
+
+func (h *Handlers) CreateRoleHandler(c *gin.Context) {
+	type CreateRoleRequest struct {
+		Name        string   `json:"name"`
+		Permissions []string `json:"permissions"`
+	}
+	var input CreateRoleRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Unable to parse payload: %s", err.Error()), map[string]interface{}{})
+		return
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		response.Error(c, http.StatusUnprocessableEntity, "Invalid input", map[string]interface{}{
+			"name": "name is required",
+		})
+		return
+	}
+
+	for _, permission := range input.Permissions {
+		if !models.ValidPermissionName(permission) {
+			response.Error(c, http.StatusUnprocessableEntity, "Invalid input", map[string]interface{}{
+				"permissions": models.ErrInvalidPermission.Error(),
+			})
+			return
+		}
+	}
+
+	role, err := h.Roles.CreateRole(input.Name, input.Permissions)
+	if errors.Is(err, models.ErrRoleExists) {
+		response.Error(c, http.StatusConflict, "Role already exists", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to create role", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Role Created Successfully", map[string]interface{}{
+		"role": views.NewRoleView(role),
+	})
+}
+
+func (h *Handlers) DeleteRoleHandler(c *gin.Context) {
+	role, ok := h.roleFromParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Roles.DeleteRole(role.ID); err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to delete role", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	rbac.Invalidate()
+
+	response.Success(c, http.StatusOK, "Role Deleted Successfully", nil)
+}
+
+func (h *Handlers) GrantRolePermissionHandler(c *gin.Context) {
+	role, ok := h.roleFromParam(c)
+	if !ok {
+		return
+	}
+
+	permission, ok := permissionFromBody(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Roles.GrantRolePermission(role.ID, permission); err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to grant permission", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	rbac.Invalidate()
+	h.respondWithRole(c, role, "Permission Granted Successfully")
+}
+
+func (h *Handlers) RevokeRolePermissionHandler(c *gin.Context) {
+	role, ok := h.roleFromParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Roles.RevokeRolePermission(role.ID, c.Param("permission")); err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to revoke permission", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	rbac.Invalidate()
+	h.respondWithRole(c, role, "Permission Revoked Successfully")
+}
+
+func (h *Handlers) GetUserGrantsHandler(c *gin.Context) {
+	grants, err := h.Roles.GetUserGrants(c.Param("user_id"))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to get user permissions", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response.Success(c, http.StatusOK, "User Permissions", views.NewUserGrantsView(grants))
+}
+
+func (h *Handlers) AssignUserRoleHandler(c *gin.Context) {
+	type AssignRoleRequest struct {
+		RoleID uint `json:"role_id"`
+	}
+	var input AssignRoleRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Unable to parse payload: %s", err.Error()), map[string]interface{}{})
+		return
+	}
+
+	if _, err := h.Roles.FindRoleByID(input.RoleID); err != nil {
+		response.Error(c, http.StatusUnprocessableEntity, "Invalid input", map[string]interface{}{
+			"role_id": "no role found matching provided ID",
+		})
+		return
+	}
+
+	err := h.Roles.AssignUserRole(c.Param("user_id"), input.RoleID)
+	if errors.Is(err, models.ErrRoleAlreadyGranted) {
+		response.Error(c, http.StatusConflict, "Role already assigned", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to assign role", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	rbac.Invalidate()
+	h.respondWithUserGrants(c, "Role Assigned Successfully")
+}
+
+func (h *Handlers) UnassignUserRoleHandler(c *gin.Context) {
+	roleID, err := strconv.ParseUint(c.Param("role_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid roleID", map[string]interface{}{})
+		return
+	}
+
+	if err := h.Roles.UnassignUserRole(c.Param("user_id"), uint(roleID)); err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to unassign role", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	rbac.Invalidate()
+	h.respondWithUserGrants(c, "Role Unassigned Successfully")
+}
+
+func (h *Handlers) GrantUserPermissionHandler(c *gin.Context) {
+	permission, ok := permissionFromBody(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Roles.GrantUserPermission(c.Param("user_id"), permission); err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to grant permission", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	rbac.Invalidate()
+	h.respondWithUserGrants(c, "Permission Granted Successfully")
+}
+
+func (h *Handlers) RevokeUserPermissionHandler(c *gin.Context) {
+	if err := h.Roles.RevokeUserPermission(c.Param("user_id"), c.Param("permission")); err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to revoke permission", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	rbac.Invalidate()
+	h.respondWithUserGrants(c, "Permission Revoked Successfully")
+}
+
+// roleFromParam loads the role of the role_id parameter, answering with an
+// error when it cannot.
+func (h *Handlers) roleFromParam(c *gin.Context) (*models.Role, bool) {
+	roleID, err := strconv.ParseUint(c.Param("role_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid roleID", map[string]interface{}{})
+		return nil, false
+	}
+
+	role, err := h.Roles.FindRoleByID(uint(roleID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		response.Error(c, http.StatusNotFound, "Role Not found", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, false
+	}
+
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to get role", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return nil, false
+	}
+
+	return role, true
+}
+
+func permissionFromBody(c *gin.Context) (string, bool) {
+	type PermissionRequest struct {
+		Permission string `json:"permission"`
+	}
+	var input PermissionRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Unable to parse payload: %s", err.Error()), map[string]interface{}{})
+		return "", false
+	}
+
+	input.Permission = strings.TrimSpace(input.Permission)
+	if !models.ValidPermissionName(input.Permission) {
+		response.Error(c, http.StatusUnprocessableEntity, "Invalid input", map[string]interface{}{
+			"permission": models.ErrInvalidPermission.Error(),
+		})
+		return "", false
+	}
+
+	return input.Permission, true
+}
+
+func (h *Handlers) respondWithRole(c *gin.Context, role *models.Role, message string) {
+	permissions, err := h.Roles.RolePermissionNames(role.ID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to get role permissions", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	response.Success(c, http.StatusOK, message, map[string]interface{}{
+		"role": views.NewRoleView(&models.RoleWithPermissions{Role: *role, Permissions: permissions}),
+	})
+}
+
+func (h *Handlers) respondWithUserGrants(c *gin.Context, message string) {
+	grants, err := h.Roles.GetUserGrants(c.Param("user_id"))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to get user permissions", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	response.Success(c, http.StatusOK, message, views.NewUserGrantsView(grants))
+}
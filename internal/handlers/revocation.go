@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"demerzel-badges/internal/models"
+	"demerzel-badges/internal/urls"
+	"demerzel-badges/internal/views"
+	"demerzel-badges/pkg/response"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) RevokeUserBadgeHandler(c *gin.Context) {
+	type RevokeBadgeRequest struct {
+		Reason string `json:"reason"`
+	}
+	var input RevokeBadgeRequest
+
+	badgeID, err := strconv.ParseUint(c.Param("badge_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid badgeID", map[string]interface{}{})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Unable to parse payload: %s", err.Error()), map[string]interface{}{})
+		return
+	}
+
+	input.Reason = strings.TrimSpace(input.Reason)
+	if input.Reason == "" {
+		response.Error(c, http.StatusUnprocessableEntity, "Invalid input", map[string]interface{}{
+			"reason": "reason is required",
+		})
+		return
+	}
+
+	if _, err := h.UserBadges.FindUserBadge(uint(badgeID)); err != nil {
+		response.Error(c, http.StatusNotFound, "Badge Not found", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	badge, err := h.UserBadges.RevokeUserBadge(uint(badgeID), c.GetString("user_id"), input.Reason)
+	if errors.Is(err, models.ErrBadgeAlreadyRevoked) {
+		response.Error(c, http.StatusConflict, "Badge already revoked", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to revoke badge", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Badge Revoked Successfully", map[string]interface{}{
+		"badge": views.NewUserBadgeView(badge),
+	})
+}
+
+// VerifyUserBadgeHandler is the public status of a user badge, for third
+// parties checking that a badge shown to them can still be trusted.
+func (h *Handlers) VerifyUserBadgeHandler(c *gin.Context) {
+	type BadgeStatus struct {
+		ID               uint       `json:"id"`
+		Status           string     `json:"status"`
+		Current          bool       `json:"current"`
+		UserID           string     `json:"user_id"`
+		Badge            string     `json:"badge"`
+		Skill            string     `json:"skill"`
+		IssuedOn         time.Time  `json:"issued_on"`
+		RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+		RevocationReason string     `json:"revocation_reason,omitempty"`
+		Links            urls.Links `json:"links"`
+	}
+
+	badgeID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid badgeID", map[string]interface{}{})
+		return
+	}
+
+	badge, err := h.UserBadges.FindUserBadge(uint(badgeID))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "Badge Not found", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	status := BadgeStatus{
+		ID:               badge.ID,
+		Status:           "valid",
+		Current:          badge.IsCurrent(),
+		UserID:           badge.UserID,
+		IssuedOn:         badge.CreatedAt,
+		RevokedAt:        badge.RevokedAt,
+		RevocationReason: badge.RevocationReason,
+		Links:            h.userBadgeLinks(badge),
+	}
+
+	if badge.IsRevoked() {
+		status.Status = "revoked"
+	}
+
+	if badge.Badge != nil {
+		status.Badge = string(badge.Badge.Name)
+		if badge.Badge.Skill != nil {
+			status.Skill = badge.Badge.Skill.CategoryName
+		}
+	}
+
+	response.Success(c, http.StatusOK, "Badge Status", map[string]interface{}{
+		"verification": status,
+	})
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"demerzel-badges/internal/models"
+	"demerzel-badges/internal/views"
+	"demerzel-badges/pkg/response"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// validLadder writes a 422 response listing the problems of the ladder and
+// returns false when it is not valid.
+func (h *Handlers) validLadder(c *gin.Context, ladder []models.SkillBadge) bool {
+	issues := models.ValidateLadder(ladder, h.Config.LadderNoGaps)
+	if len(issues) == 0 {
+		return true
+	}
+
+	response.Error(c, http.StatusUnprocessableEntity, "Invalid badge ladder", map[string]interface{}{
+		"ladder": issues,
+	})
+
+	return false
+}
+
+func (h *Handlers) GetSkillLadderHandler(c *gin.Context) {
+	skillID, err := strconv.ParseUint(c.Param("skill_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid skillID", map[string]interface{}{})
+		return
+	}
+
+	if _, err := h.Skills.FindSkillById(uint(skillID)); err != nil {
+		response.Error(c, http.StatusNotFound, "Skill Not found", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ladder, err := h.Badges.GetSkillLadder(uint(skillID))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to get ladder", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Skill Ladder", map[string]interface{}{
+		"ladder": views.NewLadderView(models.BuildLadderReport(uint(skillID), ladder, h.Config.LadderNoGaps)),
+	})
+}
+
+func (h *Handlers) UpdateSkillLadderHandler(c *gin.Context) {
+	type BadgeRange struct {
+		BadgeID  uint    `json:"badge_id"`
+		MinScore float64 `json:"min_score"`
+		MaxScore float64 `json:"max_score"`
+	}
+	type UpdateLadderRequest struct {
+		Badges []BadgeRange `json:"badges"`
+	}
+	var input UpdateLadderRequest
+
+	skillID, err := strconv.ParseUint(c.Param("skill_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid skillID", map[string]interface{}{})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Unable to parse payload: %s", err.Error()), map[string]interface{}{})
+		return
+	}
+
+	ladder, err := h.Badges.GetSkillLadder(uint(skillID))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to update ladder", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	updated := make([]models.SkillBadge, 0, len(input.Badges))
+	for _, change := range input.Badges {
+		var badge *models.SkillBadge
+		for i := range ladder {
+			if ladder[i].ID == change.BadgeID {
+				badge = &ladder[i]
+			}
+		}
+
+		if badge == nil {
+			response.Error(c, http.StatusUnprocessableEntity, "Invalid input", map[string]interface{}{
+				"badge_id": fmt.Sprintf("badge %d does not belong to this skill", change.BadgeID),
+			})
+			return
+		}
+
+		if change.MinScore < 0 || change.MinScore >= change.MaxScore {
+			response.Error(c, http.StatusUnprocessableEntity, "Invalid input", map[string]interface{}{
+				"badge_id": fmt.Sprintf("badge %d: min_score should be at least 0 and lower than max_score", change.BadgeID),
+			})
+			return
+		}
+
+		badge.MinScore, badge.MaxScore = change.MinScore, change.MaxScore
+		updated = append(updated, *badge)
+	}
+
+	if !h.validLadder(c, ladder) {
+		return
+	}
+
+	if err := h.Badges.UpdateLadderScores(updated); err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to update ladder", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	models.SortLadder(ladder)
+
+	response.Success(c, http.StatusOK, "Skill Ladder Updated Successfully", map[string]interface{}{
+		"ladder": views.NewLadderView(models.BuildLadderReport(uint(skillID), ladder, h.Config.LadderNoGaps)),
+	})
+}
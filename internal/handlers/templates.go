@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"demerzel-badges/internal/templates"
+	"demerzel-badges/pkg/response"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) PreviewTemplateHandler(c *gin.Context) {
+	renderer := templates.FromConfig(h.Config.Templates)
+
+	skill := c.DefaultQuery("skill", "Backend")
+	tier := c.DefaultQuery("tier", "Expert")
+
+	links := h.Config.URLs
+	email, err := renderer.Render(c.Param("name"), templates.BadgeEmail{
+		Name:             c.DefaultQuery("name", "Jane"),
+		Skill:            skill,
+		BadgeName:        tier,
+		ProfileLink:      links.Profile("a2218d8f-4cdb-4114-a847-4cf8fcbd2e54", "jane"),
+		VerificationLink: links.Verification(1),
+		ImageLink:        links.BadgeImage(1),
+	}, templates.Options{
+		Locale: c.DefaultQuery("locale", requestLocale(c)),
+		Tier:   tier,
+		Skill:  skill,
+	})
+
+	if errors.Is(err, templates.ErrTemplateNotFound) {
+		response.Error(c, http.StatusNotFound, "Template Not found", map[string]interface{}{
+			"templates": renderer.Names(),
+		})
+		return
+	}
+
+	if err != nil {
+		response.Error(c, http.StatusUnprocessableEntity, "Unable to render template", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	switch c.DefaultQuery("format", "html") {
+	case "html":
+		if email.HTML == "" {
+			response.Error(c, http.StatusNotFound, "Template has no HTML part", map[string]interface{}{})
+			return
+		}
+
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(email.HTML))
+	case "text":
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte("Subject: "+email.Subject+"\n\n"+email.Text))
+	case "json":
+		response.Success(c, http.StatusOK, "Template Preview", map[string]interface{}{
+			"email": email,
+		})
+	default:
+		response.Error(c, http.StatusBadRequest, "Invalid format", map[string]interface{}{
+			"format": "format should be html, text or json",
+		})
+	}
+}
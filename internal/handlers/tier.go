@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"demerzel-badges/internal/models"
+	"demerzel-badges/internal/views"
+	"demerzel-badges/pkg/response"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) ListTiersHandler(c *gin.Context) {
+	var skillID uint64
+	if skillIDQuery := c.Query("skill_id"); skillIDQuery != "" {
+		var err error
+		skillID, err = strconv.ParseUint(skillIDQuery, 10, 64)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid skill_id", map[string]interface{}{})
+			return
+		}
+	}
+
+	tiers, err := h.Badges.GetTierLadder(uint(skillID))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to list tiers", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Badge Tiers", map[string]interface{}{
+		"tiers": views.NewTierViews(tiers),
+	})
+}
+
+func (h *Handlers) CreateTierHandler(c *gin.Context) {
+	type CreateTierRequest struct {
+		SkillID     *uint  `json:"skill_id"`
+		Name        string `json:"name"`
+		DisplayName string `json:"display_name"`
+		Description string `json:"description"`
+		Rank        int    `json:"rank"`
+	}
+	var input CreateTierRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Unable to parse payload: %s", err.Error()), map[string]interface{}{})
+		return
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		response.Error(c, http.StatusUnprocessableEntity, "Invalid input", map[string]interface{}{
+			"name": "name is required",
+		})
+		return
+	}
+
+	if input.Rank < 1 {
+		response.Error(c, http.StatusUnprocessableEntity, "Invalid input", map[string]interface{}{
+			"rank": "rank should be at least 1",
+		})
+		return
+	}
+
+	if input.SkillID != nil {
+		if _, err := h.Skills.FindSkillById(*input.SkillID); err != nil {
+			response.Error(c, http.StatusUnprocessableEntity, "Invalid input", map[string]interface{}{
+				"skill": "no skill found matching provided ID",
+			})
+			return
+		}
+	}
+
+	tier, err := h.Badges.CreateTier(models.BadgeTier{
+		SkillID:     input.SkillID,
+		Name:        input.Name,
+		DisplayName: input.DisplayName,
+		Description: input.Description,
+		Rank:        input.Rank,
+	})
+
+	if errors.Is(err, models.ErrTierExists) {
+		response.Error(c, http.StatusBadRequest, "Tier already exists", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to create tier", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Tier Created Successfully", map[string]interface{}{
+		"tier": views.NewTierView(tier),
+	})
+}
+
+func (h *Handlers) UpdateTierHandler(c *gin.Context) {
+	type UpdateTierRequest struct {
+		Name        *string `json:"name"`
+		DisplayName *string `json:"display_name"`
+		Description *string `json:"description"`
+		Rank        *int    `json:"rank"`
+	}
+	var input UpdateTierRequest
+
+	tierID, err := strconv.ParseUint(c.Param("tier_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid tierID", map[string]interface{}{})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Unable to parse payload: %s", err.Error()), map[string]interface{}{})
+		return
+	}
+
+	tier, err := h.Badges.FindTierByID(uint(tierID))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "Tier Not found", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if input.Name != nil {
+		tier.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.DisplayName != nil {
+		tier.DisplayName = *input.DisplayName
+	}
+	if input.Description != nil {
+		tier.Description = *input.Description
+	}
+	if input.Rank != nil {
+		tier.Rank = *input.Rank
+	}
+
+	if tier.Name == "" {
+		response.Error(c, http.StatusUnprocessableEntity, "Invalid input", map[string]interface{}{
+			"name": "name is required",
+		})
+		return
+	}
+
+	if tier.Rank < 1 {
+		response.Error(c, http.StatusUnprocessableEntity, "Invalid input", map[string]interface{}{
+			"rank": "rank should be at least 1",
+		})
+		return
+	}
+
+	err = h.Badges.UpdateTier(tier, h.Config.LadderNoGaps)
+	if errors.Is(err, models.ErrTierExists) {
+		response.Error(c, http.StatusBadRequest, "Tier already exists", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var ladderErr *models.LadderError
+	if errors.As(err, &ladderErr) {
+		response.Error(c, http.StatusUnprocessableEntity, "Invalid badge ladder", map[string]interface{}{
+			"skill_id": ladderErr.SkillID,
+			"ladder":   ladderErr.Issues,
+		})
+		return
+	}
+
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to update tier", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Tier Updated Successfully", map[string]interface{}{
+		"tier": views.NewTierView(tier),
+	})
+}
+
+func (h *Handlers) DeleteTierHandler(c *gin.Context) {
+	tierID, err := strconv.ParseUint(c.Param("tier_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid tierID", map[string]interface{}{})
+		return
+	}
+
+	if _, err := h.Badges.FindTierByID(uint(tierID)); err != nil {
+		response.Error(c, http.StatusNotFound, "Tier Not found", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	err = h.Badges.DeleteTier(uint(tierID))
+	if errors.Is(err, models.ErrTierInUse) {
+		response.Error(c, http.StatusConflict, "Tier cannot be deleted", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to delete tier", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Tier Deleted Successfully", nil)
+}
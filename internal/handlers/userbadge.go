@@ -1,54 +1,39 @@
 package handlers
 
 import (
-	"demerzel-badges/internal/db"
-	"demerzel-badges/internal/models"
+	"demerzel-badges/internal/views"
 	"demerzel-badges/pkg/response"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
-func GetUserBadgeBySkill(c *gin.Context) {
-	skillId := c.Param("skillId")
-	userId := c.GetString("user_id")
-
-	var userbadge []models.UserBadge
-	var skillBadges []models.SkillBadge
-
-	result := db.DB.Where("id = ?", skillId).Find(&skillBadges)
-	if result.Error != nil {
-		response.Error(c, http.StatusInternalServerError, "Unable to get badge", map[string]interface{}{
-			"error": result.Error,
-		})
-
+func (h *Handlers) GetUserBadgeBySkill(c *gin.Context) {
+	skillID, err := strconv.ParseUint(c.Param("skillId"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid skillID", map[string]interface{}{})
 		return
 	}
 
-	var skillBadgeIDs []uint
-	for _, badge := range skillBadges {
-		skillBadgeIDs = append(skillBadgeIDs, badge.ID)
-	}
-
-	result = db.DB.Where("user_id=?", userId).
-		Where("badge_id IN ?", skillBadgeIDs).
-		Find(&userbadge)
+	userId := c.GetString("user_id")
 
-	if result.Error != nil {
-		response.Error(c, http.StatusInternalServerError, "User Badge not Found", map[string]interface{}{
-			"error": result.Error,
+	userbadge, err := h.UserBadges.GetUserBadgesForSkill(userId, uint(skillID))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Unable to get badge", map[string]interface{}{
+			"error": err.Error(),
 		})
 		return
 	}
 
 	if len(userbadge) == 0 {
 		response.Error(c, http.StatusNotFound, "User Badge not Found", map[string]interface{}{
-			"data": userbadge,
+			"data": views.NewUserBadgeViews(userbadge),
 		})
 		return
 	}
 
 	response.Success(c, http.StatusOK, "User Badge Retrieved Successfully", map[string]interface{}{
-		"data": userbadge,
+		"data": views.NewUserBadgeViews(userbadge),
 	})
 }
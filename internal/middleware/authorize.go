@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"demerzel-badges/internal/auth"
+	"demerzel-badges/pkg/response"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Scopes of a permission ending in ".own", see RequirePermission.
+const (
+	ScopeOwn = "own"
+	ScopeAny = "any"
+)
+
+// RequirePermission checks with the auth client that the bearer token grants
+// all the permissions, and stores the caller's ID under "user_id".
+//
+// A permission ending in ".own" is also granted by its ".any" variant. The
+// scope that was granted is stored under "permission_scope", for handlers to
+// decide whether the caller may act on other users.
+func RequirePermission(client auth.Client, permissions ...string) gin.HandlerFunc {
+	return authorize(client, permissions, true)
+}
+
+// RequireAnyPermission is RequirePermission granting access when any of the
+// permissions is granted.
+func RequireAnyPermission(client auth.Client, permissions ...string) gin.HandlerFunc {
+	return authorize(client, permissions, false)
+}
+
+// Scope returns the scope granted to the caller. It is ScopeAny only when a
+// ".any" permission was granted, so that a route without a scoped permission
+// never lets the caller act on other users.
+func Scope(c *gin.Context) string {
+	if c.GetString("permission_scope") == ScopeAny {
+		return ScopeAny
+	}
+
+	return ScopeOwn
+}
+
+// IsMachine reports whether the caller is a service using an API key, whose
+// "user_id" is not a user.
+func IsMachine(c *gin.Context) bool {
+	return c.GetBool("machine")
+}
+
+func authorize(client auth.Client, permissions []string, all bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader("Authorization")
+
+		// Check Auth header was supplied
+		if token == "" || len(strings.Split(token, " ")) != 2 {
+			response.Error(c, http.StatusUnauthorized, "Invalid Authorization Header", map[string]interface{}{
+				"Auth": "Authorization header is missing or improperly formatted",
+			})
+			c.Abort()
+			return
+		}
+
+		token = strings.Split(token, " ")[1]
+		if token == "" {
+			response.Error(c, http.StatusUnauthorized, "Specify a bearer token", map[string]interface{}{
+				"Auth": "Authorization header is missing or improperly formatted",
+			})
+			c.Abort()
+			return
+		}
+
+		var principal *auth.Principal
+		var scope string
+		var err error
+
+		for _, permission := range permissions {
+			var granted *auth.Principal
+			var grantedScope string
+			granted, grantedScope, err = authorizeScoped(c, client, token, permission)
+
+			if err == nil {
+				principal = granted
+				if grantedScope != "" && scope != ScopeOwn {
+					scope = grantedScope
+				}
+			}
+
+			if (err == nil) != all {
+				break
+			}
+		}
+
+		var denied *auth.DeniedError
+		if errors.As(err, &denied) {
+			response.Error(c, denied.Status, "You are not Authorized to access this resource", denied.Message)
+			c.Abort()
+			return
+		}
+
+		if err != nil {
+			response.Error(c, http.StatusInternalServerError, "Auth service Error", err.Error())
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", principal.UserID)
+		c.Set("machine", principal.Machine)
+		if scope != "" {
+			c.Set("permission_scope", scope)
+		}
+		c.Next()
+	}
+}
+
+// authorizeScoped checks a permission, trying the ".any" variant first for
+// the ones ending in ".own".
+func authorizeScoped(c *gin.Context, client auth.Client, token string, permission string) (*auth.Principal, string, error) {
+	base, scoped := cutSuffix(permission, "."+ScopeOwn)
+	if !scoped {
+		principal, err := client.Authorize(c.Request.Context(), token, permission)
+		return principal, "", err
+	}
+
+	principal, err := client.Authorize(c.Request.Context(), token, base+"."+ScopeAny)
+
+	var denied *auth.DeniedError
+	if !errors.As(err, &denied) {
+		return principal, ScopeAny, err
+	}
+
+	principal, err = client.Authorize(c.Request.Context(), token, permission)
+
+	return principal, ScopeOwn, err
+}
+
+func cutSuffix(s string, suffix string) (string, bool) {
+	if !strings.HasSuffix(s, suffix) {
+		return s, false
+	}
+
+	return strings.TrimSuffix(s, suffix), true
+}
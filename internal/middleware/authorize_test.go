@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"demerzel-badges/internal/auth"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// grants authorizes the permissions it holds for any token.
+type grants map[string]bool
+
+func (g grants) Authorize(ctx context.Context, token string, permission string) (*auth.Principal, error) {
+	if !g[permission] {
+		return nil, &auth.DeniedError{Status: http.StatusForbidden, Message: "missing " + permission}
+	}
+
+	return &auth.Principal{UserID: "user-1"}, nil
+}
+
+func serve(handler gin.HandlerFunc, authorization string) (int, string) {
+	gin.SetMode(gin.TestMode)
+
+	scope := ""
+	r := gin.New()
+	r.GET("/", handler, func(c *gin.Context) {
+		scope = Scope(c)
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	return w.Code, scope
+}
+
+func TestRequirePermission(t *testing.T) {
+	client := grants{"badge.read": true, "badge.create": true}
+
+	code, _ := serve(RequirePermission(client, "badge.read"), "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, _ = serve(RequirePermission(client, "badge.read", "badge.create"), "Bearer token")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = serve(RequirePermission(client, "badge.read", "badge.delete"), "Bearer token")
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, _ = serve(RequireAnyPermission(client, "badge.delete", "badge.read"), "Bearer token")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = serve(RequireAnyPermission(client, "badge.delete", "tier.manage"), "Bearer token")
+	assert.Equal(t, http.StatusForbidden, code)
+}
+
+func TestRequirePermissionScope(t *testing.T) {
+	code, scope := serve(RequirePermission(grants{"badge.update.own": true}, "badge.update.own"), "Bearer token")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, ScopeOwn, scope)
+
+	code, scope = serve(RequirePermission(grants{"badge.update.any": true}, "badge.update.own"), "Bearer token")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, ScopeAny, scope)
+
+	code, _ = serve(RequirePermission(grants{"badge.read": true}, "badge.update.own"), "Bearer token")
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, scope = serve(RequirePermission(grants{"badge.read": true}, "badge.read"), "Bearer token")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, ScopeOwn, scope, "an unscoped permission does not grant any user")
+
+	code, scope = serve(RequirePermission(grants{"badge.read": true, "badge.update.any": true}, "badge.read", "badge.update.own"), "Bearer token")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, ScopeAny, scope)
+}
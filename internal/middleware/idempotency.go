@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"demerzel-badges/internal/models"
+	"demerzel-badges/internal/repository"
+	"demerzel-badges/pkg/response"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotent replays the stored response when a request is retried with the
+// same Idempotency-Key header. It must run after the auth middleware since
+// keys are scoped to the calling user. Without a store for the keys, requests
+// are not deduplicated.
+func Idempotent(keys repository.IdempotencyRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" || keys == nil {
+			c.Next()
+			return
+		}
+
+		payload, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "Unable to read request body", map[string]interface{}{})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(payload))
+
+		hash := sha256.Sum256(payload)
+		requestHash := hex.EncodeToString(hash[:])
+		userID := c.GetString("user_id")
+
+		existing, err := keys.FindIdempotencyKey(key, userID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, http.StatusInternalServerError, "Something went wrong", err.Error())
+			c.Abort()
+			return
+		}
+
+		if existing != nil && existing.Expired() {
+			if err := keys.DeleteIdempotencyKey(existing); err != nil {
+				response.Error(c, http.StatusInternalServerError, "Something went wrong", err.Error())
+				c.Abort()
+				return
+			}
+			existing = nil
+		}
+
+		if existing != nil {
+			replay(c, existing, requestHash)
+			return
+		}
+
+		record := &models.IdempotencyKey{
+			Key:         key,
+			UserID:      userID,
+			Method:      c.Request.Method,
+			Path:        c.FullPath(),
+			RequestHash: requestHash,
+		}
+
+		err = keys.ReserveIdempotencyKey(record)
+		if errors.Is(err, models.ErrIdempotencyKeyExists) {
+			response.Error(c, http.StatusConflict, "A request with this Idempotency-Key is already being processed", map[string]interface{}{})
+			c.Abort()
+			return
+		}
+
+		if err != nil {
+			response.Error(c, http.StatusInternalServerError, "Something went wrong", err.Error())
+			c.Abort()
+			return
+		}
+
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		// The reservation is released unless a response was stored, also when
+		// the handler panics, so that retries are not refused until it expires.
+		completed := false
+		defer func() {
+			if !completed {
+				keys.DeleteIdempotencyKey(record)
+			}
+		}()
+
+		c.Next()
+
+		// Server errors are not stored so that the client can retry them.
+		if writer.Status() >= http.StatusInternalServerError {
+			return
+		}
+
+		completed = keys.CompleteIdempotencyKey(record, writer.Status(), writer.body.String()) == nil
+	}
+}
+
+func replay(c *gin.Context, record *models.IdempotencyKey, requestHash string) {
+	defer c.Abort()
+
+	if record.RequestHash != requestHash || record.Path != c.FullPath() {
+		response.Error(c, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request", map[string]interface{}{})
+		return
+	}
+
+	if record.StatusCode == 0 {
+		response.Error(c, http.StatusConflict, "A request with this Idempotency-Key is already being processed", map[string]interface{}{})
+		return
+	}
+
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(record.StatusCode, "application/json; charset=utf-8", []byte(record.ResponseBody))
+}
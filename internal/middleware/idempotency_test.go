@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"bytes"
+	"demerzel-badges/internal/repository"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func post(r *gin.Engine, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"assessment_id": 1}`))
+	req.Header.Set("Idempotency-Key", key)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestIdempotentReplaysResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	calls := 0
+	r := gin.New()
+	r.POST("/", Idempotent(repository.NewMemoryStore()), func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"calls": calls})
+	})
+
+	first := post(r, "key-1")
+	assert.Equal(t, http.StatusCreated, first.Code)
+
+	retry := post(r, "key-1")
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotentReleasesKeyWhenHandlerPanics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	calls := 0
+	r := gin.New()
+	r.Use(gin.CustomRecovery(func(c *gin.Context, err interface{}) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	r.POST("/", Idempotent(repository.NewMemoryStore()), func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			panic("handler failed")
+		}
+		c.JSON(http.StatusCreated, gin.H{"calls": calls})
+	})
+
+	assert.Equal(t, http.StatusInternalServerError, post(r, "key-1").Code)
+	assert.Equal(t, http.StatusCreated, post(r, "key-1").Code)
+	assert.Equal(t, 2, calls)
+}
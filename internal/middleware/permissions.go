@@ -0,0 +1,35 @@
+package middleware
+
+// Permissions are the names of the permissions the routes require. They
+// default to the names used by the auth service, and each one can be renamed
+// with an environment variable, PERMISSION_BADGE_CREATE for BadgeCreate.
+type Permissions struct {
+	BadgeRead      string
+	BadgeCreate    string
+	BadgeUpdate    string
+	BadgeDelete    string
+	BadgeAssign    string
+	BadgeRevoke    string
+	TierManage     string
+	OutboxManage   string
+	TemplateManage string
+	RoleManage     string
+	APIKeyManage   string
+}
+
+// DefaultPermissions returns the names used by the auth service.
+func DefaultPermissions() Permissions {
+	return Permissions{
+		BadgeRead:      "badge.read",
+		BadgeCreate:    "badge.create",
+		BadgeUpdate:    "badge.update",
+		BadgeDelete:    "badge.delete",
+		BadgeAssign:    "badge.update.own",
+		BadgeRevoke:    "badge.revoke",
+		TierManage:     "tier.manage",
+		OutboxManage:   "outbox.manage",
+		TemplateManage: "template.manage",
+		RoleManage:     "role.manage",
+		APIKeyManage:   "apikey.manage",
+	}
+}
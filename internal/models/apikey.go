@@ -0,0 +1,119 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrAPIKeyRevoked = errors.New("API key has already been revoked")
+
+// apiKeyTouchInterval limits how often the last use of a key is written.
+const apiKeyTouchInterval = time.Minute
+
+// APIKey authorizes a service to call the API. Only the hash of the key is
+// stored, Prefix identifies it in lists and lookups.
+type APIKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix" gorm:"type:varchar(32);uniqueIndex"`
+	Hash       string     `json:"-" gorm:"type:varchar(64)"`
+	Scopes     []string   `json:"scopes" gorm:"type:json;serializer:json"`
+	CreatedBy  string     `json:"created_by"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (k APIKey) TableName() string {
+	return "api_key"
+}
+
+func (k APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+func (k APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// HasScope reports whether the key grants the permission.
+func (k APIKey) HasScope(permission string) bool {
+	for _, scope := range k.Scopes {
+		if scope == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+func CreateAPIKey(db *gorm.DB, key APIKey) (*APIKey, error) {
+	newKey := APIKey{
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Hash:      key.Hash,
+		Scopes:    key.Scopes,
+		CreatedBy: key.CreatedBy,
+		ExpiresAt: key.ExpiresAt,
+	}
+
+	err := db.Create(&newKey).Error
+
+	return &newKey, err
+}
+
+// ListAPIKeys returns the keys, newest first, revoked ones included.
+func ListAPIKeys(db *gorm.DB) ([]APIKey, error) {
+	keys := []APIKey{}
+	err := db.Order("created_at DESC, id DESC").Find(&keys).Error
+
+	return keys, err
+}
+
+func FindAPIKeyByID(db *gorm.DB, keyID uint) (*APIKey, error) {
+	var key APIKey
+	err := db.First(&key, keyID).Error
+
+	return &key, err
+}
+
+func FindAPIKeyByPrefix(db *gorm.DB, prefix string) (*APIKey, error) {
+	var key APIKey
+	err := db.Where("prefix = ?", prefix).First(&key).Error
+
+	return &key, err
+}
+
+// RevokeAPIKey stops the key from authorizing requests. The key is kept so
+// that it still shows in the list.
+func RevokeAPIKey(db *gorm.DB, keyID uint) (*APIKey, error) {
+	result := db.Model(&APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", keyID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	key, err := FindAPIKeyByID(db, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.RowsAffected == 0 {
+		return key, ErrAPIKeyRevoked
+	}
+
+	return key, nil
+}
+
+// TouchAPIKey records that the key was used, at most once a minute so that
+// busy keys do not write on every request.
+func TouchAPIKey(db *gorm.DB, keyID uint, now time.Time) error {
+	return db.Model(&APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", keyID, now.Add(-apiKeyTouchInterval)).
+		UpdateColumn("last_used_at", now).Error
+}
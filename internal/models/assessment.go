@@ -0,0 +1,71 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Reasons for refusing to award a badge for a user assessment.
+var (
+	ErrAssessmentNotFound      = errors.New("assessment not found")
+	ErrAssessmentNotOwned      = errors.New("assessment was taken by another user")
+	ErrAssessmentUnderReview   = errors.New("assessment is still under review")
+	ErrAssessmentFailed        = errors.New("assessment was not passed")
+	ErrAssessmentCancelled     = errors.New("assessment has been cancelled")
+	ErrAssessmentOutsideWindow = errors.New("assessment was submitted outside of its schedule")
+)
+
+// FindUserAssessment returns an assessment taken by a user with the
+// assessment it is for.
+func FindUserAssessment(db *gorm.DB, userAssessmentID uint) (*UserAssessment, error) {
+	var taken UserAssessment
+
+	err := db.Preload("Assessment").First(&taken, userAssessmentID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &taken, nil
+}
+
+// CheckAssessment verifies that a badge can be awarded to the user for the
+// assessment they took: it must be theirs, graded, for an assessment that is
+// not cancelled, and submitted between its start and end dates when these
+// are set.
+func CheckAssessment(taken UserAssessment, userID string) error {
+	if taken.UserID != userID {
+		return ErrAssessmentNotOwned
+	}
+
+	switch taken.Status {
+	case Pending:
+		return ErrAssessmentUnderReview
+	case Failed:
+		return ErrAssessmentFailed
+	}
+
+	if taken.Assessment.Status == Failed {
+		return ErrAssessmentCancelled
+	}
+
+	submitted := taken.SubmissionDate
+	if submitted.IsZero() {
+		submitted = taken.CreatedAt
+	}
+
+	if !inWindow(submitted, taken.Assessment.StartDate, taken.Assessment.EndDate) {
+		return ErrAssessmentOutsideWindow
+	}
+
+	return nil
+}
+
+func inWindow(at time.Time, start time.Time, end time.Time) bool {
+	if !start.IsZero() && at.Before(start) {
+		return false
+	}
+
+	return end.IsZero() || !at.After(end)
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testAssessment() UserAssessment {
+	start := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	return UserAssessment{
+		UserID:         "user-1",
+		Status:         Complete,
+		SubmissionDate: start.Add(24 * time.Hour),
+		Assessment: Assessment{
+			Status:    Complete,
+			StartDate: start,
+			EndDate:   start.Add(30 * 24 * time.Hour),
+		},
+	}
+}
+
+func TestCheckAssessment(t *testing.T) {
+	assert.NoError(t, CheckAssessment(testAssessment(), "user-1"))
+	assert.ErrorIs(t, CheckAssessment(testAssessment(), "user-2"), ErrAssessmentNotOwned)
+
+	taken := testAssessment()
+	taken.Status = Pending
+	assert.ErrorIs(t, CheckAssessment(taken, "user-1"), ErrAssessmentUnderReview)
+
+	taken.Status = Failed
+	assert.ErrorIs(t, CheckAssessment(taken, "user-1"), ErrAssessmentFailed)
+
+	taken = testAssessment()
+	taken.Assessment.Status = Failed
+	assert.ErrorIs(t, CheckAssessment(taken, "user-1"), ErrAssessmentCancelled)
+}
+
+func TestCheckAssessment_Window(t *testing.T) {
+	taken := testAssessment()
+	taken.SubmissionDate = taken.Assessment.EndDate.Add(time.Minute)
+	assert.ErrorIs(t, CheckAssessment(taken, "user-1"), ErrAssessmentOutsideWindow)
+
+	taken.SubmissionDate = taken.Assessment.StartDate.Add(-time.Minute)
+	assert.ErrorIs(t, CheckAssessment(taken, "user-1"), ErrAssessmentOutsideWindow)
+
+	// Without an end date, assessments stay open
+	taken.SubmissionDate = taken.Assessment.EndDate.Add(time.Hour)
+	taken.Assessment.EndDate = time.Time{}
+	assert.NoError(t, CheckAssessment(taken, "user-1"))
+
+	// Falls back to the creation date when the submission date is unknown
+	taken.SubmissionDate = time.Time{}
+	taken.CreatedAt = taken.Assessment.StartDate.Add(-time.Hour)
+	assert.ErrorIs(t, CheckAssessment(taken, "user-1"), ErrAssessmentOutsideWindow)
+}
@@ -3,64 +3,51 @@ package models
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Badge is the name of the tier a skill badge represents.
 type Badge string
 
-const (
-	Beginner     Badge = "Beginner"
-	Intermediate Badge = "Intermediate"
-	Expert       Badge = "Expert"
-)
-
-func GetValidBadgeName(badgeName string) (Badge, error) {
-	if strings.ToLower(string(Beginner)) == strings.ToLower(badgeName) {
-		return Beginner, nil
-	}
-
-	if strings.ToLower(string(Intermediate)) == strings.ToLower(badgeName) {
-		return Intermediate, nil
-	}
-
-	if strings.ToLower(string(Expert)) == strings.ToLower(badgeName) {
-		return Expert, nil
-	}
-
-	return "", errors.New("invalid badge Provided")
-}
+var ErrBadgeInUse = errors.New("badge has already been assigned to users")
 
 type SkillBadge struct {
 	ID       uint    `json:"id" gorm:"primaryKey"`
 	SkillID  uint    `json:"skill_id"`
-	Name     Badge   `json:"name"`
+	TierID   uint    `json:"tier_id"`
+	Name     Badge   `json:"name" gorm:"type:varchar(255)"`
 	MinScore float64 `json:"min_score"`
 	MaxScore float64 `json:"max_score"`
 
-	Skill *Skill `json:"Skill,omitempty"`
+	Skill *Skill     `json:"Skill,omitempty"`
+	Tier  *BadgeTier `json:"tier,omitempty"`
 }
 
 type SkillBadgeJson struct {
-	ID       uint    `json:"id" gorm:"primaryKey"`
-	SkillID  uint    `json:"skill_id"`
-	Name     string  `json:"name"`
-	MinScore float64 `json:"min_score"`
-	MaxScore float64 `json:"max_score"`
-	Skill    *Skill  `json:"Skill,omitempty"`
+	ID       uint       `json:"id" gorm:"primaryKey"`
+	SkillID  uint       `json:"skill_id"`
+	TierID   uint       `json:"tier_id"`
+	Name     string     `json:"name"`
+	MinScore float64    `json:"min_score"`
+	MaxScore float64    `json:"max_score"`
+	Skill    *Skill     `json:"Skill,omitempty"`
+	Tier     *BadgeTier `json:"tier,omitempty"`
 }
 
 func (sB SkillBadge) MarshalJSON() ([]byte, error) {
 	jsonData := SkillBadgeJson{
 		ID:       sB.ID,
 		SkillID:  sB.SkillID,
+		TierID:   sB.TierID,
 		Name:     strings.ToLower(string(sB.Name)),
 		MinScore: sB.MinScore,
 		MaxScore: sB.MaxScore,
 		Skill:    sB.Skill,
+		Tier:     sB.Tier,
 	}
 
 	return json.Marshal(jsonData)
@@ -72,9 +59,14 @@ func (sB SkillBadge) TableName() string {
 
 type UserBadge struct {
 	ID               uint        `json:"id" gorm:"primaryKey"`
-	UserID           string      `json:"user_id" gorm:"varchar(255)"`
-	BadgeID          uint        `json:"badge_id"`
-	UserAssessmentID uint        `json:"user_assessment_id"`
+	UserID           string      `json:"user_id" gorm:"varchar(255);uniqueIndex:idx_user_badge_assessment;uniqueIndex:idx_user_badge_badge"`
+	BadgeID          uint        `json:"badge_id" gorm:"uniqueIndex:idx_user_badge_badge,where:revoked_at IS NULL"`
+	UserAssessmentID uint        `json:"user_assessment_id" gorm:"uniqueIndex:idx_user_badge_assessment"`
+	SupersededAt     *time.Time  `json:"superseded_at"`
+	SupersededByID   *uint       `json:"superseded_by_id"`
+	RevokedAt        *time.Time  `json:"revoked_at,omitempty"`
+	RevokedBy        string      `json:"revoked_by,omitempty" gorm:"type:varchar(255)"`
+	RevocationReason string      `json:"revocation_reason,omitempty"`
 	CreatedAt        time.Time   `json:"created_at"`
 	UpdatedAt        time.Time   `json:"updated_at"`
 	User             *User       `json:"user,omitempty"`
@@ -87,13 +79,108 @@ func (uB UserBadge) TableName() string {
 	return "user_badge"
 }
 
-func (b Badge) IsValid() bool {
-	return b == Beginner || b == Intermediate || b == Expert
+// IsCurrent reports whether the badge is the one the user currently holds for
+// its skill, as opposed to a badge kept in history after an upgrade.
+func (uB UserBadge) IsCurrent() bool {
+	return uB.SupersededAt == nil && !uB.IsRevoked()
+}
+
+func (uB UserBadge) IsRevoked() bool {
+	return uB.RevokedAt != nil
+}
+
+// AssignOutcome describes what AssignBadge did.
+type AssignOutcome string
+
+const (
+	// AssignCreated is the first badge of the user for the skill.
+	AssignCreated AssignOutcome = "created"
+	// AssignUpgraded replaced a lower tier badge of the same skill.
+	AssignUpgraded AssignOutcome = "upgraded"
+	// AssignExisting means the assessment or badge had already been awarded.
+	AssignExisting AssignOutcome = "existing"
+	// AssignRetained means the score earned a tier that is not higher than
+	// the current one, which is kept.
+	AssignRetained AssignOutcome = "retained"
+)
+
+// Awarded reports whether a new badge was created.
+func (o AssignOutcome) Awarded() bool {
+	return o == AssignCreated || o == AssignUpgraded
+}
+
+// Award is what assigning the badge of an assessment does, as decided by
+// DecideAward.
+type Award struct {
+	Outcome AssignOutcome
+	// Badge is awarded when the outcome is AssignCreated or AssignUpgraded.
+	Badge *SkillBadge
+	// Kept is returned as is when the outcome is AssignExisting or
+	// AssignRetained.
+	Kept *UserBadge
+	// Superseded is the current badge replaced when the outcome is
+	// AssignUpgraded.
+	Superseded *UserBadge
+}
+
+// DecideAward decides how the badge matching the score of an assessment is
+// awarded once CheckAssessment accepted it, given the ladder of its skill and
+// the badges of the user with their tiers loaded. It only decides, leaving
+// the stores to apply it, so that they all award badges the same way.
+func DecideAward(taken UserAssessment, userID string, ladder []SkillBadge, userBadges []UserBadge) (Award, error) {
+	if err := CheckAssessment(taken, userID); err != nil {
+		return Award{}, err
+	}
+
+	for i := range userBadges {
+		if userBadges[i].UserAssessmentID == taken.ID {
+			return Award{Outcome: AssignExisting, Kept: &userBadges[i]}, nil
+		}
+	}
+
+	badge := FindBadgeForScore(ladder, taken.Score)
+	if badge == nil {
+		return Award{}, ErrNoBadgeForScore
+	}
+
+	// A revoked badge can be earned again
+	var current *UserBadge
+	for i := range userBadges {
+		userBadge := &userBadges[i]
+		if userBadge.BadgeID == badge.ID && !userBadge.IsRevoked() {
+			return Award{Outcome: AssignExisting, Kept: userBadge}, nil
+		}
+
+		if userBadge.IsCurrent() && userBadge.Badge != nil && userBadge.Badge.SkillID == taken.Assessment.SkillID {
+			current = userBadge
+		}
+	}
+
+	if current == nil {
+		return Award{Outcome: AssignCreated, Badge: badge}, nil
+	}
+
+	if !Outranks(badge, current.Badge) {
+		return Award{Outcome: AssignRetained, Kept: current}, nil
+	}
+
+	return Award{Outcome: AssignUpgraded, Badge: badge, Superseded: current}, nil
+}
+
+// Outranks reports whether badge a is a higher tier than badge b. Badges
+// without a tier are compared by score range.
+func Outranks(a, b *SkillBadge) bool {
+	if a.Tier != nil && b.Tier != nil {
+		return a.Tier.Rank > b.Tier.Rank
+	}
+
+	return a.MinScore > b.MinScore
 }
 
 func CreateBadge(db *gorm.DB, badge SkillBadge) (*SkillBadge, error) {
 	newBadge := SkillBadge{
 		SkillID:  badge.SkillID,
+		TierID:   badge.TierID,
 		Name:     badge.Name,
 		MinScore: badge.MinScore,
 		MaxScore: badge.MaxScore,
@@ -104,56 +191,189 @@ func CreateBadge(db *gorm.DB, badge SkillBadge) (*SkillBadge, error) {
 	return &newBadge, err
 }
 
-func BadgeExists(db *gorm.DB, skillID uint, badgeName Badge) bool {
+func BadgeExists(db *gorm.DB, skillID uint, tierID uint) bool {
 	var existingBadge SkillBadge
 
-	err := db.Where(&SkillBadge{SkillID: skillID, Name: badgeName}).First(&existingBadge).Error
+	err := db.Where(&SkillBadge{SkillID: skillID, TierID: tierID}).First(&existingBadge).Error
 
 	return err == nil
 }
 
-func AssignBadge(db *gorm.DB, userID string, assessmentID uint) (*UserBadge, error) {
+func ListBadges(db *gorm.DB, skillID uint) ([]SkillBadge, error) {
+	var badges []SkillBadge
 
-	var assessmentTaken UserAssessment
-	var badge SkillBadge
+	query := db.Model(&SkillBadge{})
+	if skillID != 0 {
+		query = query.Where(&SkillBadge{SkillID: skillID})
+	}
+
+	err := query.Preload("Skill").Preload("Tier").Order("skill_id, min_score").Find(&badges).Error
+
+	return badges, err
+}
 
-	err := db.Preload("Assessment").First(&assessmentTaken, assessmentID).Error
+func FindBadgeByID(db *gorm.DB, badgeID uint) (*SkillBadge, error) {
+	var badge SkillBadge
+	err := db.Preload("Skill").Preload("Tier").First(&badge, badgeID).Error
 
 	if err != nil {
 		return nil, err
 	}
 
-	err = db.Where("skill_id = ? AND ? BETWEEN min_score AND max_score", assessmentTaken.Assessment.SkillID, assessmentTaken.Score).First(&badge).Error
+	return &badge, nil
+}
 
+func UpdateBadgeScores(db *gorm.DB, badge *SkillBadge, minScore, maxScore float64) error {
+	err := db.Model(&SkillBadge{ID: badge.ID}).Updates(map[string]interface{}{
+		"min_score": minScore,
+		"max_score": maxScore,
+	}).Error
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if badge.ID == 0 {
-		return nil, fmt.Errorf("badge for this assessmnt does not exist")
-	}
+	badge.MinScore, badge.MaxScore = minScore, maxScore
 
-	newUserBadge := UserBadge{
-		UserID:           userID,
-		BadgeID:          badge.ID,
-		UserAssessmentID: assessmentID,
-		CreatedAt:        time.Now(),
-		UpdatedAt:        time.Now(),
-	}
-	err = db.Create(&newUserBadge).Error
+	return nil
+}
+
+// DeleteBadge removes a badge definition. Definitions that have already been
+// awarded are kept so that existing user badges never point at nothing.
+func DeleteBadge(db *gorm.DB, badgeID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var awarded int64
+		err := tx.Model(&UserBadge{}).Where(&UserBadge{BadgeID: badgeID}).Count(&awarded).Error
+		if err != nil {
+			return err
+		}
+
+		if awarded > 0 {
+			return ErrBadgeInUse
+		}
+
+		return tx.Delete(&SkillBadge{}, badgeID).Error
+	})
+}
+
+// AssignBadge awards the badge matching the score of a user assessment, as
+// decided by DecideAward. A user holds one current badge per skill: a higher
+// tier supersedes it, the previous badge being kept in history, while a lower
+// or equal tier never replaces it. Calling it again for the same assessment
+// returns the existing badge.
+func AssignBadge(db *gorm.DB, userID string, assessmentID uint) (userBadge *UserBadge, outcome AssignOutcome, err error) {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var assessmentTaken UserAssessment
+
+		// Lock the assessment so concurrent requests for it are serialised.
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&assessmentTaken, assessmentID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAssessmentNotFound
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = tx.First(&assessmentTaken.Assessment, assessmentTaken.AssessmentID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAssessmentNotFound
+		}
+
+		if err != nil {
+			return err
+		}
+
+		ladder, err := GetSkillLadder(tx, assessmentTaken.Assessment.SkillID)
+		if err != nil {
+			return err
+		}
+
+		var userBadges []UserBadge
+		err = tx.Preload("Badge.Tier").Where(&UserBadge{UserID: userID}).Order("id").Find(&userBadges).Error
+		if err != nil {
+			return err
+		}
+
+		award, err := DecideAward(assessmentTaken, userID, ladder, userBadges)
+		if err != nil {
+			return err
+		}
+
+		outcome = award.Outcome
+		if !outcome.Awarded() {
+			userBadge, err = findUserBadge(tx, &UserBadge{ID: award.Kept.ID})
+			return err
+		}
+
+		newUserBadge := UserBadge{
+			UserID:           userID,
+			BadgeID:          award.Badge.ID,
+			UserAssessmentID: assessmentID,
+			CreatedAt:        time.Now(),
+			UpdatedAt:        time.Now(),
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&newUserBadge)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		// Awarded concurrently
+		if result.RowsAffected == 0 {
+			outcome = AssignExisting
+			userBadge, err = findHeldUserBadge(tx, userID, award.Badge.ID)
+			return err
+		}
+
+		if award.Superseded != nil {
+			err = tx.Model(&UserBadge{ID: award.Superseded.ID}).Updates(map[string]interface{}{
+				"superseded_at":    newUserBadge.CreatedAt,
+				"superseded_by_id": newUserBadge.ID,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		userBadge, err = findUserBadge(tx, &UserBadge{ID: newUserBadge.ID})
+
+		return err
+	})
 
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	err = db.Preload("UserAssessment").
+	return userBadge, outcome, nil
+}
+
+// findHeldUserBadge returns the unrevoked award of a badge to the user, or
+// nil if there is none. A revoked badge can be earned again.
+func findHeldUserBadge(db *gorm.DB, userID string, badgeID uint) (*UserBadge, error) {
+	return findUserBadge(db.Where("user_badge.revoked_at IS NULL"), &UserBadge{UserID: userID, BadgeID: badgeID})
+}
+
+// findUserBadge returns the user badge matching the conditions with its
+// relations loaded, or nil if there is none.
+func findUserBadge(db *gorm.DB, conds *UserBadge) (*UserBadge, error) {
+	var userBadge UserBadge
+
+	err := db.Preload("UserAssessment").
 		Preload("User").
 		Preload("UserAssessment.Assessment").
 		Preload("Badge").
 		Preload("Badge.Skill").
-		Where(&UserBadge{ID: newUserBadge.ID}).First(&newUserBadge).Error
+		Preload("Badge.Tier").
+		Where(conds).First(&userBadge).Error
 
-	return &newUserBadge, err
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &userBadge, nil
 }
 
 func CheckIfBadgeIsValid(db *gorm.DB, badgeID uint) bool {
@@ -163,25 +383,22 @@ func CheckIfBadgeIsValid(db *gorm.DB, badgeID uint) bool {
 	return err == nil
 }
 
-func VerifyAssessment(db *gorm.DB, asssessmentID uint) bool {
-	var assessment_taken UserAssessment
-
-	err := db.Where(&UserAssessment{ID: asssessmentID}).First(&assessment_taken).Error
+// GetUserBadgeByID returns a badge of the user. Revoked badges are only
+// returned when includeRevoked is set.
+func GetUserBadgeByID(db *gorm.DB, badgeID uint, userID string, includeRevoked bool) (*UserBadge, error) {
+	var badge UserBadge
 
-	if assessment_taken.Status == Pending || assessment_taken.Status == Failed {
-		return false
+	query := db.Where(&UserBadge{ID: badgeID, UserID: userID})
+	if !includeRevoked {
+		query = query.Where("revoked_at IS NULL")
 	}
 
-	return err == nil
-}
-
-func GetUserBadgeByID(db *gorm.DB, badgeID uint, userID string) (*UserBadge, error) {
-	var badge UserBadge
-	result := db.Where(&UserBadge{ID: badgeID, UserID: userID}).
+	result := query.
 		Preload("User").
 		Preload("Badge").
 		Preload("UserAssessment.Assessment").
 		Preload("Badge.Skill").
+		Preload("Badge.Tier").
 		First(&badge)
 
 	if result.Error != nil {
@@ -191,28 +408,63 @@ func GetUserBadgeByID(db *gorm.DB, badgeID uint, userID string) (*UserBadge, err
 	return &badge, nil
 }
 
-func GetUserBadges(db *gorm.DB, userID string, badgeName string) ([]UserBadge, error) {
+type UserBadgeFilter struct {
+	// BadgeName only keeps badges of the tier with this name.
+	BadgeName string
+	// IncludeHistory also returns badges superseded by an upgrade.
+	IncludeHistory bool
+	// IncludeRevoked also returns revoked badges.
+	IncludeRevoked bool
+}
+
+// GetUserBadges lists the badges a user currently holds.
+func GetUserBadges(db *gorm.DB, userID string, filter UserBadgeFilter) ([]UserBadge, error) {
 	var badges []UserBadge
 
-	query := db
-	if badgeName != "" {
-		validBadgeName, err := GetValidBadgeName(badgeName)
-		if err != nil {
-			return nil, err
-		}
-		query = query.Raw("SELECT user_badge.id, user_badge.user_id, user_badge.badge_id, user_badge.user_assessment_id "+
-			"FROM user_badge, skill_badge WHERE skill_badge.id = user_badge.badge_id AND skill_badge.name = ? AND user_badge.user_id = ?",
-			validBadgeName, userID,
-		)
+	query := db.Model(&UserBadge{}).Where(&UserBadge{UserID: userID})
+	if filter.BadgeName != "" {
+		query = query.Joins("JOIN skill_badge ON skill_badge.id = user_badge.badge_id").
+			Where("LOWER(skill_badge.name) = LOWER(?)", filter.BadgeName)
+	}
 
-	} else {
-		query = query.Model(&UserBadge{}).Where(&UserBadge{UserID: userID})
+	if !filter.IncludeHistory {
+		query = query.Where("user_badge.superseded_at IS NULL")
+	}
+
+	if !filter.IncludeRevoked {
+		query = query.Where("user_badge.revoked_at IS NULL")
 	}
 
 	result := query.Preload("UserAssessment").
 		Preload("User").
 		Preload("Badge").
 		Preload("Badge.Skill").
+		Preload("Badge.Tier").
+		Preload("UserAssessment.Assessment").
+		Find(&badges)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return badges, nil
+}
+
+// GetUserBadgesForSkill lists the badges a user holds for a skill, the current
+// one first followed by those it superseded. Revoked badges are left out.
+func GetUserBadgesForSkill(db *gorm.DB, userID string, skillID uint) ([]UserBadge, error) {
+	var badges []UserBadge
+
+	result := db.Model(&UserBadge{}).
+		Joins("JOIN skill_badge ON skill_badge.id = user_badge.badge_id").
+		Where("user_badge.user_id = ? AND skill_badge.skill_id = ?", userID, skillID).
+		Where("user_badge.revoked_at IS NULL").
+		Order("user_badge.created_at DESC").
+		Preload("UserAssessment").
+		Preload("User").
+		Preload("Badge").
+		Preload("Badge.Skill").
+		Preload("Badge.Tier").
 		Preload("UserAssessment.Assessment").
 		Find(&badges)
 
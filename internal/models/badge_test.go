@@ -0,0 +1,69 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecideAward(t *testing.T) {
+	ladder := testLadder()
+	beginner, intermediate := ladder[1], ladder[2]
+
+	taken := testAssessment()
+	taken.ID = 10
+	taken.Score = 60
+
+	award, err := DecideAward(taken, "user-1", ladder, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, AssignCreated, award.Outcome)
+	assert.Equal(t, intermediate.ID, award.Badge.ID)
+
+	held := []UserBadge{{ID: 1, UserAssessmentID: 9, BadgeID: beginner.ID, Badge: &beginner}}
+	award, err = DecideAward(taken, "user-1", ladder, held)
+	assert.NoError(t, err)
+	assert.Equal(t, AssignUpgraded, award.Outcome)
+	assert.Equal(t, uint(1), award.Superseded.ID)
+
+	held = []UserBadge{{ID: 1, UserAssessmentID: 9, BadgeID: intermediate.ID, Badge: &intermediate}}
+	award, err = DecideAward(taken, "user-1", ladder, held)
+	assert.NoError(t, err)
+	assert.Equal(t, AssignExisting, award.Outcome)
+	assert.Equal(t, uint(1), award.Kept.ID)
+
+	taken.Score = 30
+	award, err = DecideAward(taken, "user-1", ladder, held)
+	assert.NoError(t, err)
+	assert.Equal(t, AssignRetained, award.Outcome)
+	assert.Equal(t, uint(1), award.Kept.ID)
+
+	held = append(held, UserBadge{ID: 2, UserAssessmentID: 10, BadgeID: beginner.ID, Badge: &beginner})
+	award, err = DecideAward(taken, "user-1", ladder, held)
+	assert.NoError(t, err)
+	assert.Equal(t, AssignExisting, award.Outcome, "the assessment was already awarded")
+	assert.Equal(t, uint(2), award.Kept.ID)
+
+	_, err = DecideAward(taken, "user-2", ladder, nil)
+	assert.ErrorIs(t, err, ErrAssessmentNotOwned)
+
+	taken.Score = 120
+	_, err = DecideAward(taken, "user-1", ladder, nil)
+	assert.ErrorIs(t, err, ErrNoBadgeForScore)
+}
+
+func TestDecideAward_Revoked(t *testing.T) {
+	ladder := testLadder()
+	intermediate := ladder[2]
+	revokedAt := time.Now()
+
+	taken := testAssessment()
+	taken.ID = 10
+	taken.Score = 60
+
+	held := []UserBadge{{ID: 1, UserAssessmentID: 9, BadgeID: intermediate.ID, Badge: &intermediate, RevokedAt: &revokedAt}}
+	award, err := DecideAward(taken, "user-1", ladder, held)
+	assert.NoError(t, err)
+	assert.Equal(t, AssignCreated, award.Outcome, "a revoked badge can be earned again")
+	assert.Equal(t, intermediate.ID, award.Badge.ID)
+}
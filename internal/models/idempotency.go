@@ -0,0 +1,75 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrIdempotencyKeyExists = errors.New("idempotency key already used")
+
+// IdempotencyKeyTTL is how long a stored response can be replayed.
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// IdempotencyKey stores the response of a request sent with an
+// Idempotency-Key header. A zero StatusCode means the request is still being
+// processed.
+type IdempotencyKey struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Key          string    `json:"key" gorm:"type:varchar(255);uniqueIndex:idx_idempotency_key_user"`
+	UserID       string    `json:"user_id" gorm:"type:varchar(255);uniqueIndex:idx_idempotency_key_user"`
+	Method       string    `json:"method" gorm:"type:varchar(10)"`
+	Path         string    `json:"path"`
+	RequestHash  string    `json:"request_hash" gorm:"type:varchar(64)"`
+	StatusCode   int       `json:"status_code"`
+	ResponseBody string    `json:"response_body"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (k IdempotencyKey) TableName() string {
+	return "idempotency_key"
+}
+
+func (k IdempotencyKey) Expired() bool {
+	return time.Since(k.CreatedAt) > IdempotencyKeyTTL
+}
+
+func FindIdempotencyKey(db *gorm.DB, key string, userID string) (*IdempotencyKey, error) {
+	var record IdempotencyKey
+
+	err := db.Where(&IdempotencyKey{Key: key, UserID: userID}).First(&record).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// ReserveIdempotencyKey records that a request with the key is being
+// processed. It fails with ErrIdempotencyKeyExists if the key is taken.
+func ReserveIdempotencyKey(db *gorm.DB, record *IdempotencyKey) error {
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrIdempotencyKeyExists
+	}
+
+	return nil
+}
+
+func CompleteIdempotencyKey(db *gorm.DB, record *IdempotencyKey, statusCode int, body string) error {
+	return db.Model(record).Updates(map[string]interface{}{
+		"status_code":   statusCode,
+		"response_body": body,
+	}).Error
+}
+
+func DeleteIdempotencyKey(db *gorm.DB, record *IdempotencyKey) error {
+	return db.Delete(record).Error
+}
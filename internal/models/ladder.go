@@ -0,0 +1,198 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+var ErrNoBadgeForScore = errors.New("no badge covers the score of this assessment")
+
+type LadderIssueType string
+
+const (
+	LadderOverlap LadderIssueType = "overlap"
+	LadderGap     LadderIssueType = "gap"
+	LadderOrder   LadderIssueType = "order"
+)
+
+type LadderIssue struct {
+	Type     LadderIssueType `json:"type"`
+	BadgeIDs []uint          `json:"badge_ids"`
+	From     float64         `json:"from"`
+	To       float64         `json:"to"`
+	Message  string          `json:"message"`
+}
+
+// LadderError is returned when a change would leave the ladder of a skill
+// invalid, with the issues ValidateLadder found.
+type LadderError struct {
+	SkillID uint
+	Issues  []LadderIssue
+}
+
+func (e *LadderError) Error() string {
+	return fmt.Sprintf("the badge ladder of skill %d would become invalid", e.SkillID)
+}
+
+type LadderReport struct {
+	SkillID  uint          `json:"skill_id"`
+	Valid    bool          `json:"valid"`
+	MinScore float64       `json:"min_score"`
+	MaxScore float64       `json:"max_score"`
+	Badges   []SkillBadge  `json:"badges"`
+	Issues   []LadderIssue `json:"issues"`
+}
+
+// SortLadder orders badges by score range.
+func SortLadder(badges []SkillBadge) {
+	sort.SliceStable(badges, func(i, j int) bool {
+		if badges[i].MinScore == badges[j].MinScore {
+			return badges[i].MaxScore < badges[j].MaxScore
+		}
+		return badges[i].MinScore < badges[j].MinScore
+	})
+}
+
+// ValidateLadder checks the score ranges of a skill's badges. Ranges are
+// half-open, [min_score, max_score), so adjacent badges share a boundary: a
+// badge ending at 50 and the next one starting at 50 neither overlap nor leave
+// a gap. Gaps are only reported when requireContiguous is set. Badges must
+// also climb the tier ladder as scores increase.
+func ValidateLadder(badges []SkillBadge, requireContiguous bool) []LadderIssue {
+	sorted := make([]SkillBadge, len(badges))
+	copy(sorted, badges)
+	SortLadder(sorted)
+
+	issues := []LadderIssue{}
+	for i := 1; i < len(sorted); i++ {
+		prev, curr := sorted[i-1], sorted[i]
+
+		if curr.MinScore < prev.MaxScore {
+			issues = append(issues, LadderIssue{
+				Type:     LadderOverlap,
+				BadgeIDs: []uint{prev.ID, curr.ID},
+				From:     curr.MinScore,
+				To:       minFloat(prev.MaxScore, curr.MaxScore),
+				Message:  fmt.Sprintf("%s and %s overlap", prev.Name, curr.Name),
+			})
+		}
+
+		if requireContiguous && curr.MinScore > prev.MaxScore {
+			issues = append(issues, LadderIssue{
+				Type:     LadderGap,
+				BadgeIDs: []uint{prev.ID, curr.ID},
+				From:     prev.MaxScore,
+				To:       curr.MinScore,
+				Message:  fmt.Sprintf("no badge covers scores between %s and %s", prev.Name, curr.Name),
+			})
+		}
+
+		if prev.Tier != nil && curr.Tier != nil && curr.Tier.Rank <= prev.Tier.Rank {
+			issues = append(issues, LadderIssue{
+				Type:     LadderOrder,
+				BadgeIDs: []uint{prev.ID, curr.ID},
+				From:     prev.MinScore,
+				To:       curr.MaxScore,
+				Message:  fmt.Sprintf("%s requires higher scores than %s but is not a higher tier", curr.Name, prev.Name),
+			})
+		}
+	}
+
+	return issues
+}
+
+// FindBadgeForScore returns the badge whose range contains the score. The
+// badge with the highest max_score also includes its upper bound so that a
+// perfect score is covered.
+func FindBadgeForScore(badges []SkillBadge, score float64) *SkillBadge {
+	sorted := make([]SkillBadge, len(badges))
+	copy(sorted, badges)
+	SortLadder(sorted)
+
+	for i := len(sorted) - 1; i >= 0; i-- {
+		badge := sorted[i]
+		isTop := i == len(sorted)-1
+
+		if score >= badge.MinScore && (score < badge.MaxScore || (isTop && score == badge.MaxScore)) {
+			return &badge
+		}
+	}
+
+	return nil
+}
+
+func GetSkillLadder(db *gorm.DB, skillID uint) ([]SkillBadge, error) {
+	var badges []SkillBadge
+
+	err := db.Preload("Tier").Where(&SkillBadge{SkillID: skillID}).Find(&badges).Error
+	if err != nil {
+		return nil, err
+	}
+
+	SortLadder(badges)
+
+	return badges, nil
+}
+
+func BuildLadderReport(skillID uint, badges []SkillBadge, requireContiguous bool) LadderReport {
+	report := LadderReport{
+		SkillID: skillID,
+		Badges:  badges,
+		Issues:  ValidateLadder(badges, requireContiguous),
+	}
+
+	for i, badge := range badges {
+		if i == 0 || badge.MinScore < report.MinScore {
+			report.MinScore = badge.MinScore
+		}
+		if i == 0 || badge.MaxScore > report.MaxScore {
+			report.MaxScore = badge.MaxScore
+		}
+	}
+
+	report.Valid = len(report.Issues) == 0
+
+	return report
+}
+
+// ReplaceInLadder returns a copy of the ladder with the badge added, or
+// replacing the badge with the same ID.
+func ReplaceInLadder(ladder []SkillBadge, badge SkillBadge) []SkillBadge {
+	proposed := make([]SkillBadge, 0, len(ladder)+1)
+	for _, existing := range ladder {
+		if badge.ID != 0 && existing.ID == badge.ID {
+			continue
+		}
+		proposed = append(proposed, existing)
+	}
+
+	return append(proposed, badge)
+}
+
+// UpdateLadderScores updates the ranges of several badges of a skill at once,
+// which is needed to move a boundary shared by two badges.
+func UpdateLadderScores(db *gorm.DB, badges []SkillBadge) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, badge := range badges {
+			err := tx.Model(&SkillBadge{ID: badge.ID}).Updates(map[string]interface{}{
+				"min_score": badge.MinScore,
+				"max_score": badge.MaxScore,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testLadder() []SkillBadge {
+	return []SkillBadge{
+		{ID: 3, Name: "Expert", MinScore: 80, MaxScore: 100, Tier: &BadgeTier{Rank: 3}},
+		{ID: 1, Name: "Beginner", MinScore: 0, MaxScore: 50, Tier: &BadgeTier{Rank: 1}},
+		{ID: 2, Name: "Intermediate", MinScore: 50, MaxScore: 80, Tier: &BadgeTier{Rank: 2}},
+	}
+}
+
+func TestValidateLadder_Valid(t *testing.T) {
+	assert.Empty(t, ValidateLadder(testLadder(), true))
+}
+
+func TestValidateLadder_Overlap(t *testing.T) {
+	ladder := testLadder()
+	ladder[2].MinScore = 40
+
+	issues := ValidateLadder(ladder, false)
+	assert.Len(t, issues, 1)
+	assert.Equal(t, LadderOverlap, issues[0].Type)
+	assert.Equal(t, []uint{1, 2}, issues[0].BadgeIDs)
+	assert.Equal(t, 40.0, issues[0].From)
+	assert.Equal(t, 50.0, issues[0].To)
+}
+
+func TestValidateLadder_Gap(t *testing.T) {
+	ladder := testLadder()
+	ladder[2].MinScore = 51
+
+	assert.Empty(t, ValidateLadder(ladder, false))
+
+	issues := ValidateLadder(ladder, true)
+	assert.Len(t, issues, 1)
+	assert.Equal(t, LadderGap, issues[0].Type)
+}
+
+func TestValidateLadder_Order(t *testing.T) {
+	ladder := testLadder()
+	ladder[0].Tier.Rank = 1
+
+	issues := ValidateLadder(ladder, false)
+	assert.NotEmpty(t, issues)
+	assert.Equal(t, LadderOrder, issues[0].Type)
+}
+
+func TestFindBadgeForScore(t *testing.T) {
+	ladder := testLadder()
+
+	tests := map[float64]uint{0: 1, 49.99: 1, 50: 2, 79.5: 2, 80: 3, 100: 3}
+	for score, badgeID := range tests {
+		badge := FindBadgeForScore(ladder, score)
+		if assert.NotNil(t, badge, "score %v", score) {
+			assert.Equal(t, badgeID, badge.ID, "score %v", score)
+		}
+	}
+
+	assert.Nil(t, FindBadgeForScore(ladder, 100.5))
+	assert.Nil(t, FindBadgeForScore(ladder, -1))
+}
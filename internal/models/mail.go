@@ -0,0 +1,169 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MailStatus follows the STATUS enum of the mail_log table. A failed mail has
+// used all its delivery attempts and is only retried when replayed.
+type MailStatus string
+
+const (
+	MailPending  MailStatus = "pending"
+	MailComplete MailStatus = "complete"
+	MailFailed   MailStatus = "failed"
+)
+
+// MailRequestOrigin marks the mail_log rows written by this service.
+const MailRequestOrigin = "badges"
+
+var ErrMailNotReplayable = errors.New("only failed mails can be replayed")
+
+// MailLog is an outbox record: it is written in the same transaction as the
+// change that triggers the mail and delivered in the background. There is one
+// record per notification channel.
+type MailLog struct {
+	ID            uint            `json:"id" gorm:"primaryKey"`
+	Email         string          `json:"email" gorm:"type:varchar(225)"`
+	MessageData   json.RawMessage `json:"message_data" gorm:"type:json;serializer:json"`
+	MessageType   *uint           `json:"message_type,omitempty"`
+	Status        MailStatus      `json:"status" gorm:"type:varchar(20);default:pending;index:idx_mail_log_due"`
+	RequestOrigin string          `json:"request_origin" gorm:"type:varchar(225)"`
+	Channel       string          `json:"channel" gorm:"type:varchar(32)"`
+	UserBadgeID   *uint           `json:"user_badge_id,omitempty"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at" gorm:"index:idx_mail_log_due"`
+	LastError     string          `json:"last_error,omitempty"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+func (m MailLog) TableName() string {
+	return "mail_log"
+}
+
+// EnqueueMail adds a mail to the outbox, to be sent as soon as possible.
+func EnqueueMail(db *gorm.DB, mail *MailLog) error {
+	mail.Status = MailPending
+	mail.RequestOrigin = MailRequestOrigin
+	if mail.NextAttemptAt.IsZero() {
+		mail.NextAttemptAt = time.Now()
+	}
+
+	return db.Create(mail).Error
+}
+
+// ClaimDueMails returns the pending mails due for delivery and pushes their
+// next attempt back by lease, so that other dispatchers skip them while they
+// are being sent.
+func ClaimDueMails(db *gorm.DB, now time.Time, limit int, lease time.Duration) ([]MailLog, error) {
+	var mails []MailLog
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("request_origin = ? AND status = ? AND next_attempt_at <= ?", MailRequestOrigin, MailPending, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&mails).Error
+		if err != nil || len(mails) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(mails))
+		for i, mail := range mails {
+			ids[i] = mail.ID
+		}
+
+		return tx.Model(&MailLog{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+
+	return mails, err
+}
+
+func MarkMailDelivered(db *gorm.DB, mail *MailLog, deliveredAt time.Time) error {
+	return db.Model(&MailLog{ID: mail.ID}).Updates(map[string]interface{}{
+		"status":       MailComplete,
+		"attempts":     mail.Attempts + 1,
+		"delivered_at": deliveredAt,
+		"last_error":   "",
+	}).Error
+}
+
+// MarkMailAttemptFailed records a failed delivery. The mail is retried at
+// nextAttempt, or dead-lettered as failed when nextAttempt is nil.
+func MarkMailAttemptFailed(db *gorm.DB, mail *MailLog, deliveryErr error, nextAttempt *time.Time) error {
+	updates := map[string]interface{}{
+		"attempts":   mail.Attempts + 1,
+		"last_error": deliveryErr.Error(),
+	}
+
+	if nextAttempt == nil {
+		updates["status"] = MailFailed
+	} else {
+		updates["next_attempt_at"] = *nextAttempt
+	}
+
+	return db.Model(&MailLog{ID: mail.ID}).Updates(updates).Error
+}
+
+type MailLogFilter struct {
+	Status MailStatus
+	Limit  int
+	Offset int
+}
+
+func ListMailLogs(db *gorm.DB, filter MailLogFilter) ([]MailLog, error) {
+	var mails []MailLog
+
+	query := db.Where("request_origin = ?", MailRequestOrigin)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	err := query.Order("id desc").Limit(filter.Limit).Offset(filter.Offset).Find(&mails).Error
+
+	return mails, err
+}
+
+func FindMailLog(db *gorm.DB, id uint) (*MailLog, error) {
+	var mail MailLog
+
+	err := db.Where("request_origin = ?", MailRequestOrigin).First(&mail, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &mail, nil
+}
+
+// ReplayMail puts a dead-lettered mail back in the outbox with a fresh set of
+// attempts.
+func ReplayMail(db *gorm.DB, mail *MailLog) error {
+	now := time.Now()
+	result := db.Model(&MailLog{ID: mail.ID}).
+		Where("status = ?", MailFailed).
+		Updates(map[string]interface{}{
+			"status":          MailPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrMailNotReplayable
+	}
+
+	mail.Status = MailPending
+	mail.Attempts = 0
+	mail.NextAttemptAt = now
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package models_test
+
+import (
+	"demerzel-badges/internal/db"
+	"demerzel-badges/internal/db/dbtest"
+	"demerzel-badges/internal/models"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// migratedDB returns the test database with every migration applied.
+func migratedDB(t *testing.T) *gorm.DB {
+	conn := dbtest.Open(t)
+
+	migrator, err := db.NewMigrator(conn)
+	require.NoError(t, err)
+	_, err = migrator.Up()
+	require.NoError(t, err)
+
+	return conn
+}
+
+func enqueueMail(t *testing.T, conn *gorm.DB, at time.Time) models.MailLog {
+	mail := models.MailLog{Email: "sample@example.com", Channel: "messaging", NextAttemptAt: at}
+	require.NoError(t, models.EnqueueMail(conn, &mail))
+
+	return mail
+}
+
+func mailIDs(mails []models.MailLog) []uint {
+	ids := []uint{}
+	for _, mail := range mails {
+		ids = append(ids, mail.ID)
+	}
+
+	return ids
+}
+
+func TestClaimDueMailsSkipsLockedAndLeasedMails(t *testing.T) {
+	conn := migratedDB(t)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	locked := enqueueMail(t, conn, now.Add(-time.Minute))
+	free := enqueueMail(t, conn, now.Add(-time.Second))
+
+	// A dispatcher holding a mail makes the others skip it
+	tx := conn.Begin()
+	require.NoError(t, tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.MailLog{}, locked.ID).Error)
+
+	claimed, err := models.ClaimDueMails(conn, now, 10, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{free.ID}, mailIDs(claimed))
+
+	require.NoError(t, tx.Rollback().Error)
+
+	// Claimed mails are leased, the others are still due
+	claimed, err = models.ClaimDueMails(conn, now.Add(30*time.Second), 10, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{locked.ID}, mailIDs(claimed))
+
+	// and claimed again once their lease ended without a delivery
+	claimed, err = models.ClaimDueMails(conn, now.Add(time.Minute), 10, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{free.ID}, mailIDs(claimed))
+}
+
+func TestMarkMailAttemptFailedAndReplay(t *testing.T) {
+	conn := migratedDB(t)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	mail := enqueueMail(t, conn, now)
+	deliveryErr := errors.New("mail service unavailable")
+
+	next := now.Add(30 * time.Second)
+	require.NoError(t, models.MarkMailAttemptFailed(conn, &mail, deliveryErr, &next))
+
+	retried, err := models.FindMailLog(conn, mail.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.MailPending, retried.Status)
+	assert.Equal(t, 1, retried.Attempts)
+	assert.Equal(t, "mail service unavailable", retried.LastError)
+	assert.WithinDuration(t, next, retried.NextAttemptAt, time.Second)
+
+	require.NoError(t, models.MarkMailAttemptFailed(conn, retried, deliveryErr, nil))
+
+	failed, err := models.FindMailLog(conn, mail.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.MailFailed, failed.Status)
+	assert.Equal(t, 2, failed.Attempts)
+
+	claimed, err := models.ClaimDueMails(conn, now.Add(time.Hour), 10, time.Minute)
+	assert.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	require.NoError(t, models.ReplayMail(conn, failed))
+	assert.ErrorIs(t, models.ReplayMail(conn, failed), models.ErrMailNotReplayable)
+
+	replayed, err := models.FindMailLog(conn, mail.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.MailPending, replayed.Status)
+	assert.Equal(t, 0, replayed.Attempts)
+}
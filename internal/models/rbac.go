@@ -0,0 +1,256 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRoleExists         = errors.New("role with the same name already exists")
+	ErrInvalidPermission  = errors.New("permission names cannot be empty or contain spaces")
+	ErrRoleAlreadyGranted = errors.New("user already has this role")
+)
+
+// RoleWithPermissions is a role and the names of the permissions it grants.
+type RoleWithPermissions struct {
+	Role
+	Permissions []string `json:"permissions"`
+}
+
+// UserGrants are the roles of a user and the permissions granted to them,
+// directly or through their roles.
+type UserGrants struct {
+	UserID      string   `json:"user_id"`
+	Roles       []string `json:"roles"`
+	Direct      []string `json:"direct_permissions"`
+	Permissions []string `json:"permissions"`
+}
+
+// ValidPermissionName reports whether name can be used as a permission.
+func ValidPermissionName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, " \t\r\n")
+}
+
+// FindOrCreatePermission returns the permission with the name, creating it
+// when it does not exist yet.
+func FindOrCreatePermission(db *gorm.DB, name string) (*Permission, error) {
+	if !ValidPermissionName(name) {
+		return nil, ErrInvalidPermission
+	}
+
+	var permission Permission
+	err := db.Where(&Permission{Name: name}).FirstOrCreate(&permission).Error
+
+	return &permission, err
+}
+
+func FindRoleByID(db *gorm.DB, roleID uint) (*Role, error) {
+	var role Role
+	err := db.First(&role, roleID).Error
+
+	return &role, err
+}
+
+// ListRoles returns the roles ordered by name with their permissions.
+func ListRoles(db *gorm.DB) ([]RoleWithPermissions, error) {
+	var roles []Role
+	if err := db.Order("name").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]RoleWithPermissions, len(roles))
+	for i, role := range roles {
+		permissions, err := RolePermissionNames(db, role.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		result[i] = RoleWithPermissions{Role: role, Permissions: permissions}
+	}
+
+	return result, nil
+}
+
+// CreateRole creates a role granting the permissions.
+func CreateRole(db *gorm.DB, name string, permissions []string) (*RoleWithPermissions, error) {
+	role := Role{Name: name}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&Role{}).Where("name = ?", name).Count(&existing).Error; err != nil {
+			return err
+		}
+
+		if existing > 0 {
+			return ErrRoleExists
+		}
+
+		if err := tx.Create(&role).Error; err != nil {
+			return err
+		}
+
+		for _, permission := range permissions {
+			if err := GrantRolePermission(tx, role.ID, permission); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := RolePermissionNames(db, role.ID)
+
+	return &RoleWithPermissions{Role: role, Permissions: names}, err
+}
+
+// DeleteRole deletes the role, its grants and its assignments to users.
+func DeleteRole(db *gorm.DB, roleID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", roleID).Delete(&RolePermission{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("role_id = ?", roleID).Delete(&UserRole{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&Role{}, roleID).Error
+	})
+}
+
+// RolePermissionNames returns the sorted names of the permissions of a role.
+func RolePermissionNames(db *gorm.DB, roleID uint) ([]string, error) {
+	names := []string{}
+	err := db.Model(&Permission{}).
+		Joins("JOIN roles_permissions ON roles_permissions.permission_id = permissions.id").
+		Where("roles_permissions.role_id = ?", roleID).
+		Distinct().
+		Order("permissions.name").
+		Pluck("permissions.name", &names).Error
+
+	return names, err
+}
+
+// GrantRolePermission grants the permission to the role, it does nothing
+// when the role already has it.
+func GrantRolePermission(db *gorm.DB, roleID uint, name string) error {
+	permission, err := FindOrCreatePermission(db, name)
+	if err != nil {
+		return err
+	}
+
+	grant := RolePermission{RoleID: roleID, PermissionId: permission.ID}
+
+	return db.Where(&grant).FirstOrCreate(&grant).Error
+}
+
+func RevokeRolePermission(db *gorm.DB, roleID uint, name string) error {
+	return db.Where("role_id = ? AND permission_id IN (?)", roleID, permissionIDs(db, name)).
+		Delete(&RolePermission{}).Error
+}
+
+// AssignUserRole gives the role to the user.
+func AssignUserRole(db *gorm.DB, userID string, roleID uint) error {
+	var existing int64
+	err := db.Model(&UserRole{}).Where("user_id = ? AND role_id = ?", userID, roleID).Count(&existing).Error
+	if err != nil {
+		return err
+	}
+
+	if existing > 0 {
+		return ErrRoleAlreadyGranted
+	}
+
+	return db.Create(&UserRole{UserID: userID, RoleID: roleID}).Error
+}
+
+func UnassignUserRole(db *gorm.DB, userID string, roleID uint) error {
+	return db.Where("user_id = ? AND role_id = ?", userID, roleID).Delete(&UserRole{}).Error
+}
+
+// GrantUserPermission grants the permission to the user directly, it does
+// nothing when the user already has it.
+func GrantUserPermission(db *gorm.DB, userID string, name string) error {
+	permission, err := FindOrCreatePermission(db, name)
+	if err != nil {
+		return err
+	}
+
+	grant := UserPermission{UserID: userID, PermissionId: permission.ID}
+
+	return db.Where(&grant).FirstOrCreate(&grant).Error
+}
+
+func RevokeUserPermission(db *gorm.DB, userID string, name string) error {
+	return db.Where("user_id = ? AND permission_id IN (?)", userID, permissionIDs(db, name)).
+		Delete(&UserPermission{}).Error
+}
+
+// GetUserGrants resolves the roles of the user and the permissions they have,
+// directly or through their roles.
+func GetUserGrants(db *gorm.DB, userID string) (*UserGrants, error) {
+	grants := UserGrants{UserID: userID, Roles: []string{}, Direct: []string{}}
+
+	err := db.Model(&Role{}).
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Distinct().
+		Order("roles.name").
+		Pluck("roles.name", &grants.Roles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Model(&Permission{}).
+		Joins("JOIN user_permissions ON user_permissions.permission_id = permissions.id").
+		Where("user_permissions.user_id = ?", userID).
+		Distinct().
+		Order("permissions.name").
+		Pluck("permissions.name", &grants.Direct).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var inherited []string
+	err = db.Model(&Permission{}).
+		Joins("JOIN roles_permissions ON roles_permissions.permission_id = permissions.id").
+		Joins("JOIN user_roles ON user_roles.role_id = roles_permissions.role_id").
+		Where("user_roles.user_id = ?", userID).
+		Distinct().
+		Pluck("permissions.name", &inherited).Error
+	if err != nil {
+		return nil, err
+	}
+
+	grants.Permissions = mergeNames(grants.Direct, inherited)
+
+	return &grants, nil
+}
+
+func permissionIDs(db *gorm.DB, name string) *gorm.DB {
+	return db.Model(&Permission{}).Select("id").Where("name = ?", name)
+}
+
+func mergeNames(lists ...[]string) []string {
+	seen := map[string]bool{}
+	merged := []string{}
+
+	for _, list := range lists {
+		for _, name := range list {
+			if !seen[name] {
+				seen[name] = true
+				merged = append(merged, name)
+			}
+		}
+	}
+
+	sort.Strings(merged)
+
+	return merged
+}
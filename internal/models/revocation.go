@@ -0,0 +1,73 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrBadgeAlreadyRevoked = errors.New("badge has already been revoked")
+
+// FindUserBadge returns any user badge by ID, including revoked ones.
+func FindUserBadge(db *gorm.DB, badgeID uint) (*UserBadge, error) {
+	badge, err := findUserBadge(db, &UserBadge{ID: badgeID})
+	if err != nil {
+		return nil, err
+	}
+
+	if badge == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return badge, nil
+}
+
+// RevokeUserBadge revokes a badge. If the badge had superseded another one,
+// that previous badge becomes the user's current badge for the skill again.
+func RevokeUserBadge(db *gorm.DB, badgeID uint, revokedBy string, reason string) (*UserBadge, error) {
+	var revoked *UserBadge
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var badge UserBadge
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&badge, badgeID).Error
+		if err != nil {
+			return err
+		}
+
+		if badge.IsRevoked() {
+			return ErrBadgeAlreadyRevoked
+		}
+
+		wasCurrent := badge.IsCurrent()
+
+		err = tx.Model(&UserBadge{ID: badge.ID}).Updates(map[string]interface{}{
+			"revoked_at":        time.Now(),
+			"revoked_by":        revokedBy,
+			"revocation_reason": reason,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if wasCurrent {
+			err = tx.Model(&UserBadge{}).
+				Where("superseded_by_id = ? AND revoked_at IS NULL", badge.ID).
+				Updates(map[string]interface{}{
+					"superseded_at":    nil,
+					"superseded_by_id": nil,
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		revoked, err = findUserBadge(tx, &UserBadge{ID: badge.ID})
+
+		return err
+	})
+
+	return revoked, err
+}
@@ -0,0 +1,210 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTierInUse  = errors.New("tier is used by existing badges")
+	ErrTierExists = errors.New("tier with the same name or rank already exists in this ladder")
+)
+
+// BadgeTier is one step of a tier ladder. Tiers without a SkillID form the
+// default ladder, used by every skill that does not define its own.
+type BadgeTier struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	SkillID     *uint     `json:"skill_id"`
+	Name        string    `json:"name" gorm:"type:varchar(255);not null"`
+	DisplayName string    `json:"display_name" gorm:"type:varchar(255)"`
+	Description string    `json:"description"`
+	Rank        int       `json:"rank" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (t BadgeTier) TableName() string {
+	return "badge_tier"
+}
+
+var DefaultTiers = []BadgeTier{
+	{Name: "Beginner", DisplayName: "Beginner", Description: "Has a working knowledge of the skill", Rank: 1},
+	{Name: "Intermediate", DisplayName: "Intermediate", Description: "Applies the skill independently", Rank: 2},
+	{Name: "Expert", DisplayName: "Expert", Description: "Has mastered the skill", Rank: 3},
+}
+
+func ladderQuery(db *gorm.DB, skillID uint) *gorm.DB {
+	if skillID == 0 {
+		return db.Model(&BadgeTier{}).Where("skill_id IS NULL")
+	}
+
+	return db.Model(&BadgeTier{}).Where("skill_id = ?", skillID)
+}
+
+// GetTierLadder returns the tiers available to a skill ordered by rank. A
+// skill without its own tiers uses the default ladder.
+func GetTierLadder(db *gorm.DB, skillID uint) ([]BadgeTier, error) {
+	var tiers []BadgeTier
+
+	err := ladderQuery(db, skillID).Order("rank").Find(&tiers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tiers) == 0 && skillID != 0 {
+		return GetTierLadder(db, 0)
+	}
+
+	return tiers, nil
+}
+
+func FindTierByID(db *gorm.DB, tierID uint) (*BadgeTier, error) {
+	var tier BadgeTier
+	err := db.First(&tier, tierID).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &tier, nil
+}
+
+// FindTierByName looks a tier up by name, ignoring case, in the ladder used
+// by the given skill.
+func FindTierByName(db *gorm.DB, skillID uint, name string) (*BadgeTier, error) {
+	ladder, err := GetTierLadder(db, skillID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tier := range ladder {
+		if strings.EqualFold(tier.Name, name) {
+			return &tier, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// InLadder reports whether the tier can be used for badges of the given skill.
+func (t BadgeTier) InLadder(db *gorm.DB, skillID uint) bool {
+	ladder, err := GetTierLadder(db, skillID)
+	if err != nil {
+		return false
+	}
+
+	for _, tier := range ladder {
+		if tier.ID == t.ID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func tierConflicts(db *gorm.DB, tier BadgeTier) (bool, error) {
+	var skillID uint
+	if tier.SkillID != nil {
+		skillID = *tier.SkillID
+	}
+
+	var count int64
+	err := ladderQuery(db, skillID).
+		Where("id <> ?", tier.ID).
+		Where("(LOWER(name) = LOWER(?) OR rank = ?)", tier.Name, tier.Rank).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+func CreateTier(db *gorm.DB, tier BadgeTier) (*BadgeTier, error) {
+	newTier := BadgeTier{
+		SkillID:     tier.SkillID,
+		Name:        tier.Name,
+		DisplayName: tier.DisplayName,
+		Description: tier.Description,
+		Rank:        tier.Rank,
+	}
+
+	if newTier.DisplayName == "" {
+		newTier.DisplayName = newTier.Name
+	}
+
+	conflict, err := tierConflicts(db, newTier)
+	if err != nil {
+		return nil, err
+	}
+
+	if conflict {
+		return nil, ErrTierExists
+	}
+
+	err = db.Create(&newTier).Error
+
+	return &newTier, err
+}
+
+// UpdateTier saves the tier and keeps the denormalised name of its badges in
+// sync. A new rank can reorder the ladders of every skill using the tier, so
+// they are validated again and the update is rolled back with a LadderError
+// when one of them becomes invalid.
+func UpdateTier(db *gorm.DB, tier *BadgeTier, requireContiguous bool) error {
+	conflict, err := tierConflicts(db, *tier)
+	if err != nil {
+		return err
+	}
+
+	if conflict {
+		return ErrTierExists
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(tier).Select("Name", "DisplayName", "Description", "Rank").Updates(tier).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&SkillBadge{}).Where("tier_id = ?", tier.ID).Update("name", tier.Name).Error
+		if err != nil {
+			return err
+		}
+
+		var skillIDs []uint
+		err = tx.Model(&SkillBadge{}).Distinct("skill_id").Where("tier_id = ?", tier.ID).Pluck("skill_id", &skillIDs).Error
+		if err != nil {
+			return err
+		}
+
+		for _, skillID := range skillIDs {
+			ladder, err := GetSkillLadder(tx, skillID)
+			if err != nil {
+				return err
+			}
+
+			if issues := ValidateLadder(ladder, requireContiguous); len(issues) > 0 {
+				return &LadderError{SkillID: skillID, Issues: issues}
+			}
+		}
+
+		return nil
+	})
+}
+
+func DeleteTier(db *gorm.DB, tierID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var used int64
+		err := tx.Model(&SkillBadge{}).Where("tier_id = ?", tierID).Count(&used).Error
+		if err != nil {
+			return err
+		}
+
+		if used > 0 {
+			return ErrTierInUse
+		}
+
+		return tx.Delete(&BadgeTier{}, tierID).Error
+	})
+}
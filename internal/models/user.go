@@ -9,9 +9,9 @@ type User struct {
 	LastName     string    `json:"last_name"`
 	Email        string    `json:"email"`
 	SectionOrder string    `json:"section_order"`
-	Password     string    `json:"password"`
+	Password     string    `json:"-"`
 	ProfilePic   string    `json:"profile_pic"`
-	RefreshToken string    `json:"refresh_token"`
+	RefreshToken string    `json:"-"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -20,13 +20,15 @@ func (u User) TableName() string {
 	return "user"
 }
 
+// The role and permission tables are shared with the auth service, their
+// names and columns follow its schema.
 type Role struct {
 	ID   uint   `json:"id" gorm:"primaryKey"`
 	Name string `json:"name"`
 }
 
 func (r Role) TableName() string {
-	return "role"
+	return "roles"
 }
 
 type UserRole struct {
@@ -34,21 +36,23 @@ type UserRole struct {
 	RoleID    uint      `json:"role_id"`
 	UserID    string    `json:"user_id"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
 
 	User User
 	Role Role
 }
 
+func (uR UserRole) TableName() string {
+	return "user_roles"
+}
+
 type Permission struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
 	Name      string    `json:"name"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
 }
 
 func (p Permission) TableName() string {
-	return "permission"
+	return "permissions"
 }
 
 type UserPermission struct {
@@ -56,14 +60,13 @@ type UserPermission struct {
 	UserID       string    `json:"user_id"`
 	PermissionId uint      `json:"permission_id"`
 	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
 
 	User       User
 	Permission Permission
 }
 
 func (uP UserPermission) TableName() string {
-	return "user_permission"
+	return "user_permissions"
 }
 
 type RolePermission struct {
@@ -71,7 +74,6 @@ type RolePermission struct {
 	RoleID       uint      `json:"role_id"`
 	PermissionId uint      `json:"permission_id"`
 	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
 
 	Role       Role
 	Permission Permission
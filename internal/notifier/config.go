@@ -0,0 +1,125 @@
+package notifier
+
+import (
+	"demerzel-badges/internal/templates"
+	"fmt"
+	"strings"
+)
+
+// Config selects the notification channels, NOTIFIERS, and holds the
+// settings of each one.
+type Config struct {
+	Channels []string
+
+	MessagingURL string
+
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	WebhookURL    string
+	WebhookSecret string
+
+	SlackWebhookURL   string
+	DiscordWebhookURL string
+}
+
+// ParseChannels returns the channels listed in a NOTIFIERS setting, the
+// messaging service when it is empty. NOTIFIERS=none disables notifications.
+func ParseChannels(setting string) []string {
+	setting = strings.TrimSpace(setting)
+	if setting == "" {
+		return []string{"messaging"}
+	}
+
+	var channels []string
+	for _, channel := range strings.Split(setting, ",") {
+		channel = strings.ToLower(strings.TrimSpace(channel))
+		if channel != "" && channel != "none" {
+			channels = append(channels, channel)
+		}
+	}
+
+	return channels
+}
+
+// Validate returns the problems of every configured channel.
+func (c Config) Validate() error {
+	var errs Errors
+
+	for _, channel := range c.Channels {
+		if err := c.validate(channel); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (c Config) validate(channel string) error {
+	switch channel {
+	case "messaging":
+		if c.MessagingURL == "" {
+			return fmt.Errorf("messaging: MESSAGING_API_URL is required")
+		}
+	case "smtp":
+		if c.SMTPHost == "" || c.SMTPFrom == "" {
+			return fmt.Errorf("smtp: SMTP_HOST and SMTP_FROM are required")
+		}
+	case "webhook":
+		if c.WebhookURL == "" {
+			return fmt.Errorf("webhook: NOTIFY_WEBHOOK_URL is required")
+		}
+	case "slack":
+		if c.SlackWebhookURL == "" {
+			return fmt.Errorf("slack: SLACK_WEBHOOK_URL is required")
+		}
+	case "discord":
+		if c.DiscordWebhookURL == "" {
+			return fmt.Errorf("discord: DISCORD_WEBHOOK_URL is required")
+		}
+	default:
+		return fmt.Errorf("unknown notifier %q", channel)
+	}
+
+	return nil
+}
+
+// New creates the notifiers of the configured channels, the emails are
+// rendered by renderer.
+func New(cfg Config, renderer *templates.Renderer) (Multi, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	var notifiers Multi
+	for _, channel := range cfg.Channels {
+		switch channel {
+		case "messaging":
+			notifiers = append(notifiers, NewMessaging(cfg.MessagingURL, renderer))
+		case "smtp":
+			notifiers = append(notifiers, &SMTP{
+				Host:      cfg.SMTPHost,
+				Port:      cfg.SMTPPort,
+				Username:  cfg.SMTPUsername,
+				Password:  cfg.SMTPPassword,
+				From:      cfg.SMTPFrom,
+				Templates: renderer,
+			})
+		case "webhook":
+			notifiers = append(notifiers, NewWebhook(cfg.WebhookURL, cfg.WebhookSecret))
+		case "slack":
+			notifiers = append(notifiers, NewSlack(cfg.SlackWebhookURL))
+		case "discord":
+			notifiers = append(notifiers, NewDiscord(cfg.DiscordWebhookURL))
+		}
+	}
+
+	return notifiers, nil
+}
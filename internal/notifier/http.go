@@ -0,0 +1,173 @@
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"demerzel-badges/internal/templates"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+func newClient() *resty.Client {
+	return resty.New().SetTimeout(10 * time.Second)
+}
+
+func postJSON(ctx context.Context, client *resty.Client, url string, body []byte, headers map[string]string) error {
+	res, err := client.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetHeaders(headers).
+		SetBody(body).
+		Post(url)
+	if err != nil {
+		return err
+	}
+
+	if !res.IsSuccess() {
+		return fmt.Errorf("%s responded with %d: %s", url, res.StatusCode(), res.String())
+	}
+
+	return nil
+}
+
+// Messaging sends the award email through the portfolio messaging service.
+// The email is rendered from the local templates, like the SMTP one, and sent
+// along with the fields the service used to build it from.
+type Messaging struct {
+	URL       string
+	Templates *templates.Renderer
+	client    *resty.Client
+}
+
+func NewMessaging(url string, renderer *templates.Renderer) *Messaging {
+	return &Messaging{URL: url, Templates: renderer, client: newClient()}
+}
+
+func (m *Messaging) Name() string {
+	return "messaging"
+}
+
+func (m *Messaging) Notify(ctx context.Context, event Event) error {
+	email, err := renderEmail(m.Templates, event)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"recipient":         event.Recipient.Email,
+		"name":              event.Recipient.Name,
+		"skill":             event.Skill,
+		"badge_name":        event.BadgeName,
+		"user_profile_link": event.ProfileLink,
+		"subject":           email.Subject,
+		"html":              email.HTML,
+		"text":              email.Text,
+	})
+	if err != nil {
+		return err
+	}
+
+	return postJSON(ctx, m.client, m.URL, body, nil)
+}
+
+// Webhook posts the event as JSON. When a secret is set, the body is signed
+// with HMAC-SHA256 in the X-Badges-Signature header.
+type Webhook struct {
+	URL    string
+	Secret string
+	client *resty.Client
+}
+
+func NewWebhook(url string, secret string) *Webhook {
+	return &Webhook{URL: url, Secret: secret, client: newClient()}
+}
+
+func (w *Webhook) Name() string {
+	return "webhook"
+}
+
+func (w *Webhook) Notify(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{"X-Badges-Event": event.Type}
+	if w.Secret != "" {
+		headers["X-Badges-Signature"] = "sha256=" + Sign(w.Secret, body)
+	}
+
+	return postJSON(ctx, w.client, w.URL, body, headers)
+}
+
+// Sign is the hex HMAC-SHA256 of a webhook body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Slack posts to a Slack incoming webhook.
+type Slack struct {
+	WebhookURL string
+	client     *resty.Client
+}
+
+func NewSlack(webhookURL string) *Slack {
+	return &Slack{WebhookURL: webhookURL, client: newClient()}
+}
+
+func (s *Slack) Name() string {
+	return "slack"
+}
+
+func (s *Slack) Notify(ctx context.Context, event Event) error {
+	text := ":medal: " + event.Summary()
+	if event.ProfileLink != "" {
+		text += fmt.Sprintf(" (<%s|portfolio>)", event.ProfileLink)
+	}
+
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+
+	return postJSON(ctx, s.client, s.WebhookURL, body, nil)
+}
+
+// Discord posts to a Discord channel webhook.
+type Discord struct {
+	WebhookURL string
+	client     *resty.Client
+}
+
+func NewDiscord(webhookURL string) *Discord {
+	return &Discord{WebhookURL: webhookURL, client: newClient()}
+}
+
+func (d *Discord) Name() string {
+	return "discord"
+}
+
+func (d *Discord) Notify(ctx context.Context, event Event) error {
+	content := ":medal: " + event.Summary()
+	if event.ProfileLink != "" {
+		content += " " + event.ProfileLink
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"content":          content,
+		"allowed_mentions": map[string]interface{}{"parse": []string{}},
+	})
+	if err != nil {
+		return err
+	}
+
+	return postJSON(ctx, d.client, d.WebhookURL, body, nil)
+}
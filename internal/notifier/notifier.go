@@ -0,0 +1,110 @@
+// Package notifier tells users and teams about badge events through the
+// configured channels: the messaging service, SMTP, webhooks, Slack and
+// Discord.
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	EventBadgeAwarded  = "badge.awarded"
+	EventBadgeUpgraded = "badge.upgraded"
+)
+
+type Recipient struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+	Name   string `json:"name"`
+}
+
+// Event is a badge event, as stored in the outbox and sent to webhooks.
+type Event struct {
+	Type             string    `json:"type"`
+	Recipient        Recipient `json:"recipient"`
+	UserBadgeID      uint      `json:"user_badge_id"`
+	BadgeID          uint      `json:"badge_id"`
+	Skill            string    `json:"skill"`
+	BadgeName        string    `json:"badge_name"`
+	ProfileLink      string    `json:"profile_link"`
+	VerificationLink string    `json:"verification_link,omitempty"`
+	ImageLink        string    `json:"image_link,omitempty"`
+	Locale           string    `json:"locale,omitempty"`
+	OccurredAt       time.Time `json:"occurred_at"`
+}
+
+// Summary is a one line description of the event for chat channels.
+func (e Event) Summary() string {
+	verb := "earned"
+	if e.Type == EventBadgeUpgraded {
+		verb = "was upgraded to"
+	}
+
+	name := e.Recipient.Name
+	if name == "" {
+		name = e.Recipient.Email
+	}
+
+	return fmt.Sprintf("%s %s the %s badge in %s", name, verb, e.BadgeName, e.Skill)
+}
+
+// Notifier delivers events through one channel.
+type Notifier interface {
+	// Name is the channel name used in the NOTIFIERS setting and the outbox.
+	Name() string
+	Notify(ctx context.Context, event Event) error
+}
+
+// Multi fans events out to several notifiers.
+type Multi []Notifier
+
+func (m Multi) Name() string {
+	names := make([]string, len(m))
+	for i, n := range m {
+		names[i] = n.Name()
+	}
+
+	return strings.Join(names, ",")
+}
+
+// Notify sends the event through every notifier, even if some fail.
+func (m Multi) Notify(ctx context.Context, event Event) error {
+	var errs Errors
+	for _, n := range m {
+		if err := n.Notify(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+// Get returns the notifier of a channel, nil if it is not configured.
+func (m Multi) Get(name string) Notifier {
+	for _, n := range m {
+		if n.Name() == name {
+			return n
+		}
+	}
+
+	return nil
+}
+
+// Errors gathers the failures of several notifiers.
+type Errors []error
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,236 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"demerzel-badges/internal/templates"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var event = Event{
+	Type:        EventBadgeAwarded,
+	Recipient:   Recipient{UserID: "u1", Email: "jane@example.com", Name: "Jane"},
+	UserBadgeID: 12,
+	BadgeID:     3,
+	Skill:       "Backend",
+	BadgeName:   "Expert",
+	ProfileLink: "https://zuri.team/portfolio/u1",
+	OccurredAt:  time.Date(2023, 9, 20, 17, 28, 42, 0, time.UTC),
+}
+
+// recorder is an HTTP stand-in that keeps the last request it received.
+type recorder struct {
+	status  int
+	body    []byte
+	headers http.Header
+}
+
+func (r *recorder) server(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.body, _ = io.ReadAll(req.Body)
+		r.headers = req.Header
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func decode(t *testing.T, body []byte) map[string]interface{} {
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(body, &decoded))
+
+	return decoded
+}
+
+func TestMessaging(t *testing.T) {
+	r := &recorder{status: http.StatusOK}
+	server := r.server(t)
+
+	messaging := NewMessaging(server.URL, templates.New("", ""))
+	assert.NoError(t, messaging.Notify(context.Background(), event))
+
+	body := decode(t, r.body)
+	assert.Equal(t, "jane@example.com", body["recipient"])
+	assert.Equal(t, "Jane", body["name"])
+	assert.Equal(t, "Backend", body["skill"])
+	assert.Equal(t, "Expert", body["badge_name"])
+	assert.Equal(t, "https://zuri.team/portfolio/u1", body["user_profile_link"])
+	assert.Equal(t, "You are now a Backend expert", body["subject"])
+	assert.Contains(t, body["html"], "https://zuri.team/portfolio/u1")
+	assert.Contains(t, body["text"], "https://zuri.team/portfolio/u1")
+
+	r.status = http.StatusBadGateway
+	assert.Error(t, messaging.Notify(context.Background(), event))
+}
+
+func TestWebhook(t *testing.T) {
+	r := &recorder{status: http.StatusAccepted}
+	server := r.server(t)
+
+	assert.NoError(t, NewWebhook(server.URL, "secret").Notify(context.Background(), event))
+	assert.Equal(t, "badge.awarded", r.headers.Get("X-Badges-Event"))
+	assert.Equal(t, "sha256="+Sign("secret", r.body), r.headers.Get("X-Badges-Signature"))
+
+	var received Event
+	assert.NoError(t, json.Unmarshal(r.body, &received))
+	assert.Equal(t, event, received)
+
+	assert.NoError(t, NewWebhook(server.URL, "").Notify(context.Background(), event))
+	assert.Empty(t, r.headers.Get("X-Badges-Signature"))
+}
+
+func TestSlack(t *testing.T) {
+	r := &recorder{status: http.StatusOK}
+	server := r.server(t)
+
+	assert.NoError(t, NewSlack(server.URL).Notify(context.Background(), event))
+	assert.Equal(t, ":medal: Jane earned the Expert badge in Backend (<https://zuri.team/portfolio/u1|portfolio>)", decode(t, r.body)["text"])
+}
+
+func TestDiscord(t *testing.T) {
+	r := &recorder{status: http.StatusNoContent}
+	server := r.server(t)
+
+	upgraded := event
+	upgraded.Type = EventBadgeUpgraded
+	upgraded.ProfileLink = ""
+
+	assert.NoError(t, NewDiscord(server.URL).Notify(context.Background(), upgraded))
+	assert.Equal(t, ":medal: Jane was upgraded to the Expert badge in Backend", decode(t, r.body)["content"])
+}
+
+// fakeSMTP accepts one message and sends its DATA on the returned channel.
+func fakeSMTP(t *testing.T) (string, string, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+		reply("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"):
+				reply("250 localhost")
+			case command == "DATA":
+				reply("354 go ahead")
+
+				var data strings.Builder
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+
+				messages <- data.String()
+				reply("250 queued")
+			case command == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	return host, port, messages
+}
+
+func TestSMTP(t *testing.T) {
+	host, port, messages := fakeSMTP(t)
+
+	smtp := &SMTP{Host: host, Port: port, From: "badges@zuri.team", Templates: templates.New("", "")}
+	assert.NoError(t, smtp.Notify(context.Background(), event))
+
+	message := <-messages
+	assert.Contains(t, message, "To: jane@example.com\r\n")
+	assert.Contains(t, message, "Subject: You are now a Backend expert\r\n")
+	assert.Contains(t, message, "Content-Type: multipart/alternative")
+	assert.Contains(t, message, "https://zuri.team/portfolio/u1")
+
+	noRecipient := event
+	noRecipient.Recipient.Email = ""
+	assert.Error(t, smtp.Notify(context.Background(), noRecipient))
+}
+
+type failing struct {
+	name  string
+	calls int
+}
+
+func (f *failing) Name() string { return f.name }
+
+func (f *failing) Notify(ctx context.Context, event Event) error {
+	f.calls++
+	return errors.New("unavailable")
+}
+
+func TestMulti(t *testing.T) {
+	r := &recorder{status: http.StatusOK}
+	server := r.server(t)
+
+	broken := &failing{name: "broken"}
+	multi := Multi{broken, NewSlack(server.URL)}
+
+	err := multi.Notify(context.Background(), event)
+	assert.EqualError(t, err, "broken: unavailable")
+	assert.Equal(t, 1, broken.calls)
+	assert.NotEmpty(t, r.body, "a failing notifier should not stop the others")
+
+	assert.Equal(t, "broken,slack", multi.Name())
+	assert.Equal(t, broken, multi.Get("broken"))
+	assert.Nil(t, multi.Get("discord"))
+}
+
+func TestNew(t *testing.T) {
+	assert.Equal(t, []string{"messaging"}, ParseChannels(""))
+	assert.Empty(t, ParseChannels("none"))
+
+	cfg := Config{
+		Channels:        ParseChannels(" Messaging, slack,discord "),
+		MessagingURL:    "https://messaging.zuri.team",
+		SlackWebhookURL: "https://hooks.slack.com/services/x",
+	}
+
+	_, err := New(cfg, nil)
+	assert.EqualError(t, err, "discord: DISCORD_WEBHOOK_URL is required")
+
+	cfg.DiscordWebhookURL = "https://discord.com/api/webhooks/x"
+	notifiers, err := New(cfg, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "messaging,slack,discord", notifiers.Name())
+
+	cfg.Channels = ParseChannels("pigeon,webhook")
+	err = cfg.Validate()
+	assert.EqualError(t, err, `unknown notifier "pigeon"; webhook: NOTIFY_WEBHOOK_URL is required`)
+}
@@ -0,0 +1,155 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"demerzel-badges/internal/templates"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTP sends the award email directly through a mail server, rendered from
+// the local templates.
+type SMTP struct {
+	Host      string
+	Port      string
+	Username  string
+	Password  string
+	From      string
+	Templates *templates.Renderer
+}
+
+func (s *SMTP) Name() string {
+	return "smtp"
+}
+
+func (s *SMTP) Notify(ctx context.Context, event Event) error {
+	if event.Recipient.Email == "" {
+		return fmt.Errorf("event has no recipient email")
+	}
+
+	message, err := s.message(event)
+	if err != nil {
+		return err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(s.Host, s.Port))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.Host}); err != nil {
+			return err
+		}
+	}
+
+	if s.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(s.From); err != nil {
+		return err
+	}
+
+	if err := client.Rcpt(event.Recipient.Email); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := writer.Write(message); err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (s *SMTP) message(event Event) ([]byte, error) {
+	email, err := renderEmail(s.Templates, event)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildMessage(s.From, event.Recipient.Email, email.Subject, email.Text, email.HTML)
+}
+
+// renderEmail renders the email of an event from the template of its type,
+// in the locale of the recipient and the variant of its badge.
+func renderEmail(renderer *templates.Renderer, event Event) (templates.Email, error) {
+	return renderer.Render(TemplateName(event), templates.BadgeEmail{
+		Name:             event.Recipient.Name,
+		Skill:            event.Skill,
+		BadgeName:        event.BadgeName,
+		ProfileLink:      event.ProfileLink,
+		VerificationLink: event.VerificationLink,
+		ImageLink:        event.ImageLink,
+	}, templates.Options{
+		Locale: event.Locale,
+		Tier:   event.BadgeName,
+		Skill:  event.Skill,
+	})
+}
+
+// TemplateName is the email template of an event, badge_awarded for
+// badge.awarded.
+func TemplateName(event Event) string {
+	return strings.ReplaceAll(event.Type, ".", "_")
+}
+
+// buildMessage writes a MIME message with a plain text body and, when html
+// is not empty, an HTML alternative.
+func buildMessage(from string, to string, subject string, text string, html string) ([]byte, error) {
+	for _, header := range []string{from, to} {
+		if strings.ContainsAny(header, "\r\n") {
+			return nil, fmt.Errorf("invalid address %q", header)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if html == "" {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+		buf.WriteString(text)
+		return buf.Bytes(), nil
+	}
+
+	const boundary = "badges-alternative-boundary"
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+	fmt.Fprintf(&buf, "--%s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n", boundary, text)
+	fmt.Fprintf(&buf, "--%s\r\nContent-Type: text/html; charset=utf-8\r\n\r\n%s\r\n", boundary, html)
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes(), nil
+}
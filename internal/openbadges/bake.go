@@ -0,0 +1,298 @@
+package openbadges
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"hash/crc32"
+	"io"
+	"strings"
+)
+
+// Baking embeds an assertion in the badge image itself, as described in
+// https://www.imsglobal.org/sites/default/files/Badges/OBv2p0Final/baking/index.html
+
+const (
+	bakeKeyword  = "openbadges"
+	svgNamespace = "http://openbadges.org"
+
+	// maxBakedContentSize bounds the decompressed content of a PNG, which
+	// would otherwise be free to expand without limit.
+	maxBakedContentSize = 1 << 20
+)
+
+var (
+	ErrNotBaked         = errors.New("image has no embedded assertion")
+	ErrAlreadyBaked     = errors.New("image already has an embedded assertion")
+	ErrUnsupportedImage = errors.New("image should be a PNG or an SVG")
+	ErrInvalidImage     = errors.New("image is corrupt")
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// BakePNG adds an iTXt chunk holding content, the assertion URL or JSON,
+// right after the IHDR chunk of the image.
+func BakePNG(image []byte, content []byte) ([]byte, error) {
+	chunks, err := pngChunks(image)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := bakedPNGContent(chunks); err == nil {
+		return nil, ErrAlreadyBaked
+	}
+
+	// keyword, null separator, no compression, no language tag and no
+	// translated keyword.
+	data := append([]byte(bakeKeyword), 0, 0, 0, 0, 0)
+	data = append(data, content...)
+
+	var buf bytes.Buffer
+	buf.Write(pngSignature)
+	for i, chunk := range chunks {
+		writePNGChunk(&buf, chunk.kind, chunk.data)
+		if i == 0 {
+			writePNGChunk(&buf, "iTXt", data)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnbakePNG returns the content of the openbadges iTXt (or legacy tEXt) chunk.
+func UnbakePNG(image []byte) ([]byte, error) {
+	chunks, err := pngChunks(image)
+	if err != nil {
+		return nil, err
+	}
+
+	return bakedPNGContent(chunks)
+}
+
+type pngChunk struct {
+	kind string
+	data []byte
+}
+
+func pngChunks(image []byte) ([]pngChunk, error) {
+	if !bytes.HasPrefix(image, pngSignature) {
+		return nil, ErrUnsupportedImage
+	}
+
+	var chunks []pngChunk
+	rest := image[len(pngSignature):]
+	for len(rest) > 0 {
+		if len(rest) < 12 {
+			return nil, ErrInvalidImage
+		}
+
+		length := binary.BigEndian.Uint32(rest[:4])
+		if uint64(length) > uint64(len(rest)-12) {
+			return nil, ErrInvalidImage
+		}
+
+		kind := string(rest[4:8])
+		data := rest[8 : 8+length]
+		if crc32.ChecksumIEEE(rest[4:8+length]) != binary.BigEndian.Uint32(rest[8+length:12+length]) {
+			return nil, ErrInvalidImage
+		}
+
+		chunks = append(chunks, pngChunk{kind: kind, data: data})
+		rest = rest[12+length:]
+
+		if kind == "IEND" {
+			break
+		}
+	}
+
+	if len(chunks) == 0 || chunks[0].kind != "IHDR" {
+		return nil, ErrInvalidImage
+	}
+
+	return chunks, nil
+}
+
+func writePNGChunk(w io.Writer, kind string, data []byte) {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header[:4], uint32(len(data)))
+	copy(header[4:], kind)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+
+	w.Write(header)
+	w.Write(data)
+	binary.Write(w, binary.BigEndian, crc.Sum32())
+}
+
+func bakedPNGContent(chunks []pngChunk) ([]byte, error) {
+	for _, chunk := range chunks {
+		keyword, rest, found := bytes.Cut(chunk.data, []byte{0})
+		if !found || string(keyword) != bakeKeyword {
+			continue
+		}
+
+		switch chunk.kind {
+		case "tEXt":
+			return rest, nil
+		case "iTXt":
+			if len(rest) < 2 {
+				return nil, ErrInvalidImage
+			}
+
+			compressed := rest[0] == 1
+			// Skip the language tag and the translated keyword.
+			parts := bytes.SplitN(rest[2:], []byte{0}, 3)
+			if len(parts) != 3 {
+				return nil, ErrInvalidImage
+			}
+
+			if !compressed {
+				return parts[2], nil
+			}
+
+			reader, err := zlib.NewReader(bytes.NewReader(parts[2]))
+			if err != nil {
+				return nil, ErrInvalidImage
+			}
+			defer reader.Close()
+
+			content, err := io.ReadAll(io.LimitReader(reader, maxBakedContentSize+1))
+			if err != nil || len(content) > maxBakedContentSize {
+				return nil, ErrInvalidImage
+			}
+
+			return content, nil
+		}
+	}
+
+	return nil, ErrNotBaked
+}
+
+// BakeSVG adds an openbadges:assertion element to the root of the image.
+// verify is the assertion URL, assertion the optional assertion JSON.
+func BakeSVG(image []byte, verify string, assertion []byte) ([]byte, error) {
+	if _, err := UnbakeSVG(image); err == nil {
+		return nil, ErrAlreadyBaked
+	}
+
+	start := bytes.Index(image, []byte("<svg"))
+	if start < 0 {
+		return nil, ErrUnsupportedImage
+	}
+
+	end := bytes.IndexByte(image[start:], '>')
+	if end < 0 {
+		return nil, ErrInvalidImage
+	}
+	end += start
+
+	tag := image[start:end]
+	selfClosing := bytes.HasSuffix(tag, []byte("/"))
+	tag = bytes.TrimSuffix(tag, []byte("/"))
+
+	var buf bytes.Buffer
+	buf.Write(image[:start])
+	buf.Write(tag)
+	if !bytes.Contains(tag, []byte("xmlns:openbadges=")) {
+		buf.WriteString(` xmlns:openbadges="` + svgNamespace + `"`)
+	}
+	buf.WriteString(">\n  <openbadges:assertion verify=\"")
+	xml.EscapeText(&buf, []byte(verify))
+	buf.WriteString(`">`)
+	if len(assertion) > 0 {
+		buf.WriteString("<![CDATA[")
+		buf.Write(bytes.ReplaceAll(assertion, []byte("]]>"), []byte("]]]]><![CDATA[>")))
+		buf.WriteString("]]>")
+	}
+	buf.WriteString("</openbadges:assertion>")
+
+	if selfClosing {
+		buf.WriteString("</svg>")
+		buf.Write(image[end+1:])
+	} else {
+		buf.Write(image[end+1:])
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnbakeSVG returns the assertion JSON embedded in the image, or the URL of
+// the assertion when only that is embedded.
+func UnbakeSVG(image []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(image))
+	decoder.Strict = false
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, ErrNotBaked
+		}
+
+		if err != nil {
+			return nil, ErrInvalidImage
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok || element.Name.Space != svgNamespace || element.Name.Local != "assertion" {
+			continue
+		}
+
+		var content struct {
+			Verify string `xml:"verify,attr"`
+			Text   string `xml:",chardata"`
+		}
+		if err := decoder.DecodeElement(&content, &element); err != nil {
+			return nil, ErrInvalidImage
+		}
+
+		if text := strings.TrimSpace(content.Text); text != "" {
+			return []byte(text), nil
+		}
+
+		if content.Verify == "" {
+			return nil, ErrNotBaked
+		}
+
+		return []byte(content.Verify), nil
+	}
+}
+
+// Unbake extracts the embedded content from a PNG or an SVG image.
+func Unbake(image []byte) ([]byte, string, error) {
+	if bytes.HasPrefix(image, pngSignature) {
+		content, err := UnbakePNG(image)
+		return content, "png", err
+	}
+
+	if bytes.Contains(image, []byte("<svg")) {
+		content, err := UnbakeSVG(image)
+		return content, "svg", err
+	}
+
+	return nil, "", ErrUnsupportedImage
+}
+
+// ParseBaked reads unbaked content, which is either the URL of a hosted
+// assertion or the assertion itself.
+func ParseBaked(content []byte) (string, *Assertion, error) {
+	content = bytes.TrimSpace(content)
+	if !bytes.HasPrefix(content, []byte("{")) {
+		return string(content), nil, nil
+	}
+
+	var assertion Assertion
+	if err := json.Unmarshal(content, &assertion); err != nil {
+		return "", nil, err
+	}
+
+	if assertion.ID == "" {
+		return "", nil, errors.New("embedded assertion has no id")
+	}
+
+	return assertion.ID, &assertion, nil
+}
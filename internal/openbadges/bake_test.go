@@ -0,0 +1,125 @@
+package openbadges
+
+import (
+	"bytes"
+	"compress/zlib"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testPNG(t *testing.T) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))))
+
+	return buf.Bytes()
+}
+
+func TestBakePNG(t *testing.T) {
+	content := []byte(`{"id":"https://badges.example.com/api/badges/openbadges/assertions/12"}`)
+
+	baked, err := BakePNG(testPNG(t), content)
+	assert.NoError(t, err)
+
+	_, err = png.Decode(bytes.NewReader(baked))
+	assert.NoError(t, err, "baked image should remain a valid PNG")
+
+	unbaked, format, err := Unbake(baked)
+	assert.NoError(t, err)
+	assert.Equal(t, "png", format)
+	assert.Equal(t, content, unbaked)
+
+	_, err = BakePNG(baked, content)
+	assert.ErrorIs(t, err, ErrAlreadyBaked)
+
+	_, err = UnbakePNG(testPNG(t))
+	assert.ErrorIs(t, err, ErrNotBaked)
+
+	corrupt := append([]byte{}, baked...)
+	corrupt[len(corrupt)-20] ^= 0xff
+	_, err = UnbakePNG(corrupt)
+	assert.ErrorIs(t, err, ErrInvalidImage)
+}
+
+func TestUnbakePNGLimitsCompressedContent(t *testing.T) {
+	bake := func(size int) []byte {
+		var compressed bytes.Buffer
+		w := zlib.NewWriter(&compressed)
+		w.Write(bytes.Repeat([]byte(" "), size))
+		w.Close()
+
+		chunks, err := pngChunks(testPNG(t))
+		assert.NoError(t, err)
+
+		// compressed, no language tag and no translated keyword
+		data := append([]byte(bakeKeyword), 0, 1, 0, 0, 0)
+		data = append(data, compressed.Bytes()...)
+
+		var buf bytes.Buffer
+		buf.Write(pngSignature)
+		for i, chunk := range chunks {
+			writePNGChunk(&buf, chunk.kind, chunk.data)
+			if i == 0 {
+				writePNGChunk(&buf, "iTXt", data)
+			}
+		}
+
+		return buf.Bytes()
+	}
+
+	content, err := UnbakePNG(bake(maxBakedContentSize))
+	assert.NoError(t, err)
+	assert.Len(t, content, maxBakedContentSize)
+
+	_, err = UnbakePNG(bake(maxBakedContentSize + 1))
+	assert.ErrorIs(t, err, ErrInvalidImage)
+}
+
+func TestBakeSVG(t *testing.T) {
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="4" height="4"><rect width="4" height="4"/></svg>`)
+	url := "https://badges.example.com/api/badges/openbadges/assertions/12"
+	assertion := []byte(`{"id":"` + url + `","recipient":{"identity":"a]]>b"}}`)
+
+	baked, err := BakeSVG(svg, url, assertion)
+	assert.NoError(t, err)
+	assert.Contains(t, string(baked), `xmlns:openbadges="http://openbadges.org"`)
+
+	unbaked, format, err := Unbake(baked)
+	assert.NoError(t, err)
+	assert.Equal(t, "svg", format)
+	assert.Equal(t, assertion, unbaked)
+
+	_, err = BakeSVG(baked, url, assertion)
+	assert.ErrorIs(t, err, ErrAlreadyBaked)
+
+	urlOnly, err := BakeSVG([]byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`), url, nil)
+	assert.NoError(t, err)
+
+	unbaked, err = UnbakeSVG(urlOnly)
+	assert.NoError(t, err)
+	assert.Equal(t, url, string(unbaked))
+
+	_, _, err = Unbake([]byte("GIF89a"))
+	assert.ErrorIs(t, err, ErrUnsupportedImage)
+}
+
+func TestParseBaked(t *testing.T) {
+	id, assertion, err := ParseBaked([]byte(" https://badges.example.com/api/badges/openbadges/assertions/12\n"))
+	assert.NoError(t, err)
+	assert.Nil(t, assertion)
+	assert.Equal(t, "https://badges.example.com/api/badges/openbadges/assertions/12", id)
+
+	userBadgeID, ok := builder.AssertionIDFromURL(id)
+	assert.True(t, ok)
+	assert.Equal(t, uint(12), userBadgeID)
+
+	_, ok = builder.AssertionIDFromURL("https://elsewhere.example.com/openbadges/assertions/12")
+	assert.False(t, ok)
+
+	id, assertion, err = ParseBaked([]byte(`{"type":"Assertion","id":"urn:uuid:1","badge":"b"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "urn:uuid:1", id)
+	assert.Equal(t, "b", assertion.Badge)
+}
@@ -0,0 +1,216 @@
+// Package openbadges builds Open Badges 2.0 documents from the badges
+// service models so that they can be hosted and imported into backpacks.
+package openbadges
+
+import (
+	"crypto/sha256"
+	"demerzel-badges/internal/models"
+	"demerzel-badges/internal/urls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const Context = "https://w3id.org/openbadges/v2"
+
+var ErrNoRecipient = errors.New("badge has no recipient email")
+
+type Profile struct {
+	Context     string `json:"@context"`
+	Type        string `json:"type"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	Email       string `json:"email,omitempty"`
+	Description string `json:"description,omitempty"`
+	Image       string `json:"image,omitempty"`
+}
+
+type Criteria struct {
+	Narrative string `json:"narrative"`
+}
+
+type BadgeClass struct {
+	Context     string   `json:"@context"`
+	Type        string   `json:"type"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Image       string   `json:"image"`
+	Criteria    Criteria `json:"criteria"`
+	Issuer      string   `json:"issuer"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+type IdentityObject struct {
+	Type     string `json:"type"`
+	Hashed   bool   `json:"hashed"`
+	Salt     string `json:"salt,omitempty"`
+	Identity string `json:"identity"`
+}
+
+type Verification struct {
+	Type string `json:"type"`
+}
+
+type Assertion struct {
+	Context      string         `json:"@context"`
+	Type         string         `json:"type"`
+	ID           string         `json:"id"`
+	Recipient    IdentityObject `json:"recipient"`
+	Badge        string         `json:"badge"`
+	IssuedOn     string         `json:"issuedOn"`
+	Verification Verification   `json:"verification"`
+}
+
+// RevokedAssertion is served with a 410 status in place of an assertion that
+// has been revoked, as the hosted verification process expects.
+type RevokedAssertion struct {
+	Context          string `json:"@context"`
+	Type             string `json:"type"`
+	ID               string `json:"id"`
+	Revoked          bool   `json:"revoked"`
+	RevocationReason string `json:"revocationReason,omitempty"`
+}
+
+// Issuer describes the organisation issuing the badges.
+type Issuer struct {
+	Name        string
+	URL         string
+	Email       string
+	Description string
+	Image       string
+}
+
+// Builder creates the documents. URLs build the links of the documents, Salt
+// is a secret mixed into recipient hashes.
+type Builder struct {
+	URLs   urls.Builder
+	Issuer Issuer
+	Salt   string
+}
+
+func (b Builder) IssuerURL() string {
+	return b.URLs.API("/openbadges/issuer")
+}
+
+func (b Builder) BadgeClassURL(badgeID uint) string {
+	return b.URLs.API("/openbadges/badges/%d", badgeID)
+}
+
+func (b Builder) AssertionURL(userBadgeID uint) string {
+	return b.URLs.Assertion(userBadgeID)
+}
+
+// AssertionIDFromURL returns the user badge ID of an assertion URL built by
+// AssertionURL.
+func (b Builder) AssertionIDFromURL(url string) (uint, bool) {
+	return b.URLs.ID(url, urls.AssertionPath)
+}
+
+func (b Builder) Profile() Profile {
+	return Profile{
+		Context:     Context,
+		Type:        "Issuer",
+		ID:          b.IssuerURL(),
+		Name:        b.Issuer.Name,
+		URL:         b.Issuer.URL,
+		Email:       b.Issuer.Email,
+		Description: b.Issuer.Description,
+		Image:       b.Issuer.Image,
+	}
+}
+
+// BadgeClass describes a skill badge. The badge must have its Skill and Tier
+// loaded.
+func (b Builder) BadgeClass(badge models.SkillBadge) BadgeClass {
+	skill := "the skill"
+	tags := []string{}
+	if badge.Skill != nil {
+		skill = badge.Skill.CategoryName
+		tags = append(tags, badge.Skill.CategoryName)
+	}
+
+	tier := string(badge.Name)
+	description := fmt.Sprintf("%s level in %s.", tier, skill)
+	if badge.Tier != nil {
+		tier = badge.Tier.DisplayName
+		if badge.Tier.Description != "" {
+			description = fmt.Sprintf("%s: %s.", skill, strings.TrimSuffix(badge.Tier.Description, "."))
+		}
+	}
+
+	return BadgeClass{
+		Context:     Context,
+		Type:        "BadgeClass",
+		ID:          b.BadgeClassURL(badge.ID),
+		Name:        fmt.Sprintf("%s %s", skill, tier),
+		Description: description,
+		Image:       b.URLs.BadgeImage(badge.ID),
+		Criteria: Criteria{
+			Narrative: fmt.Sprintf("Score between %g and %g in a %s assessment.", badge.MinScore, badge.MaxScore, skill),
+		},
+		Issuer: b.IssuerURL(),
+		Tags:   tags,
+	}
+}
+
+// Assertion describes the award of a badge to a user. The user badge must
+// have its User loaded.
+func (b Builder) Assertion(userBadge models.UserBadge) (Assertion, error) {
+	if userBadge.User == nil || userBadge.User.Email == "" {
+		return Assertion{}, ErrNoRecipient
+	}
+
+	salt := b.recipientSalt(userBadge.ID)
+
+	return Assertion{
+		Context: Context,
+		Type:    "Assertion",
+		ID:      b.AssertionURL(userBadge.ID),
+		Recipient: IdentityObject{
+			Type:     "email",
+			Hashed:   true,
+			Salt:     salt,
+			Identity: HashIdentity(userBadge.User.Email, salt),
+		},
+		Badge:        b.BadgeClassURL(userBadge.BadgeID),
+		IssuedOn:     userBadge.CreatedAt.UTC().Format(time.RFC3339),
+		Verification: Verification{Type: "hosted"},
+	}, nil
+}
+
+// Matches reports whether two assertions award the same badge to the same
+// recipient on the same date.
+func (a Assertion) Matches(other Assertion) bool {
+	return a.ID == other.ID &&
+		a.Badge == other.Badge &&
+		a.Recipient == other.Recipient &&
+		a.IssuedOn == other.IssuedOn
+}
+
+func (b Builder) RevokedAssertion(userBadge models.UserBadge) RevokedAssertion {
+	return RevokedAssertion{
+		Context:          Context,
+		Type:             "Assertion",
+		ID:               b.AssertionURL(userBadge.ID),
+		Revoked:          true,
+		RevocationReason: userBadge.RevocationReason,
+	}
+}
+
+// recipientSalt derives a per assertion salt so that hashed emails cannot be
+// compared across assertions.
+func (b Builder) recipientSalt(userBadgeID uint) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d", b.Salt, userBadgeID)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// HashIdentity hashes a recipient email the way backpacks expect it,
+// "sha256$" followed by the hex digest of the lowercased email and salt.
+func HashIdentity(email string, salt string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email)) + salt))
+	return "sha256$" + hex.EncodeToString(sum[:])
+}
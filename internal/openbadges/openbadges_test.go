@@ -0,0 +1,68 @@
+package openbadges
+
+import (
+	"crypto/sha256"
+	"demerzel-badges/internal/models"
+	"demerzel-badges/internal/urls"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var builder = Builder{
+	URLs:   urls.Builder{APIURL: "https://badges.example.com/api/badges/"},
+	Issuer: Issuer{Name: "Zuri Portfolio", URL: "https://zuri.team"},
+	Salt:   "secret",
+}
+
+func TestHashIdentity(t *testing.T) {
+	sum := sha256.Sum256([]byte("jane@example.com" + "abc"))
+
+	assert.Equal(t, "sha256$"+hex.EncodeToString(sum[:]), HashIdentity(" Jane@Example.com", "abc"))
+}
+
+func TestAssertion(t *testing.T) {
+	issued := time.Date(2023, 9, 20, 18, 28, 42, 0, time.FixedZone("WAT", 3600))
+	userBadge := models.UserBadge{
+		ID:        12,
+		BadgeID:   3,
+		CreatedAt: issued,
+		User:      &models.User{Email: "jane@example.com"},
+	}
+
+	assertion, err := builder.Assertion(userBadge)
+	assert.NoError(t, err)
+
+	assert.Equal(t, Context, assertion.Context)
+	assert.Equal(t, "https://badges.example.com/api/badges/openbadges/assertions/12", assertion.ID)
+	assert.Equal(t, "https://badges.example.com/api/badges/openbadges/badges/3", assertion.Badge)
+	assert.Equal(t, "2023-09-20T17:28:42Z", assertion.IssuedOn)
+	assert.Equal(t, "hosted", assertion.Verification.Type)
+	assert.True(t, assertion.Recipient.Hashed)
+	assert.Equal(t, HashIdentity("jane@example.com", assertion.Recipient.Salt), assertion.Recipient.Identity)
+
+	other, _ := builder.Assertion(models.UserBadge{ID: 13, User: userBadge.User})
+	assert.NotEqual(t, assertion.Recipient.Identity, other.Recipient.Identity)
+
+	_, err = builder.Assertion(models.UserBadge{ID: 14})
+	assert.ErrorIs(t, err, ErrNoRecipient)
+}
+
+func TestBadgeClass(t *testing.T) {
+	badgeClass := builder.BadgeClass(models.SkillBadge{
+		ID:       3,
+		Name:     "Expert",
+		MinScore: 80,
+		MaxScore: 100,
+		Skill:    &models.Skill{CategoryName: "Backend"},
+		Tier:     &models.BadgeTier{DisplayName: "Expert", Description: "Has mastered the skill"},
+	})
+
+	assert.Equal(t, "Backend Expert", badgeClass.Name)
+	assert.Equal(t, "Backend: Has mastered the skill.", badgeClass.Description)
+	assert.Equal(t, "https://badges.example.com/api/badges/openbadges/issuer", badgeClass.Issuer)
+	assert.Equal(t, "https://badges.example.com/api/badges/badges/3/image.png", badgeClass.Image)
+	assert.Contains(t, badgeClass.Criteria.Narrative, "between 80 and 100")
+}
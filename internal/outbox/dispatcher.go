@@ -0,0 +1,148 @@
+// Package outbox delivers the mails queued in the mail_log table. Mails are
+// written in the same transaction as the change they announce, so a slow or
+// failing mail service never fails the request that triggered them.
+package outbox
+
+import (
+	"context"
+	"demerzel-badges/internal/models"
+	"demerzel-badges/internal/repository"
+	"demerzel-badges/pkg/logger"
+	"time"
+)
+
+// Sender delivers a single mail.
+type Sender interface {
+	Send(ctx context.Context, mail models.MailLog) error
+}
+
+type Dispatcher struct {
+	Mails  repository.MailRepository
+	Sender Sender
+
+	// Interval is how often the outbox is polled.
+	Interval  time.Duration
+	BatchSize int
+	// MaxAttempts is the number of deliveries tried before a mail is
+	// dead-lettered.
+	MaxAttempts int
+	// BaseDelay is the wait after the first failure, doubled after each
+	// further failure up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Timeout bounds a single delivery.
+	Timeout time.Duration
+
+	Now func() time.Time
+}
+
+// Config is the retry policy of the dispatcher, set by the OUTBOX_*
+// variables.
+type Config struct {
+	PollInterval time.Duration
+	MaxAttempts  int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+}
+
+func NewDispatcher(mails repository.MailRepository, sender Sender, cfg Config) *Dispatcher {
+	return &Dispatcher{
+		Mails:       mails,
+		Sender:      sender,
+		Interval:    cfg.PollInterval,
+		BatchSize:   20,
+		MaxAttempts: cfg.MaxAttempts,
+		BaseDelay:   cfg.BaseDelay,
+		MaxDelay:    cfg.MaxDelay,
+		Timeout:     15 * time.Second,
+		Now:         time.Now,
+	}
+}
+
+// Run dispatches due mails until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DispatchOnce(ctx); err != nil {
+			logger.Errorf("outbox: unable to dispatch mails: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce sends the mails currently due and returns how many were sent.
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	// Claimed mails are not picked up again before the lease ends, even if
+	// this dispatcher dies while sending them.
+	lease := d.Timeout * time.Duration(d.BatchSize+1)
+	mails, err := d.Mails.ClaimDueMails(d.Now(), d.BatchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, mail := range mails {
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+
+		if d.deliver(ctx, mail) {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, mail models.MailLog) bool {
+	sendCtx, cancel := context.WithTimeout(ctx, d.Timeout)
+	err := d.Sender.Send(sendCtx, mail)
+	cancel()
+
+	if err == nil {
+		if err := d.Mails.MarkMailDelivered(&mail, d.Now()); err != nil {
+			logger.Errorf("outbox: unable to mark mail %d as delivered: %v", mail.ID, err)
+		}
+		return true
+	}
+
+	var nextAttempt *time.Time
+	if mail.Attempts+1 < d.MaxAttempts {
+		next := d.Now().Add(Backoff(mail.Attempts+1, d.BaseDelay, d.MaxDelay))
+		nextAttempt = &next
+		logger.Warnf("outbox: delivery of mail %d failed, retrying at %s: %v", mail.ID, next.Format(time.RFC3339), err)
+	} else {
+		logger.Errorf("outbox: delivery of mail %d failed %d times, dead-lettered: %v", mail.ID, mail.Attempts+1, err)
+	}
+
+	if err := d.Mails.MarkMailAttemptFailed(&mail, err, nextAttempt); err != nil {
+		logger.Errorf("outbox: unable to record failed delivery of mail %d: %v", mail.ID, err)
+	}
+
+	return false
+}
+
+// Backoff is the wait before retrying after the given number of failed
+// attempts: base, then doubled after each attempt, capped at max.
+func Backoff(attempts int, base time.Duration, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+
+	if delay > max {
+		return max
+	}
+
+	return delay
+}
@@ -0,0 +1,189 @@
+package outbox
+
+import (
+	"context"
+	"demerzel-badges/internal/models"
+	"demerzel-badges/internal/repository"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSender fails the deliveries of the mails it is told to, and records
+// the others.
+type fakeSender struct {
+	failing map[uint]bool
+	sent    []uint
+}
+
+func (s *fakeSender) Send(ctx context.Context, mail models.MailLog) error {
+	if s.failing[mail.ID] {
+		return errors.New("mail service unavailable")
+	}
+
+	s.sent = append(s.sent, mail.ID)
+
+	return nil
+}
+
+func testDispatcher(sender Sender) (*Dispatcher, *repository.MemoryStore, *time.Time) {
+	store := repository.NewMemoryStore()
+	now := time.Date(2023, 9, 20, 12, 0, 0, 0, time.UTC)
+
+	d := NewDispatcher(store, sender, Config{
+		PollInterval: time.Second,
+		MaxAttempts:  3,
+		BaseDelay:    30 * time.Second,
+		MaxDelay:     10 * time.Minute,
+	})
+	d.Now = func() time.Time { return now }
+
+	return d, store, &now
+}
+
+func enqueue(t *testing.T, store *repository.MemoryStore, at time.Time) models.MailLog {
+	mail := models.MailLog{Email: "sample@example.com", Channel: "messaging", NextAttemptAt: at}
+	require.NoError(t, store.EnqueueMail(&mail))
+
+	return mail
+}
+
+func findMail(t *testing.T, store *repository.MemoryStore, mailID uint) *models.MailLog {
+	mail, err := store.FindMailLog(mailID)
+	require.NoError(t, err)
+
+	return mail
+}
+
+func TestBackoff(t *testing.T) {
+	base := 30 * time.Second
+	max := 10 * time.Minute
+
+	assert.Equal(t, 30*time.Second, Backoff(1, base, max))
+	assert.Equal(t, 60*time.Second, Backoff(2, base, max))
+	assert.Equal(t, 4*time.Minute, Backoff(4, base, max))
+	assert.Equal(t, max, Backoff(6, base, max))
+	assert.Equal(t, max, Backoff(1000, base, max))
+}
+
+func TestDispatchOnceDeliversDueMails(t *testing.T) {
+	sender := &fakeSender{}
+	d, store, now := testDispatcher(sender)
+
+	due := enqueue(t, store, now.Add(-time.Minute))
+	later := enqueue(t, store, now.Add(time.Hour))
+
+	sent, err := d.DispatchOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, []uint{due.ID}, sender.sent)
+
+	delivered := findMail(t, store, due.ID)
+	assert.Equal(t, models.MailComplete, delivered.Status)
+	assert.Equal(t, 1, delivered.Attempts)
+	assert.NotNil(t, delivered.DeliveredAt)
+
+	assert.Equal(t, models.MailPending, findMail(t, store, later.ID).Status)
+}
+
+func TestClaimDueMailsLeasesClaimedMails(t *testing.T) {
+	_, store, now := testDispatcher(&fakeSender{})
+
+	mail := enqueue(t, store, *now)
+
+	claimed, err := store.ClaimDueMails(*now, 10, time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 1)
+
+	// Another dispatcher skips the mail while it is leased
+	claimed, err = store.ClaimDueMails(now.Add(30*time.Second), 10, time.Minute)
+	assert.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	// and claims it again once the lease ended without a delivery
+	claimed, err = store.ClaimDueMails(now.Add(time.Minute), 10, time.Minute)
+	assert.NoError(t, err)
+	if assert.Len(t, claimed, 1) {
+		assert.Equal(t, mail.ID, claimed[0].ID)
+	}
+}
+
+func TestDispatchOnceRetriesWithBackoff(t *testing.T) {
+	sender := &fakeSender{failing: map[uint]bool{}}
+	d, store, now := testDispatcher(sender)
+
+	mail := enqueue(t, store, *now)
+	sender.failing[mail.ID] = true
+
+	sent, err := d.DispatchOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	retried := findMail(t, store, mail.ID)
+	assert.Equal(t, models.MailPending, retried.Status)
+	assert.Equal(t, 1, retried.Attempts)
+	assert.Equal(t, "mail service unavailable", retried.LastError)
+	assert.Equal(t, now.Add(30*time.Second), retried.NextAttemptAt)
+
+	// Not due again before the backoff ends
+	sent, err = d.DispatchOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Equal(t, 1, findMail(t, store, mail.ID).Attempts)
+}
+
+func TestDispatchOnceDeadLettersAfterMaxAttempts(t *testing.T) {
+	sender := &fakeSender{failing: map[uint]bool{}}
+	d, store, now := testDispatcher(sender)
+
+	mail := enqueue(t, store, *now)
+	sender.failing[mail.ID] = true
+
+	for i := 0; i < d.MaxAttempts; i++ {
+		_, err := d.DispatchOnce(context.Background())
+		assert.NoError(t, err)
+		*now = now.Add(d.MaxDelay)
+	}
+
+	failed := findMail(t, store, mail.ID)
+	assert.Equal(t, models.MailFailed, failed.Status)
+	assert.Equal(t, d.MaxAttempts, failed.Attempts)
+
+	// Dead-lettered mails are not claimed anymore
+	sent, err := d.DispatchOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Equal(t, d.MaxAttempts, findMail(t, store, mail.ID).Attempts)
+}
+
+func TestReplayMail(t *testing.T) {
+	sender := &fakeSender{failing: map[uint]bool{}}
+	d, store, now := testDispatcher(sender)
+	d.MaxAttempts = 1
+
+	mail := enqueue(t, store, *now)
+	sender.failing[mail.ID] = true
+
+	_, err := d.DispatchOnce(context.Background())
+	assert.NoError(t, err)
+
+	failed := findMail(t, store, mail.ID)
+	assert.Equal(t, models.MailFailed, failed.Status)
+
+	assert.NoError(t, store.ReplayMail(failed))
+	assert.Equal(t, models.MailPending, failed.Status)
+	assert.Equal(t, 0, failed.Attempts)
+
+	// Only dead-lettered mails can be replayed
+	assert.ErrorIs(t, store.ReplayMail(failed), models.ErrMailNotReplayable)
+
+	delete(sender.failing, mail.ID)
+	*now = failed.NextAttemptAt
+	sent, err := d.DispatchOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, models.MailComplete, findMail(t, store, mail.ID).Status)
+}
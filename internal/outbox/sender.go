@@ -0,0 +1,29 @@
+package outbox
+
+import (
+	"context"
+	"demerzel-badges/internal/models"
+	"demerzel-badges/internal/notifier"
+	"encoding/json"
+	"fmt"
+)
+
+// NotifierSender delivers each outbox record through the notifier of its
+// channel, so that a channel failing is retried without resending the others.
+type NotifierSender struct {
+	Notifiers notifier.Multi
+}
+
+func (s NotifierSender) Send(ctx context.Context, mail models.MailLog) error {
+	n := s.Notifiers.Get(mail.Channel)
+	if n == nil {
+		return fmt.Errorf("no notifier is configured for channel %q", mail.Channel)
+	}
+
+	var event notifier.Event
+	if err := json.Unmarshal(mail.MessageData, &event); err != nil {
+		return err
+	}
+
+	return n.Notify(ctx, event)
+}
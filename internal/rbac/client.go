@@ -0,0 +1,43 @@
+package rbac
+
+import (
+	"context"
+	"demerzel-badges/internal/auth"
+	"errors"
+	"net/http"
+)
+
+// Client authorizes tokens with the local engine. The token is verified by
+// the Verifier, which only identifies the caller: the permissions in its
+// claims are ignored.
+type Client struct {
+	Verifier *auth.Verifier
+	Engine   *Engine
+}
+
+func (c *Client) Authorize(ctx context.Context, token string, permission string) (*auth.Principal, error) {
+	claims, err := c.Verifier.Verify(ctx, token)
+	if errors.Is(err, auth.ErrUnavailable) {
+		return nil, err
+	}
+
+	if err != nil {
+		return nil, &auth.DeniedError{Status: http.StatusUnauthorized, Message: err.Error()}
+	}
+
+	userID := c.Verifier.Principal(claims).UserID
+	if userID == "" {
+		return nil, &auth.DeniedError{Status: http.StatusUnauthorized, Message: "token has no user"}
+	}
+
+	grants, err := c.Engine.Grants(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.Engine.Allows(grants, permission) {
+		return nil, &auth.DeniedError{Status: http.StatusForbidden, Message: "missing permission " + permission}
+	}
+
+	return &auth.Principal{UserID: userID, Permissions: grants.Permissions, Roles: grants.Roles}, nil
+}
@@ -0,0 +1,37 @@
+package rbac
+
+import (
+	"demerzel-badges/internal/auth"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ModeRBAC is the AUTH_MODE using the local engine.
+const ModeRBAC = "rbac"
+
+// Config adds to the auth configuration how long permissions are cached,
+// RBAC_CACHE_TTL.
+type Config struct {
+	Auth     auth.Config
+	CacheTTL time.Duration
+}
+
+// New creates the client selected by the auth mode. With rbac, tokens are
+// verified like in the jwt mode and permissions are read from the database,
+// cached for CacheTTL. The other modes are handled by auth.New.
+func New(db *gorm.DB, cfg Config) (auth.Client, error) {
+	if strings.ToLower(strings.TrimSpace(cfg.Auth.Mode)) != ModeRBAC {
+		return auth.New(cfg.Auth)
+	}
+
+	verifier, err := auth.NewVerifier(cfg.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	engine := NewEngine(DBStore{DB: db}, cfg.CacheTTL, verifier.AdminRoles)
+
+	return &Client{Verifier: verifier, Engine: engine}, nil
+}
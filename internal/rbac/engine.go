@@ -0,0 +1,116 @@
+// Package rbac resolves the permissions of users from the role and
+// permission tables, instead of asking the auth service.
+package rbac
+
+import (
+	"demerzel-badges/internal/models"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxCacheEntries bounds the memory used by the cache.
+const maxCacheEntries = 10000
+
+// Store loads the roles and permissions of a user.
+type Store interface {
+	UserGrants(userID string) (*models.UserGrants, error)
+}
+
+// DBStore reads the grants from the database.
+type DBStore struct {
+	DB *gorm.DB
+}
+
+func (s DBStore) UserGrants(userID string) (*models.UserGrants, error) {
+	return models.GetUserGrants(s.DB, userID)
+}
+
+// generation is bumped by Invalidate, entries cached before are stale.
+var generation int64
+
+// Invalidate drops the grants cached by every engine, to be called when roles
+// or grants change.
+func Invalidate() {
+	atomic.AddInt64(&generation, 1)
+}
+
+type cacheEntry struct {
+	grants     *models.UserGrants
+	generation int64
+	expires    time.Time
+}
+
+// Engine decides whether users have a permission. Grants are cached for TTL,
+// which bounds how long changes made by other instances take to apply.
+type Engine struct {
+	Store Store
+	TTL   time.Duration
+	// AdminRoles are granted every permission.
+	AdminRoles []string
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	now     func() time.Time
+}
+
+func NewEngine(store Store, ttl time.Duration, adminRoles []string) *Engine {
+	return &Engine{
+		Store:      store,
+		TTL:        ttl,
+		AdminRoles: adminRoles,
+		entries:    map[string]cacheEntry{},
+		now:        time.Now,
+	}
+}
+
+// Grants returns the roles and permissions of the user.
+func (e *Engine) Grants(userID string) (*models.UserGrants, error) {
+	current := atomic.LoadInt64(&generation)
+
+	e.mu.Lock()
+	entry, ok := e.entries[userID]
+	e.mu.Unlock()
+
+	if ok && entry.generation == current && e.now().Before(entry.expires) {
+		return entry.grants, nil
+	}
+
+	grants, err := e.Store.UserGrants(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.TTL > 0 {
+		e.mu.Lock()
+		if len(e.entries) >= maxCacheEntries {
+			e.entries = map[string]cacheEntry{}
+		}
+		e.entries[userID] = cacheEntry{grants: grants, generation: current, expires: e.now().Add(e.TTL)}
+		e.mu.Unlock()
+	}
+
+	return grants, nil
+}
+
+// Allows reports whether the grants include the permission, directly or
+// through an admin role.
+func (e *Engine) Allows(grants *models.UserGrants, permission string) bool {
+	for _, name := range grants.Permissions {
+		if name == permission {
+			return true
+		}
+	}
+
+	for _, role := range grants.Roles {
+		for _, admin := range e.AdminRoles {
+			if role == admin {
+				return true
+			}
+		}
+	}
+
+	return false
+}
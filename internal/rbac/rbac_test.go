@@ -0,0 +1,126 @@
+package rbac
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"demerzel-badges/internal/auth"
+	"demerzel-badges/internal/models"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStore struct {
+	grants map[string]*models.UserGrants
+	loads  int
+	err    error
+}
+
+func (s *fakeStore) UserGrants(userID string) (*models.UserGrants, error) {
+	s.loads++
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	if grants, ok := s.grants[userID]; ok {
+		return grants, nil
+	}
+
+	return &models.UserGrants{UserID: userID}, nil
+}
+
+func newStore() *fakeStore {
+	return &fakeStore{grants: map[string]*models.UserGrants{
+		"u1": {UserID: "u1", Roles: []string{"mentor"}, Permissions: []string{"badge.read", "badge.update.own"}},
+		"u2": {UserID: "u2", Roles: []string{"admin"}},
+	}}
+}
+
+func TestEngineAllows(t *testing.T) {
+	engine := NewEngine(newStore(), time.Minute, []string{"admin"})
+
+	grants, err := engine.Grants("u1")
+	assert.NoError(t, err)
+	assert.True(t, engine.Allows(grants, "badge.read"))
+	assert.False(t, engine.Allows(grants, "badge.create"))
+
+	grants, err = engine.Grants("u2")
+	assert.NoError(t, err)
+	assert.True(t, engine.Allows(grants, "badge.create"))
+
+	grants, err = engine.Grants("u3")
+	assert.NoError(t, err)
+	assert.False(t, engine.Allows(grants, "badge.read"))
+}
+
+func TestEngineCache(t *testing.T) {
+	store := newStore()
+	engine := NewEngine(store, time.Minute, nil)
+
+	now := time.Now()
+	engine.now = func() time.Time { return now }
+
+	_, _ = engine.Grants("u1")
+	_, _ = engine.Grants("u1")
+	assert.Equal(t, 1, store.loads)
+
+	Invalidate()
+	_, _ = engine.Grants("u1")
+	assert.Equal(t, 2, store.loads)
+
+	now = now.Add(2 * time.Minute)
+	_, _ = engine.Grants("u1")
+	assert.Equal(t, 3, store.loads)
+
+	store.err = errors.New("database down")
+	Invalidate()
+	_, err := engine.Grants("u1")
+	assert.Error(t, err)
+}
+
+func TestClientAuthorize(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	set, _ := json.Marshal(map[string]interface{}{"keys": []map[string]string{
+		{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": base64.RawURLEncoding.EncodeToString(public)},
+	}})
+	keys, err := auth.ParseJWKS(set)
+	assert.NoError(t, err)
+
+	client := &Client{
+		Verifier: &auth.Verifier{Keys: auth.StaticKeySet(keys), UserClaim: "sub", PermissionsClaim: "permissions"},
+		Engine:   NewEngine(newStore(), time.Minute, []string{"admin"}),
+	}
+
+	sign := func(claims map[string]interface{}) string {
+		header, _ := json.Marshal(map[string]string{"alg": "EdDSA", "kid": "ed"})
+		payload, _ := json.Marshal(claims)
+		input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+		return input + "." + base64.RawURLEncoding.EncodeToString(ed25519.Sign(private, []byte(input)))
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+
+	principal, err := client.Authorize(context.Background(), sign(map[string]interface{}{"sub": "u1", "exp": exp}), "badge.read")
+	assert.NoError(t, err)
+	assert.Equal(t, "u1", principal.UserID)
+	assert.Equal(t, []string{"mentor"}, principal.Roles)
+
+	// Permissions in the token are not trusted
+	token := sign(map[string]interface{}{"sub": "u1", "exp": exp, "permissions": []string{"badge.create"}})
+	_, err = client.Authorize(context.Background(), token, "badge.create")
+	var denied *auth.DeniedError
+	assert.True(t, errors.As(err, &denied))
+	assert.Equal(t, http.StatusForbidden, denied.Status)
+
+	_, err = client.Authorize(context.Background(), "not-a-token", "badge.read")
+	assert.True(t, errors.As(err, &denied))
+	assert.Equal(t, http.StatusUnauthorized, denied.Status)
+}
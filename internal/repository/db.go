@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"demerzel-badges/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DBStore implements the repositories on the Postgres database.
+type DBStore struct {
+	DB *gorm.DB
+}
+
+func (s DBStore) ListBadges(skillID uint) ([]models.SkillBadge, error) {
+	return models.ListBadges(s.DB, skillID)
+}
+
+func (s DBStore) FindBadgeByID(badgeID uint) (*models.SkillBadge, error) {
+	return models.FindBadgeByID(s.DB, badgeID)
+}
+
+func (s DBStore) BadgeExists(skillID uint, tierID uint) bool {
+	return models.BadgeExists(s.DB, skillID, tierID)
+}
+
+func (s DBStore) CreateBadge(badge models.SkillBadge) (*models.SkillBadge, error) {
+	return models.CreateBadge(s.DB, badge)
+}
+
+func (s DBStore) UpdateBadgeScores(badge *models.SkillBadge, minScore, maxScore float64) error {
+	return models.UpdateBadgeScores(s.DB, badge, minScore, maxScore)
+}
+
+func (s DBStore) DeleteBadge(badgeID uint) error {
+	return models.DeleteBadge(s.DB, badgeID)
+}
+
+func (s DBStore) GetSkillLadder(skillID uint) ([]models.SkillBadge, error) {
+	return models.GetSkillLadder(s.DB, skillID)
+}
+
+func (s DBStore) UpdateLadderScores(badges []models.SkillBadge) error {
+	return models.UpdateLadderScores(s.DB, badges)
+}
+
+func (s DBStore) GetTierLadder(skillID uint) ([]models.BadgeTier, error) {
+	return models.GetTierLadder(s.DB, skillID)
+}
+
+func (s DBStore) FindTierByID(tierID uint) (*models.BadgeTier, error) {
+	return models.FindTierByID(s.DB, tierID)
+}
+
+func (s DBStore) FindTierByName(skillID uint, name string) (*models.BadgeTier, error) {
+	return models.FindTierByName(s.DB, skillID, name)
+}
+
+func (s DBStore) TierInLadder(tier *models.BadgeTier, skillID uint) bool {
+	return tier.InLadder(s.DB, skillID)
+}
+
+func (s DBStore) CreateTier(tier models.BadgeTier) (*models.BadgeTier, error) {
+	return models.CreateTier(s.DB, tier)
+}
+
+func (s DBStore) UpdateTier(tier *models.BadgeTier, requireContiguous bool) error {
+	return models.UpdateTier(s.DB, tier, requireContiguous)
+}
+
+func (s DBStore) DeleteTier(tierID uint) error {
+	return models.DeleteTier(s.DB, tierID)
+}
+
+func (s DBStore) GetUserBadges(userID string, filter models.UserBadgeFilter) ([]models.UserBadge, error) {
+	return models.GetUserBadges(s.DB, userID, filter)
+}
+
+func (s DBStore) GetUserBadgeByID(badgeID uint, userID string, includeRevoked bool) (*models.UserBadge, error) {
+	return models.GetUserBadgeByID(s.DB, badgeID, userID, includeRevoked)
+}
+
+func (s DBStore) GetUserBadgesForSkill(userID string, skillID uint) ([]models.UserBadge, error) {
+	return models.GetUserBadgesForSkill(s.DB, userID, skillID)
+}
+
+func (s DBStore) FindUserBadge(badgeID uint) (*models.UserBadge, error) {
+	return models.FindUserBadge(s.DB, badgeID)
+}
+
+// AssignBadge awards the badge and queues its notifications in the same
+// transaction, they are sent by the outbox dispatcher.
+func (s DBStore) AssignBadge(userID string, assessmentID uint, mails MailBuilder) (*models.UserBadge, models.AssignOutcome, error) {
+	var userBadge *models.UserBadge
+	var outcome models.AssignOutcome
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		userBadge, outcome, err = models.AssignBadge(tx, userID, assessmentID)
+		if err != nil || !outcome.Awarded() || mails == nil {
+			return err
+		}
+
+		queued, err := mails(userBadge, outcome)
+		if err != nil {
+			return err
+		}
+
+		for i := range queued {
+			if err := models.EnqueueMail(tx, &queued[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return userBadge, outcome, nil
+}
+
+func (s DBStore) RevokeUserBadge(badgeID uint, revokedBy string, reason string) (*models.UserBadge, error) {
+	return models.RevokeUserBadge(s.DB, badgeID, revokedBy, reason)
+}
+
+func (s DBStore) FindSkillById(skillID uint) (*models.Skill, error) {
+	return models.FindSkillById(s.DB, skillID)
+}
+
+func (s DBStore) FindUserAssessment(userAssessmentID uint) (*models.UserAssessment, error) {
+	return models.FindUserAssessment(s.DB, userAssessmentID)
+}
+
+func (s DBStore) EnqueueMail(mail *models.MailLog) error {
+	return models.EnqueueMail(s.DB, mail)
+}
+
+func (s DBStore) ClaimDueMails(now time.Time, limit int, lease time.Duration) ([]models.MailLog, error) {
+	return models.ClaimDueMails(s.DB, now, limit, lease)
+}
+
+func (s DBStore) MarkMailDelivered(mail *models.MailLog, deliveredAt time.Time) error {
+	return models.MarkMailDelivered(s.DB, mail, deliveredAt)
+}
+
+func (s DBStore) MarkMailAttemptFailed(mail *models.MailLog, deliveryErr error, nextAttempt *time.Time) error {
+	return models.MarkMailAttemptFailed(s.DB, mail, deliveryErr, nextAttempt)
+}
+
+func (s DBStore) ListMailLogs(filter models.MailLogFilter) ([]models.MailLog, error) {
+	return models.ListMailLogs(s.DB, filter)
+}
+
+func (s DBStore) FindMailLog(mailID uint) (*models.MailLog, error) {
+	return models.FindMailLog(s.DB, mailID)
+}
+
+func (s DBStore) ReplayMail(mail *models.MailLog) error {
+	return models.ReplayMail(s.DB, mail)
+}
+
+func (s DBStore) ListRoles() ([]models.RoleWithPermissions, error) {
+	return models.ListRoles(s.DB)
+}
+
+func (s DBStore) FindRoleByID(roleID uint) (*models.Role, error) {
+	return models.FindRoleByID(s.DB, roleID)
+}
+
+func (s DBStore) CreateRole(name string, permissions []string) (*models.RoleWithPermissions, error) {
+	return models.CreateRole(s.DB, name, permissions)
+}
+
+func (s DBStore) DeleteRole(roleID uint) error {
+	return models.DeleteRole(s.DB, roleID)
+}
+
+func (s DBStore) RolePermissionNames(roleID uint) ([]string, error) {
+	return models.RolePermissionNames(s.DB, roleID)
+}
+
+func (s DBStore) GrantRolePermission(roleID uint, name string) error {
+	return models.GrantRolePermission(s.DB, roleID, name)
+}
+
+func (s DBStore) RevokeRolePermission(roleID uint, name string) error {
+	return models.RevokeRolePermission(s.DB, roleID, name)
+}
+
+func (s DBStore) GetUserGrants(userID string) (*models.UserGrants, error) {
+	return models.GetUserGrants(s.DB, userID)
+}
+
+func (s DBStore) AssignUserRole(userID string, roleID uint) error {
+	return models.AssignUserRole(s.DB, userID, roleID)
+}
+
+func (s DBStore) UnassignUserRole(userID string, roleID uint) error {
+	return models.UnassignUserRole(s.DB, userID, roleID)
+}
+
+func (s DBStore) GrantUserPermission(userID string, name string) error {
+	return models.GrantUserPermission(s.DB, userID, name)
+}
+
+func (s DBStore) RevokeUserPermission(userID string, name string) error {
+	return models.RevokeUserPermission(s.DB, userID, name)
+}
+
+func (s DBStore) ListAPIKeys() ([]models.APIKey, error) {
+	return models.ListAPIKeys(s.DB)
+}
+
+func (s DBStore) CreateAPIKey(key models.APIKey) (*models.APIKey, error) {
+	return models.CreateAPIKey(s.DB, key)
+}
+
+func (s DBStore) RevokeAPIKey(keyID uint) (*models.APIKey, error) {
+	return models.RevokeAPIKey(s.DB, keyID)
+}
+
+func (s DBStore) FindIdempotencyKey(key string, userID string) (*models.IdempotencyKey, error) {
+	return models.FindIdempotencyKey(s.DB, key, userID)
+}
+
+func (s DBStore) ReserveIdempotencyKey(record *models.IdempotencyKey) error {
+	return models.ReserveIdempotencyKey(s.DB, record)
+}
+
+func (s DBStore) CompleteIdempotencyKey(record *models.IdempotencyKey, statusCode int, body string) error {
+	return models.CompleteIdempotencyKey(s.DB, record, statusCode, body)
+}
+
+func (s DBStore) DeleteIdempotencyKey(record *models.IdempotencyKey) error {
+	return models.DeleteIdempotencyKey(s.DB, record)
+}
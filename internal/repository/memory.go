@@ -0,0 +1,1132 @@
+package repository
+
+import (
+	"demerzel-badges/internal/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryStore implements the repositories in memory, for tests and running
+// the API without a database. It behaves like DBStore, a missing record is
+// reported with gorm.ErrRecordNotFound.
+type MemoryStore struct {
+	mu          sync.Mutex
+	lastID      uint
+	users       map[string]models.User
+	skills      map[uint]models.Skill
+	tiers       map[uint]models.BadgeTier
+	badges      map[uint]models.SkillBadge
+	assessments map[uint]models.Assessment
+	taken       map[uint]models.UserAssessment
+	userBadges  map[uint]models.UserBadge
+	mails       []models.MailLog
+	now         func() time.Time
+
+	roles           map[uint]models.Role
+	rolePermissions map[uint]map[string]bool
+	userRoles       map[string]map[uint]bool
+	userPermissions map[string]map[string]bool
+	apiKeys         map[uint]models.APIKey
+	idempotencyKeys map[uint]models.IdempotencyKey
+}
+
+// NewMemoryStore returns an empty store holding the default tier ladder.
+func NewMemoryStore() *MemoryStore {
+	m := &MemoryStore{
+		users:       map[string]models.User{},
+		skills:      map[uint]models.Skill{},
+		tiers:       map[uint]models.BadgeTier{},
+		badges:      map[uint]models.SkillBadge{},
+		assessments: map[uint]models.Assessment{},
+		taken:       map[uint]models.UserAssessment{},
+		userBadges:  map[uint]models.UserBadge{},
+		now:         time.Now,
+
+		roles:           map[uint]models.Role{},
+		rolePermissions: map[uint]map[string]bool{},
+		userRoles:       map[string]map[uint]bool{},
+		userPermissions: map[string]map[string]bool{},
+		apiKeys:         map[uint]models.APIKey{},
+		idempotencyKeys: map[uint]models.IdempotencyKey{},
+	}
+
+	for _, tier := range models.DefaultTiers {
+		tier.ID = m.nextID(0)
+		m.tiers[tier.ID] = tier
+	}
+
+	return m
+}
+
+// nextID returns id, or a new ID when it is zero.
+func (m *MemoryStore) nextID(id uint) uint {
+	if id == 0 {
+		m.lastID++
+		return m.lastID
+	}
+
+	if id > m.lastID {
+		m.lastID = id
+	}
+
+	return id
+}
+
+// AddUser stores a user, replacing the one with the same ID.
+func (m *MemoryStore) AddUser(user models.User) models.User {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.users[user.ID] = user
+
+	return user
+}
+
+// AddSkill stores a skill, giving it an ID when it has none.
+func (m *MemoryStore) AddSkill(skill models.Skill) models.Skill {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	skill.ID = m.nextID(skill.ID)
+	m.skills[skill.ID] = skill
+
+	return skill
+}
+
+// AddAssessment stores an assessment, giving it an ID when it has none.
+func (m *MemoryStore) AddAssessment(assessment models.Assessment) models.Assessment {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	assessment.ID = m.nextID(assessment.ID)
+	m.assessments[assessment.ID] = assessment
+
+	return assessment
+}
+
+// AddUserAssessment stores an assessment taken by a user, giving it an ID
+// when it has none.
+func (m *MemoryStore) AddUserAssessment(taken models.UserAssessment) models.UserAssessment {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	taken.ID = m.nextID(taken.ID)
+	m.taken[taken.ID] = taken
+
+	return taken
+}
+
+// Mails returns the notifications queued by AssignBadge.
+func (m *MemoryStore) Mails() []models.MailLog {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]models.MailLog(nil), m.mails...)
+}
+
+func (m *MemoryStore) ListBadges(skillID uint) ([]models.SkillBadge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	badges := []models.SkillBadge{}
+	for _, badge := range m.badges {
+		if skillID == 0 || badge.SkillID == skillID {
+			badges = append(badges, m.loadBadge(badge))
+		}
+	}
+
+	sort.Slice(badges, func(i, j int) bool {
+		if badges[i].SkillID != badges[j].SkillID {
+			return badges[i].SkillID < badges[j].SkillID
+		}
+		return badges[i].MinScore < badges[j].MinScore
+	})
+
+	return badges, nil
+}
+
+func (m *MemoryStore) FindBadgeByID(badgeID uint) (*models.SkillBadge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	badge, ok := m.badges[badgeID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	badge = m.loadBadge(badge)
+
+	return &badge, nil
+}
+
+func (m *MemoryStore) BadgeExists(skillID uint, tierID uint) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, badge := range m.badges {
+		if badge.SkillID == skillID && badge.TierID == tierID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *MemoryStore) CreateBadge(badge models.SkillBadge) (*models.SkillBadge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	newBadge := models.SkillBadge{
+		ID:       m.nextID(0),
+		SkillID:  badge.SkillID,
+		TierID:   badge.TierID,
+		Name:     badge.Name,
+		MinScore: badge.MinScore,
+		MaxScore: badge.MaxScore,
+	}
+	m.badges[newBadge.ID] = newBadge
+
+	return &newBadge, nil
+}
+
+func (m *MemoryStore) UpdateBadgeScores(badge *models.SkillBadge, minScore, maxScore float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.setScores(badge.ID, minScore, maxScore)
+	badge.MinScore, badge.MaxScore = minScore, maxScore
+
+	return nil
+}
+
+func (m *MemoryStore) DeleteBadge(badgeID uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, userBadge := range m.userBadges {
+		if userBadge.BadgeID == badgeID {
+			return models.ErrBadgeInUse
+		}
+	}
+
+	delete(m.badges, badgeID)
+
+	return nil
+}
+
+func (m *MemoryStore) GetSkillLadder(skillID uint) ([]models.SkillBadge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.skillLadder(skillID), nil
+}
+
+func (m *MemoryStore) UpdateLadderScores(badges []models.SkillBadge) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, badge := range badges {
+		m.setScores(badge.ID, badge.MinScore, badge.MaxScore)
+	}
+
+	return nil
+}
+
+func (m *MemoryStore) GetTierLadder(skillID uint) ([]models.BadgeTier, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.tierLadder(skillID), nil
+}
+
+func (m *MemoryStore) FindTierByID(tierID uint) (*models.BadgeTier, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tier, ok := m.tiers[tierID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &tier, nil
+}
+
+func (m *MemoryStore) FindTierByName(skillID uint, name string) (*models.BadgeTier, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, tier := range m.tierLadder(skillID) {
+		if strings.EqualFold(tier.Name, name) {
+			return &tier, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MemoryStore) TierInLadder(tier *models.BadgeTier, skillID uint) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, candidate := range m.tierLadder(skillID) {
+		if candidate.ID == tier.ID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *MemoryStore) CreateTier(tier models.BadgeTier) (*models.BadgeTier, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	newTier := models.BadgeTier{
+		SkillID:     tier.SkillID,
+		Name:        tier.Name,
+		DisplayName: tier.DisplayName,
+		Description: tier.Description,
+		Rank:        tier.Rank,
+		CreatedAt:   m.now(),
+		UpdatedAt:   m.now(),
+	}
+
+	if newTier.DisplayName == "" {
+		newTier.DisplayName = newTier.Name
+	}
+
+	if m.tierConflicts(newTier) {
+		return nil, models.ErrTierExists
+	}
+
+	newTier.ID = m.nextID(0)
+	m.tiers[newTier.ID] = newTier
+
+	return &newTier, nil
+}
+
+func (m *MemoryStore) UpdateTier(tier *models.BadgeTier, requireContiguous bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.tiers[tier.ID]
+	if !ok {
+		return nil
+	}
+
+	if m.tierConflicts(*tier) {
+		return models.ErrTierExists
+	}
+
+	previous := stored
+	stored.Name, stored.DisplayName = tier.Name, tier.DisplayName
+	stored.Description, stored.Rank = tier.Description, tier.Rank
+	stored.UpdatedAt = m.now()
+	m.tiers[tier.ID] = stored
+
+	for _, skillID := range m.tierSkills(tier.ID) {
+		if issues := models.ValidateLadder(m.skillLadder(skillID), requireContiguous); len(issues) > 0 {
+			m.tiers[tier.ID] = previous
+			return &models.LadderError{SkillID: skillID, Issues: issues}
+		}
+	}
+
+	for id, badge := range m.badges {
+		if badge.TierID == tier.ID {
+			badge.Name = models.Badge(tier.Name)
+			m.badges[id] = badge
+		}
+	}
+
+	return nil
+}
+
+func (m *MemoryStore) DeleteTier(tierID uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, badge := range m.badges {
+		if badge.TierID == tierID {
+			return models.ErrTierInUse
+		}
+	}
+
+	delete(m.tiers, tierID)
+
+	return nil
+}
+
+func (m *MemoryStore) GetUserBadges(userID string, filter models.UserBadgeFilter) ([]models.UserBadge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	badges := []models.UserBadge{}
+	for _, userBadge := range m.sortedUserBadges() {
+		if userBadge.UserID != userID {
+			continue
+		}
+
+		if filter.BadgeName != "" && !strings.EqualFold(string(m.badges[userBadge.BadgeID].Name), filter.BadgeName) {
+			continue
+		}
+
+		if (!filter.IncludeHistory && userBadge.SupersededAt != nil) || (!filter.IncludeRevoked && userBadge.IsRevoked()) {
+			continue
+		}
+
+		badges = append(badges, m.loadUserBadge(userBadge))
+	}
+
+	return badges, nil
+}
+
+func (m *MemoryStore) GetUserBadgeByID(badgeID uint, userID string, includeRevoked bool) (*models.UserBadge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	userBadge, ok := m.userBadges[badgeID]
+	if !ok || userBadge.UserID != userID || (!includeRevoked && userBadge.IsRevoked()) {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	userBadge = m.loadUserBadge(userBadge)
+
+	return &userBadge, nil
+}
+
+func (m *MemoryStore) GetUserBadgesForSkill(userID string, skillID uint) ([]models.UserBadge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	badges := []models.UserBadge{}
+	for _, userBadge := range m.sortedUserBadges() {
+		if userBadge.UserID == userID && m.badges[userBadge.BadgeID].SkillID == skillID && !userBadge.IsRevoked() {
+			badges = append(badges, m.loadUserBadge(userBadge))
+		}
+	}
+
+	sort.SliceStable(badges, func(i, j int) bool {
+		return badges[i].CreatedAt.After(badges[j].CreatedAt)
+	})
+
+	return badges, nil
+}
+
+func (m *MemoryStore) FindUserBadge(badgeID uint) (*models.UserBadge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	userBadge, ok := m.userBadges[badgeID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	userBadge = m.loadUserBadge(userBadge)
+
+	return &userBadge, nil
+}
+
+// AssignBadge applies models.DecideAward like models.AssignBadge. Nothing is
+// stored when building the mails fails.
+func (m *MemoryStore) AssignBadge(userID string, assessmentID uint, mails MailBuilder) (*models.UserBadge, models.AssignOutcome, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	taken, ok := m.taken[assessmentID]
+	if !ok {
+		return nil, "", models.ErrAssessmentNotFound
+	}
+
+	taken.Assessment, ok = m.assessments[taken.AssessmentID]
+	if !ok {
+		return nil, "", models.ErrAssessmentNotFound
+	}
+
+	var userBadges []models.UserBadge
+	for _, userBadge := range m.sortedUserBadges() {
+		if userBadge.UserID == userID {
+			userBadges = append(userBadges, m.loadUserBadge(userBadge))
+		}
+	}
+
+	award, err := models.DecideAward(taken, userID, m.skillLadder(taken.Assessment.SkillID), userBadges)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !award.Outcome.Awarded() {
+		return award.Kept, award.Outcome, nil
+	}
+
+	now := m.now()
+	newUserBadge := models.UserBadge{
+		ID:               m.nextID(0),
+		UserID:           userID,
+		BadgeID:          award.Badge.ID,
+		UserAssessmentID: assessmentID,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	awarded := m.loadUserBadge(newUserBadge)
+
+	var queued []models.MailLog
+	if mails != nil {
+		if queued, err = mails(&awarded, award.Outcome); err != nil {
+			return nil, "", err
+		}
+	}
+
+	m.userBadges[newUserBadge.ID] = newUserBadge
+	if award.Superseded != nil {
+		superseded := m.userBadges[award.Superseded.ID]
+		superseded.SupersededAt, superseded.SupersededByID = &now, &newUserBadge.ID
+		m.userBadges[award.Superseded.ID] = superseded
+	}
+
+	for i := range queued {
+		m.enqueueMail(&queued[i])
+	}
+
+	return &awarded, award.Outcome, nil
+}
+
+func (m *MemoryStore) RevokeUserBadge(badgeID uint, revokedBy string, reason string) (*models.UserBadge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	badge, ok := m.userBadges[badgeID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	if badge.IsRevoked() {
+		return nil, models.ErrBadgeAlreadyRevoked
+	}
+
+	wasCurrent := badge.IsCurrent()
+
+	now := m.now()
+	badge.RevokedAt, badge.RevokedBy, badge.RevocationReason = &now, revokedBy, reason
+	m.userBadges[badge.ID] = badge
+
+	if wasCurrent {
+		for id, previous := range m.userBadges {
+			if previous.SupersededByID != nil && *previous.SupersededByID == badge.ID && !previous.IsRevoked() {
+				previous.SupersededAt, previous.SupersededByID = nil, nil
+				m.userBadges[id] = previous
+			}
+		}
+	}
+
+	revoked := m.loadUserBadge(badge)
+
+	return &revoked, nil
+}
+
+func (m *MemoryStore) FindSkillById(skillID uint) (*models.Skill, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	skill, ok := m.skills[skillID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &skill, nil
+}
+
+func (m *MemoryStore) FindUserAssessment(userAssessmentID uint) (*models.UserAssessment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	taken, ok := m.taken[userAssessmentID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	taken.Assessment = m.assessments[taken.AssessmentID]
+
+	return &taken, nil
+}
+
+func (m *MemoryStore) EnqueueMail(mail *models.MailLog) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.enqueueMail(mail)
+
+	return nil
+}
+
+// ClaimDueMails pushes the next attempt of the claimed mails back by lease,
+// like models.ClaimDueMails, so that they are not claimed again before it
+// ends.
+func (m *MemoryStore) ClaimDueMails(now time.Time, limit int, lease time.Duration) ([]models.MailLog, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	due := []int{}
+	for i, mail := range m.mails {
+		if mail.RequestOrigin == models.MailRequestOrigin && mail.Status == models.MailPending && !mail.NextAttemptAt.After(now) {
+			due = append(due, i)
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return m.mails[due[i]].NextAttemptAt.Before(m.mails[due[j]].NextAttemptAt)
+	})
+
+	if limit > 0 && limit < len(due) {
+		due = due[:limit]
+	}
+
+	mails := make([]models.MailLog, len(due))
+	for i, index := range due {
+		mails[i] = m.mails[index]
+		m.mails[index].NextAttemptAt = now.Add(lease)
+	}
+
+	return mails, nil
+}
+
+func (m *MemoryStore) MarkMailDelivered(mail *models.MailLog, deliveredAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if stored := m.mail(mail.ID); stored != nil {
+		stored.Status, stored.Attempts = models.MailComplete, mail.Attempts+1
+		stored.DeliveredAt, stored.LastError = &deliveredAt, ""
+		stored.UpdatedAt = m.now()
+	}
+
+	return nil
+}
+
+func (m *MemoryStore) MarkMailAttemptFailed(mail *models.MailLog, deliveryErr error, nextAttempt *time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := m.mail(mail.ID)
+	if stored == nil {
+		return nil
+	}
+
+	stored.Attempts, stored.LastError = mail.Attempts+1, deliveryErr.Error()
+	if nextAttempt == nil {
+		stored.Status = models.MailFailed
+	} else {
+		stored.NextAttemptAt = *nextAttempt
+	}
+	stored.UpdatedAt = m.now()
+
+	return nil
+}
+
+func (m *MemoryStore) ListMailLogs(filter models.MailLogFilter) ([]models.MailLog, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mails := []models.MailLog{}
+	for i := len(m.mails) - 1; i >= 0; i-- {
+		mail := m.mails[i]
+		if mail.RequestOrigin == models.MailRequestOrigin && (filter.Status == "" || mail.Status == filter.Status) {
+			mails = append(mails, mail)
+		}
+	}
+
+	if filter.Offset >= len(mails) {
+		return []models.MailLog{}, nil
+	}
+	mails = mails[filter.Offset:]
+
+	if filter.Limit > 0 && filter.Limit < len(mails) {
+		mails = mails[:filter.Limit]
+	}
+
+	return mails, nil
+}
+
+func (m *MemoryStore) FindMailLog(mailID uint) (*models.MailLog, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, mail := range m.mails {
+		if mail.ID == mailID && mail.RequestOrigin == models.MailRequestOrigin {
+			return &mail, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MemoryStore) ReplayMail(mail *models.MailLog) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := m.mail(mail.ID)
+	if stored == nil || stored.Status != models.MailFailed {
+		return models.ErrMailNotReplayable
+	}
+
+	now := m.now()
+	stored.Status, stored.Attempts, stored.NextAttemptAt = models.MailPending, 0, now
+	stored.UpdatedAt = now
+	mail.Status, mail.Attempts, mail.NextAttemptAt = models.MailPending, 0, now
+
+	return nil
+}
+
+func (m *MemoryStore) ListRoles() ([]models.RoleWithPermissions, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	roles := []models.RoleWithPermissions{}
+	for _, role := range m.roles {
+		roles = append(roles, models.RoleWithPermissions{Role: role, Permissions: sortedNames(m.rolePermissions[role.ID])})
+	}
+
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+
+	return roles, nil
+}
+
+func (m *MemoryStore) FindRoleByID(roleID uint) (*models.Role, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	role, ok := m.roles[roleID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &role, nil
+}
+
+func (m *MemoryStore) CreateRole(name string, permissions []string) (*models.RoleWithPermissions, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, role := range m.roles {
+		if role.Name == name {
+			return nil, models.ErrRoleExists
+		}
+	}
+
+	granted := map[string]bool{}
+	for _, permission := range permissions {
+		if !models.ValidPermissionName(permission) {
+			return nil, models.ErrInvalidPermission
+		}
+		granted[permission] = true
+	}
+
+	role := models.Role{ID: m.nextID(0), Name: name}
+	m.roles[role.ID] = role
+	m.rolePermissions[role.ID] = granted
+
+	return &models.RoleWithPermissions{Role: role, Permissions: sortedNames(granted)}, nil
+}
+
+func (m *MemoryStore) DeleteRole(roleID uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.roles, roleID)
+	delete(m.rolePermissions, roleID)
+	for _, roles := range m.userRoles {
+		delete(roles, roleID)
+	}
+
+	return nil
+}
+
+func (m *MemoryStore) RolePermissionNames(roleID uint) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return sortedNames(m.rolePermissions[roleID]), nil
+}
+
+func (m *MemoryStore) GrantRolePermission(roleID uint, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !models.ValidPermissionName(name) {
+		return models.ErrInvalidPermission
+	}
+
+	if m.rolePermissions[roleID] == nil {
+		m.rolePermissions[roleID] = map[string]bool{}
+	}
+	m.rolePermissions[roleID][name] = true
+
+	return nil
+}
+
+func (m *MemoryStore) RevokeRolePermission(roleID uint, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.rolePermissions[roleID], name)
+
+	return nil
+}
+
+func (m *MemoryStore) GetUserGrants(userID string) (*models.UserGrants, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	roles := map[string]bool{}
+	permissions := map[string]bool{}
+	for roleID := range m.userRoles[userID] {
+		roles[m.roles[roleID].Name] = true
+		for name := range m.rolePermissions[roleID] {
+			permissions[name] = true
+		}
+	}
+
+	for name := range m.userPermissions[userID] {
+		permissions[name] = true
+	}
+
+	return &models.UserGrants{
+		UserID:      userID,
+		Roles:       sortedNames(roles),
+		Direct:      sortedNames(m.userPermissions[userID]),
+		Permissions: sortedNames(permissions),
+	}, nil
+}
+
+func (m *MemoryStore) AssignUserRole(userID string, roleID uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.userRoles[userID][roleID] {
+		return models.ErrRoleAlreadyGranted
+	}
+
+	if m.userRoles[userID] == nil {
+		m.userRoles[userID] = map[uint]bool{}
+	}
+	m.userRoles[userID][roleID] = true
+
+	return nil
+}
+
+func (m *MemoryStore) UnassignUserRole(userID string, roleID uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.userRoles[userID], roleID)
+
+	return nil
+}
+
+func (m *MemoryStore) GrantUserPermission(userID string, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !models.ValidPermissionName(name) {
+		return models.ErrInvalidPermission
+	}
+
+	if m.userPermissions[userID] == nil {
+		m.userPermissions[userID] = map[string]bool{}
+	}
+	m.userPermissions[userID][name] = true
+
+	return nil
+}
+
+func (m *MemoryStore) RevokeUserPermission(userID string, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.userPermissions[userID], name)
+
+	return nil
+}
+
+func (m *MemoryStore) ListAPIKeys() ([]models.APIKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := []models.APIKey{}
+	for _, key := range m.apiKeys {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.After(keys[j].CreatedAt)
+		}
+		return keys[i].ID > keys[j].ID
+	})
+
+	return keys, nil
+}
+
+func (m *MemoryStore) CreateAPIKey(key models.APIKey) (*models.APIKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	newKey := models.APIKey{
+		ID:        m.nextID(0),
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Hash:      key.Hash,
+		Scopes:    key.Scopes,
+		CreatedBy: key.CreatedBy,
+		ExpiresAt: key.ExpiresAt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.apiKeys[newKey.ID] = newKey
+
+	return &newKey, nil
+}
+
+func (m *MemoryStore) RevokeAPIKey(keyID uint) (*models.APIKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key, ok := m.apiKeys[keyID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	if key.Revoked() {
+		return &key, models.ErrAPIKeyRevoked
+	}
+
+	now := m.now()
+	key.RevokedAt, key.UpdatedAt = &now, now
+	m.apiKeys[keyID] = key
+
+	return &key, nil
+}
+
+func (m *MemoryStore) FindIdempotencyKey(key string, userID string) (*models.IdempotencyKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, record := range m.idempotencyKeys {
+		if record.Key == key && record.UserID == userID {
+			return &record, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MemoryStore) ReserveIdempotencyKey(record *models.IdempotencyKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.idempotencyKeys {
+		if existing.Key == record.Key && existing.UserID == record.UserID {
+			return models.ErrIdempotencyKeyExists
+		}
+	}
+
+	now := m.now()
+	record.ID = m.nextID(0)
+	record.CreatedAt, record.UpdatedAt = now, now
+	m.idempotencyKeys[record.ID] = *record
+
+	return nil
+}
+
+func (m *MemoryStore) CompleteIdempotencyKey(record *models.IdempotencyKey, statusCode int, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.idempotencyKeys[record.ID]
+	if !ok {
+		return nil
+	}
+
+	stored.StatusCode, stored.ResponseBody, stored.UpdatedAt = statusCode, body, m.now()
+	m.idempotencyKeys[record.ID] = stored
+	record.StatusCode, record.ResponseBody = statusCode, body
+
+	return nil
+}
+
+func (m *MemoryStore) DeleteIdempotencyKey(record *models.IdempotencyKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.idempotencyKeys, record.ID)
+
+	return nil
+}
+
+// enqueueMail adds a mail to the outbox like models.EnqueueMail.
+func (m *MemoryStore) enqueueMail(mail *models.MailLog) {
+	now := m.now()
+	mail.ID = m.nextID(0)
+	mail.Status = models.MailPending
+	mail.RequestOrigin = models.MailRequestOrigin
+	if mail.NextAttemptAt.IsZero() {
+		mail.NextAttemptAt = now
+	}
+	mail.CreatedAt, mail.UpdatedAt = now, now
+
+	m.mails = append(m.mails, *mail)
+}
+
+func (m *MemoryStore) mail(mailID uint) *models.MailLog {
+	for i := range m.mails {
+		if m.mails[i].ID == mailID {
+			return &m.mails[i]
+		}
+	}
+
+	return nil
+}
+
+func (m *MemoryStore) setScores(badgeID uint, minScore, maxScore float64) {
+	if badge, ok := m.badges[badgeID]; ok {
+		badge.MinScore, badge.MaxScore = minScore, maxScore
+		m.badges[badgeID] = badge
+	}
+}
+
+func (m *MemoryStore) skillLadder(skillID uint) []models.SkillBadge {
+	ladder := []models.SkillBadge{}
+	for _, badge := range m.badges {
+		if badge.SkillID == skillID {
+			badge.Tier = m.tier(badge.TierID)
+			ladder = append(ladder, badge)
+		}
+	}
+
+	models.SortLadder(ladder)
+
+	return ladder
+}
+
+// tierSkills returns the skills with a badge on the tier.
+func (m *MemoryStore) tierSkills(tierID uint) []uint {
+	seen := map[uint]bool{}
+	skillIDs := []uint{}
+	for _, badge := range m.badges {
+		if badge.TierID == tierID && !seen[badge.SkillID] {
+			seen[badge.SkillID] = true
+			skillIDs = append(skillIDs, badge.SkillID)
+		}
+	}
+
+	return skillIDs
+}
+
+// tierLadder falls back to the default ladder for skills without their own
+// tiers, like models.GetTierLadder.
+func (m *MemoryStore) tierLadder(skillID uint) []models.BadgeTier {
+	tiers := []models.BadgeTier{}
+	for _, tier := range m.tiers {
+		if tierSkillID(tier) == skillID {
+			tiers = append(tiers, tier)
+		}
+	}
+
+	if len(tiers) == 0 && skillID != 0 {
+		return m.tierLadder(0)
+	}
+
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].Rank < tiers[j].Rank })
+
+	return tiers
+}
+
+func (m *MemoryStore) tierConflicts(tier models.BadgeTier) bool {
+	for _, existing := range m.tiers {
+		if existing.ID == tier.ID || tierSkillID(existing) != tierSkillID(tier) {
+			continue
+		}
+
+		if strings.EqualFold(existing.Name, tier.Name) || existing.Rank == tier.Rank {
+			return true
+		}
+	}
+
+	return false
+}
+
+func tierSkillID(tier models.BadgeTier) uint {
+	if tier.SkillID == nil {
+		return 0
+	}
+
+	return *tier.SkillID
+}
+
+func (m *MemoryStore) tier(tierID uint) *models.BadgeTier {
+	tier, ok := m.tiers[tierID]
+	if !ok {
+		return nil
+	}
+
+	return &tier
+}
+
+// loadBadge returns a copy of the badge with its skill and tier.
+func (m *MemoryStore) loadBadge(badge models.SkillBadge) models.SkillBadge {
+	if skill, ok := m.skills[badge.SkillID]; ok {
+		badge.Skill = &skill
+	}
+	badge.Tier = m.tier(badge.TierID)
+
+	return badge
+}
+
+// loadUserBadge returns a copy of the user badge with its user, badge and
+// assessment.
+func (m *MemoryStore) loadUserBadge(userBadge models.UserBadge) models.UserBadge {
+	if user, ok := m.users[userBadge.UserID]; ok {
+		userBadge.User = &user
+	}
+
+	if badge, ok := m.badges[userBadge.BadgeID]; ok {
+		badge = m.loadBadge(badge)
+		userBadge.Badge = &badge
+	}
+
+	if taken, ok := m.taken[userBadge.UserAssessmentID]; ok {
+		taken.Assessment = m.assessments[taken.AssessmentID]
+		userBadge.UserAssessment = &taken
+	}
+
+	return userBadge
+}
+
+func (m *MemoryStore) sortedUserBadges() []models.UserBadge {
+	userBadges := make([]models.UserBadge, 0, len(m.userBadges))
+	for _, userBadge := range m.userBadges {
+		userBadges = append(userBadges, userBadge)
+	}
+
+	sort.Slice(userBadges, func(i, j int) bool { return userBadges[i].ID < userBadges[j].ID })
+
+	return userBadges
+}
+
+// sortedNames returns the names of the set in order.
+func sortedNames(set map[string]bool) []string {
+	names := []string{}
+	for name := range set {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
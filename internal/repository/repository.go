@@ -0,0 +1,101 @@
+// Package repository defines how the handlers reach the badges, skills,
+// assessments and admin records they work on, so that they can run against
+// the database or an in-memory store.
+package repository
+
+import (
+	"demerzel-badges/internal/models"
+	"time"
+)
+
+// BadgeRepository stores the badges of the skills and the tier ladders they
+// are built on.
+type BadgeRepository interface {
+	ListBadges(skillID uint) ([]models.SkillBadge, error)
+	FindBadgeByID(badgeID uint) (*models.SkillBadge, error)
+	BadgeExists(skillID uint, tierID uint) bool
+	CreateBadge(badge models.SkillBadge) (*models.SkillBadge, error)
+	UpdateBadgeScores(badge *models.SkillBadge, minScore, maxScore float64) error
+	DeleteBadge(badgeID uint) error
+	GetSkillLadder(skillID uint) ([]models.SkillBadge, error)
+	UpdateLadderScores(badges []models.SkillBadge) error
+
+	GetTierLadder(skillID uint) ([]models.BadgeTier, error)
+	FindTierByID(tierID uint) (*models.BadgeTier, error)
+	FindTierByName(skillID uint, name string) (*models.BadgeTier, error)
+	TierInLadder(tier *models.BadgeTier, skillID uint) bool
+	CreateTier(tier models.BadgeTier) (*models.BadgeTier, error)
+	UpdateTier(tier *models.BadgeTier, requireContiguous bool) error
+	DeleteTier(tierID uint) error
+}
+
+// MailBuilder returns the notifications of a badge that has just been
+// awarded, AssignBadge queues them together with the badge.
+type MailBuilder func(userBadge *models.UserBadge, outcome models.AssignOutcome) ([]models.MailLog, error)
+
+// UserBadgeRepository stores the badges awarded to users.
+type UserBadgeRepository interface {
+	GetUserBadges(userID string, filter models.UserBadgeFilter) ([]models.UserBadge, error)
+	GetUserBadgeByID(badgeID uint, userID string, includeRevoked bool) (*models.UserBadge, error)
+	GetUserBadgesForSkill(userID string, skillID uint) ([]models.UserBadge, error)
+	FindUserBadge(badgeID uint) (*models.UserBadge, error)
+	AssignBadge(userID string, assessmentID uint, mails MailBuilder) (*models.UserBadge, models.AssignOutcome, error)
+	RevokeUserBadge(badgeID uint, revokedBy string, reason string) (*models.UserBadge, error)
+}
+
+// SkillRepository reads the skills badges are awarded for.
+type SkillRepository interface {
+	FindSkillById(skillID uint) (*models.Skill, error)
+}
+
+// AssessmentRepository reads the assessments taken by users.
+type AssessmentRepository interface {
+	FindUserAssessment(userAssessmentID uint) (*models.UserAssessment, error)
+}
+
+// MailRepository stores the outbox: the mails queued for delivery, claimed
+// by the dispatcher and replayed once dead-lettered.
+type MailRepository interface {
+	EnqueueMail(mail *models.MailLog) error
+	ClaimDueMails(now time.Time, limit int, lease time.Duration) ([]models.MailLog, error)
+	MarkMailDelivered(mail *models.MailLog, deliveredAt time.Time) error
+	MarkMailAttemptFailed(mail *models.MailLog, deliveryErr error, nextAttempt *time.Time) error
+
+	ListMailLogs(filter models.MailLogFilter) ([]models.MailLog, error)
+	FindMailLog(mailID uint) (*models.MailLog, error)
+	ReplayMail(mail *models.MailLog) error
+}
+
+// RoleRepository stores the roles and the permissions granted to users,
+// directly or through their roles.
+type RoleRepository interface {
+	ListRoles() ([]models.RoleWithPermissions, error)
+	FindRoleByID(roleID uint) (*models.Role, error)
+	CreateRole(name string, permissions []string) (*models.RoleWithPermissions, error)
+	DeleteRole(roleID uint) error
+	RolePermissionNames(roleID uint) ([]string, error)
+	GrantRolePermission(roleID uint, name string) error
+	RevokeRolePermission(roleID uint, name string) error
+
+	GetUserGrants(userID string) (*models.UserGrants, error)
+	AssignUserRole(userID string, roleID uint) error
+	UnassignUserRole(userID string, roleID uint) error
+	GrantUserPermission(userID string, name string) error
+	RevokeUserPermission(userID string, name string) error
+}
+
+// APIKeyRepository stores the API keys services authenticate with.
+type APIKeyRepository interface {
+	ListAPIKeys() ([]models.APIKey, error)
+	CreateAPIKey(key models.APIKey) (*models.APIKey, error)
+	RevokeAPIKey(keyID uint) (*models.APIKey, error)
+}
+
+// IdempotencyRepository stores the responses replayed to requests retried
+// with the same Idempotency-Key header.
+type IdempotencyRepository interface {
+	FindIdempotencyKey(key string, userID string) (*models.IdempotencyKey, error)
+	ReserveIdempotencyKey(record *models.IdempotencyKey) error
+	CompleteIdempotencyKey(record *models.IdempotencyKey, statusCode int, body string) error
+	DeleteIdempotencyKey(record *models.IdempotencyKey) error
+}
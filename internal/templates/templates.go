@@ -0,0 +1,253 @@
+// Package templates renders the emails sent by the service. Templates are
+// embedded in the binary and can be overridden, in part or entirely, by files
+// in the directory set in EMAIL_TEMPLATES_DIR.
+//
+// A template is looked up as <locale>/<name>/<variant>.<part>.tmpl, where the
+// part is subject, txt or html. Variants are tried from the most to the least
+// specific: skill-<skill>.tier-<tier>, skill-<skill>, tier-<tier> and
+// default, so that a tier or a skill can have its own subject or body.
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"unicode"
+)
+
+//go:embed emails
+var embedded embed.FS
+
+const defaultLocale = "en"
+
+var ErrTemplateNotFound = errors.New("template not found")
+
+// Email is a rendered email. HTML is empty when the template has no HTML part.
+type Email struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html,omitempty"`
+}
+
+// Options select the variant of a template.
+type Options struct {
+	Locale string
+	Tier   string
+	Skill  string
+}
+
+// BadgeEmail is the data of the badge_awarded and badge_upgraded templates.
+type BadgeEmail struct {
+	Name             string
+	Skill            string
+	BadgeName        string
+	ProfileLink      string
+	VerificationLink string
+	ImageLink        string
+}
+
+type Renderer struct {
+	sources       []fs.FS
+	defaultLocale string
+}
+
+// New creates a renderer reading the templates in dir, if not empty, before
+// the embedded ones.
+func New(dir string, locale string) *Renderer {
+	emails, _ := fs.Sub(embedded, "emails")
+
+	r := &Renderer{sources: []fs.FS{emails}, defaultLocale: defaultLocale}
+	if dir != "" {
+		r.sources = append([]fs.FS{os.DirFS(dir)}, r.sources...)
+	}
+
+	if locale != "" {
+		r.defaultLocale = normalize(locale)
+	}
+
+	return r
+}
+
+// Config sets the directory of the templates overriding the embedded ones,
+// EMAIL_TEMPLATES_DIR, and the locale of the recipients without one,
+// EMAIL_DEFAULT_LOCALE.
+type Config struct {
+	Dir           string
+	DefaultLocale string
+}
+
+func FromConfig(cfg Config) *Renderer {
+	return New(cfg.Dir, cfg.DefaultLocale)
+}
+
+// Render renders the subject, text and HTML parts of a template. The subject
+// and text parts are required.
+func (r *Renderer) Render(name string, data interface{}, options Options) (Email, error) {
+	var email Email
+
+	subject, err := r.lookup(name, "subject", options)
+	if err != nil {
+		return email, err
+	}
+
+	text, err := r.lookup(name, "txt", options)
+	if err != nil {
+		return email, err
+	}
+
+	if email.Subject, err = renderText(subject, data); err != nil {
+		return email, err
+	}
+	// The subject ends up in a header, it must fit on one line.
+	email.Subject = strings.Join(strings.Fields(email.Subject), " ")
+
+	if email.Text, err = renderText(text, data); err != nil {
+		return email, err
+	}
+
+	html, err := r.lookup(name, "html", options)
+	if errors.Is(err, ErrTemplateNotFound) {
+		return email, nil
+	}
+
+	if err != nil {
+		return email, err
+	}
+
+	tmpl, err := htmltemplate.New(name).Parse(html)
+	if err != nil {
+		return email, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return email, err
+	}
+	email.HTML = buf.String()
+
+	return email, nil
+}
+
+// Names lists the templates available in the default locale.
+func (r *Renderer) Names() []string {
+	seen := map[string]bool{}
+	for _, source := range r.sources {
+		entries, _ := fs.ReadDir(source, r.defaultLocale)
+		for _, entry := range entries {
+			if entry.IsDir() {
+				seen[entry.Name()] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (r *Renderer) lookup(name string, part string, options Options) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\.`) {
+		return "", ErrTemplateNotFound
+	}
+
+	for _, locale := range r.locales(options.Locale) {
+		for _, variant := range variants(options) {
+			path := locale + "/" + name + "/" + variant + "." + part + ".tmpl"
+
+			for _, source := range r.sources {
+				content, err := fs.ReadFile(source, path)
+				if err == nil {
+					return string(content), nil
+				}
+
+				if !errors.Is(err, fs.ErrNotExist) {
+					return "", err
+				}
+			}
+		}
+	}
+
+	return "", ErrTemplateNotFound
+}
+
+// locales returns the locales to try, "fr-ca" is followed by "fr" and the
+// default locale.
+func (r *Renderer) locales(locale string) []string {
+	var locales []string
+	add := func(locale string) {
+		if locale == "" {
+			return
+		}
+
+		for _, l := range locales {
+			if l == locale {
+				return
+			}
+		}
+		locales = append(locales, locale)
+	}
+
+	locale = normalize(locale)
+	add(locale)
+	if base, _, found := strings.Cut(locale, "-"); found {
+		add(base)
+	}
+	add(r.defaultLocale)
+	add(defaultLocale)
+
+	return locales
+}
+
+func variants(options Options) []string {
+	skill := normalize(options.Skill)
+	tier := normalize(options.Tier)
+
+	var variants []string
+	if skill != "" && tier != "" {
+		variants = append(variants, "skill-"+skill+".tier-"+tier)
+	}
+
+	if skill != "" {
+		variants = append(variants, "skill-"+skill)
+	}
+
+	if tier != "" {
+		variants = append(variants, "tier-"+tier)
+	}
+
+	return append(variants, "default")
+}
+
+// normalize turns "Backend Development" into "backend-development", "UI/UX"
+// into "ui-ux" and "fr_CA" into "fr-ca".
+func normalize(value string) string {
+	words := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return strings.Join(words, "-")
+}
+
+func renderText(content string, data interface{}) (string, error) {
+	tmpl, err := texttemplate.New("").Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
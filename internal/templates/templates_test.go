@@ -0,0 +1,76 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var data = BadgeEmail{
+	Name:        "Jane <script>",
+	Skill:       "Backend",
+	BadgeName:   "Beginner",
+	ProfileLink: "https://zuri.team/portfolio/u1",
+}
+
+func TestRender(t *testing.T) {
+	renderer := New("", "")
+
+	email, err := renderer.Render("badge_awarded", data, Options{Tier: "Beginner", Skill: "Backend"})
+	assert.NoError(t, err)
+	assert.Equal(t, "You earned the Beginner badge in Backend", email.Subject)
+	assert.Contains(t, email.Text, "Hi Jane <script>,")
+	assert.Contains(t, email.HTML, "Hi Jane &lt;script&gt;,")
+	assert.Contains(t, email.HTML, `href="https://zuri.team/portfolio/u1"`)
+
+	email, err = renderer.Render("badge_awarded", data, Options{Tier: "Expert", Skill: "Backend"})
+	assert.NoError(t, err)
+	assert.Equal(t, "You are now a Backend expert", email.Subject, "tier override should replace the subject")
+	assert.Contains(t, email.Text, "Congratulations!", "parts without override should use the default")
+
+	_, err = renderer.Render("../badge_awarded", data, Options{})
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+
+	_, err = renderer.Render("welcome", data, Options{})
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+}
+
+func TestRenderLocale(t *testing.T) {
+	renderer := New("", "")
+
+	email, err := renderer.Render("badge_upgraded", data, Options{Locale: "fr-CA"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Votre badge Backend passe au niveau Beginner", email.Subject)
+
+	email, err = renderer.Render("badge_upgraded", data, Options{Locale: "yo"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Your Backend badge was upgraded to Beginner", email.Subject)
+
+	email, err = New("", "fr").Render("badge_upgraded", data, Options{Locale: "yo"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Votre badge Backend passe au niveau Beginner", email.Subject)
+}
+
+func TestRenderOverrideDirectory(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "en", "badge_awarded")
+	assert.NoError(t, os.MkdirAll(path, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(path, "skill-ui-ux.tier-beginner.subject.tmpl"), []byte("Welcome to\n{{.Skill}}"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(path, "default.txt.tmpl"), []byte("{{.Unknown}}"), 0o644))
+
+	renderer := New(dir, "")
+
+	_, err := renderer.Render("badge_awarded", data, Options{Tier: "Beginner", Skill: "UI/UX"})
+	assert.Error(t, err, "missing fields should fail the rendering")
+
+	assert.NoError(t, os.WriteFile(filepath.Join(path, "default.txt.tmpl"), []byte("Hello {{.Name}}"), 0o644))
+
+	email, err := renderer.Render("badge_awarded", BadgeEmail{Skill: "UI/UX", Name: "Jane"}, Options{Tier: "Beginner", Skill: "UI/UX"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Welcome to UI/UX", email.Subject)
+	assert.Equal(t, "Hello Jane", email.Text)
+
+	assert.Equal(t, []string{"badge_awarded", "badge_upgraded"}, renderer.Names())
+}
@@ -0,0 +1,98 @@
+// Package urls builds the absolute links the service hands out, to the
+// user's portfolio and to the public API, so that emails, Open Badges
+// documents and API responses all agree.
+package urls
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Builder holds the base URLs. ProfilePath is appended to PortfolioURL, with
+// {user_id} and {username} replaced by those of the user. APIURL is the
+// public URL of the API, never derived from the request since its Host header
+// is chosen by the client.
+type Builder struct {
+	PortfolioURL string
+	ProfilePath  string
+	APIURL       string
+}
+
+// API returns the absolute URL of an API path.
+func (b Builder) API(path string, args ...interface{}) string {
+	return strings.TrimRight(b.APIURL, "/") + "/" + strings.TrimLeft(fmt.Sprintf(path, args...), "/")
+}
+
+// ID returns the ID at the end of an API link built for path, such as
+// "/credentials/%d", and whether the link matches it.
+func (b Builder) ID(link string, path string) (uint, bool) {
+	prefix := b.API(strings.TrimSuffix(path, "%d"))
+	if !strings.HasPrefix(link, prefix) {
+		return 0, false
+	}
+
+	id, err := strconv.ParseUint(strings.TrimPrefix(link, prefix), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return uint(id), true
+}
+
+// Profile is the portfolio page of a user.
+func (b Builder) Profile(userID string, username string) string {
+	path := strings.NewReplacer(
+		"{user_id}", url.PathEscape(userID),
+		"{username}", url.PathEscape(username),
+	).Replace(b.ProfilePath)
+
+	return strings.TrimRight(b.PortfolioURL, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// Verification is the public page confirming that a user badge is valid.
+func (b Builder) Verification(userBadgeID uint) string {
+	return b.API("/badges/verify/%d", userBadgeID)
+}
+
+func (b Builder) BadgeImage(badgeID uint) string {
+	return b.API("/badges/%d/image.png", badgeID)
+}
+
+const (
+	AssertionPath  = "/openbadges/assertions/%d"
+	CredentialPath = "/credentials/%d"
+)
+
+func (b Builder) Assertion(userBadgeID uint) string {
+	return b.API(AssertionPath, userBadgeID)
+}
+
+func (b Builder) Credential(userBadgeID uint) string {
+	return b.API(CredentialPath, userBadgeID)
+}
+
+// Links are the links of a user badge returned in API responses.
+type Links struct {
+	Profile      string `json:"profile,omitempty"`
+	Verification string `json:"verification"`
+	Image        string `json:"image"`
+	Assertion    string `json:"assertion"`
+	Credential   string `json:"credential"`
+}
+
+func (b Builder) UserBadgeLinks(userBadgeID uint, badgeID uint, userID string, username string) Links {
+	links := Links{
+		Verification: b.Verification(userBadgeID),
+		Image:        b.BadgeImage(badgeID),
+		Assertion:    b.Assertion(userBadgeID),
+		Credential:   b.Credential(userBadgeID),
+	}
+
+	if userID != "" {
+		links.Profile = b.Profile(userID, username)
+	}
+
+	return links
+}
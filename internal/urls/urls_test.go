@@ -0,0 +1,39 @@
+package urls
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+	b := Builder{
+		PortfolioURL: "https://staging.zuri.team/",
+		ProfilePath:  "/@{username}",
+		APIURL:       "https://api.zuri.team/api/badges/",
+	}
+	assert.Equal(t, "https://api.zuri.team/api/badges/badges/verify/12", b.Verification(12))
+	assert.Equal(t, "https://staging.zuri.team/@jane%20doe", b.Profile("u1", "jane doe"))
+}
+
+func TestLinks(t *testing.T) {
+	b := Builder{PortfolioURL: "https://zuri.team", ProfilePath: "/portfolio/{user_id}", APIURL: "https://host/api/badges"}
+
+	assert.Equal(t, Links{
+		Profile:      "https://zuri.team/portfolio/u1",
+		Verification: "https://host/api/badges/badges/verify/12",
+		Image:        "https://host/api/badges/badges/3/image.png",
+		Assertion:    "https://host/api/badges/openbadges/assertions/12",
+		Credential:   "https://host/api/badges/credentials/12",
+	}, b.UserBadgeLinks(12, 3, "u1", "jane"))
+
+	id, ok := b.ID("https://host/api/badges/credentials/12", CredentialPath)
+	assert.True(t, ok)
+	assert.Equal(t, uint(12), id)
+
+	_, ok = b.ID("https://host/api/badges/openbadges/assertions/12", CredentialPath)
+	assert.False(t, ok)
+
+	_, ok = b.ID("https://host/api/badges/credentials/12/extra", CredentialPath)
+	assert.False(t, ok)
+}
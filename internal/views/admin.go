@@ -0,0 +1,124 @@
+package views
+
+import (
+	"demerzel-badges/internal/models"
+	"encoding/json"
+	"time"
+)
+
+// MailView is a record of the outbox, for the admins following deliveries.
+type MailView struct {
+	ID            uint              `json:"id"`
+	Email         string            `json:"email"`
+	MessageData   json.RawMessage   `json:"message_data"`
+	Status        models.MailStatus `json:"status"`
+	RequestOrigin string            `json:"request_origin"`
+	Channel       string            `json:"channel"`
+	UserBadgeID   *uint             `json:"user_badge_id,omitempty"`
+	Attempts      int               `json:"attempts"`
+	NextAttemptAt time.Time         `json:"next_attempt_at"`
+	LastError     string            `json:"last_error,omitempty"`
+	DeliveredAt   *time.Time        `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+func NewMailView(mail *models.MailLog) *MailView {
+	return &MailView{
+		ID:            mail.ID,
+		Email:         mail.Email,
+		MessageData:   mail.MessageData,
+		Status:        mail.Status,
+		RequestOrigin: mail.RequestOrigin,
+		Channel:       mail.Channel,
+		UserBadgeID:   mail.UserBadgeID,
+		Attempts:      mail.Attempts,
+		NextAttemptAt: mail.NextAttemptAt,
+		LastError:     mail.LastError,
+		DeliveredAt:   mail.DeliveredAt,
+		CreatedAt:     mail.CreatedAt,
+		UpdatedAt:     mail.UpdatedAt,
+	}
+}
+
+func NewMailViews(mails []models.MailLog) []MailView {
+	result := make([]MailView, len(mails))
+	for i := range mails {
+		result[i] = *NewMailView(&mails[i])
+	}
+
+	return result
+}
+
+// APIKeyView describes a key without its hash.
+type APIKeyView struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  string     `json:"created_by"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func NewAPIKeyView(key *models.APIKey) *APIKeyView {
+	return &APIKeyView{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		CreatedBy:  key.CreatedBy,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+		UpdatedAt:  key.UpdatedAt,
+	}
+}
+
+func NewAPIKeyViews(keys []models.APIKey) []APIKeyView {
+	result := make([]APIKeyView, len(keys))
+	for i := range keys {
+		result[i] = *NewAPIKeyView(&keys[i])
+	}
+
+	return result
+}
+
+type RoleView struct {
+	ID          uint     `json:"id"`
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+}
+
+func NewRoleView(role *models.RoleWithPermissions) *RoleView {
+	return &RoleView{ID: role.ID, Name: role.Name, Permissions: role.Permissions}
+}
+
+func NewRoleViews(roles []models.RoleWithPermissions) []RoleView {
+	result := make([]RoleView, len(roles))
+	for i := range roles {
+		result[i] = *NewRoleView(&roles[i])
+	}
+
+	return result
+}
+
+type UserGrantsView struct {
+	UserID            string   `json:"user_id"`
+	Roles             []string `json:"roles"`
+	DirectPermissions []string `json:"direct_permissions"`
+	Permissions       []string `json:"permissions"`
+}
+
+func NewUserGrantsView(grants *models.UserGrants) *UserGrantsView {
+	return &UserGrantsView{
+		UserID:            grants.UserID,
+		Roles:             grants.Roles,
+		DirectPermissions: grants.Direct,
+		Permissions:       grants.Permissions,
+	}
+}
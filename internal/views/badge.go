@@ -0,0 +1,123 @@
+package views
+
+import (
+	"demerzel-badges/internal/models"
+	"strings"
+	"time"
+)
+
+type SkillView struct {
+	ID            uint   `json:"id"`
+	CategoryName  string `json:"category_name"`
+	Description   string `json:"description"`
+	ParentSkillID *uint  `json:"parent_skill_id"`
+}
+
+func NewSkillView(skill *models.Skill) *SkillView {
+	if skill == nil {
+		return nil
+	}
+
+	return &SkillView{
+		ID:            skill.ID,
+		CategoryName:  skill.CategoryName,
+		Description:   skill.Description,
+		ParentSkillID: skill.ParentSkillID,
+	}
+}
+
+type TierView struct {
+	ID          uint      `json:"id"`
+	SkillID     *uint     `json:"skill_id"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"display_name"`
+	Description string    `json:"description"`
+	Rank        int       `json:"rank"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NewTierView(tier *models.BadgeTier) *TierView {
+	if tier == nil {
+		return nil
+	}
+
+	return &TierView{
+		ID:          tier.ID,
+		SkillID:     tier.SkillID,
+		Name:        tier.Name,
+		DisplayName: tier.DisplayName,
+		Description: tier.Description,
+		Rank:        tier.Rank,
+		CreatedAt:   tier.CreatedAt,
+		UpdatedAt:   tier.UpdatedAt,
+	}
+}
+
+func NewTierViews(tiers []models.BadgeTier) []TierView {
+	result := make([]TierView, len(tiers))
+	for i := range tiers {
+		result[i] = *NewTierView(&tiers[i])
+	}
+
+	return result
+}
+
+// SkillBadgeView is a badge definition. Names are lowercased, as they have
+// always been returned.
+type SkillBadgeView struct {
+	ID       uint       `json:"id"`
+	SkillID  uint       `json:"skill_id"`
+	TierID   uint       `json:"tier_id"`
+	Name     string     `json:"name"`
+	MinScore float64    `json:"min_score"`
+	MaxScore float64    `json:"max_score"`
+	Skill    *SkillView `json:"Skill,omitempty"`
+	Tier     *TierView  `json:"tier,omitempty"`
+}
+
+func NewSkillBadgeView(badge *models.SkillBadge) *SkillBadgeView {
+	if badge == nil {
+		return nil
+	}
+
+	return &SkillBadgeView{
+		ID:       badge.ID,
+		SkillID:  badge.SkillID,
+		TierID:   badge.TierID,
+		Name:     strings.ToLower(string(badge.Name)),
+		MinScore: badge.MinScore,
+		MaxScore: badge.MaxScore,
+		Skill:    NewSkillView(badge.Skill),
+		Tier:     NewTierView(badge.Tier),
+	}
+}
+
+func NewSkillBadgeViews(badges []models.SkillBadge) []SkillBadgeView {
+	result := make([]SkillBadgeView, len(badges))
+	for i := range badges {
+		result[i] = *NewSkillBadgeView(&badges[i])
+	}
+
+	return result
+}
+
+type LadderView struct {
+	SkillID  uint                 `json:"skill_id"`
+	Valid    bool                 `json:"valid"`
+	MinScore float64              `json:"min_score"`
+	MaxScore float64              `json:"max_score"`
+	Badges   []SkillBadgeView     `json:"badges"`
+	Issues   []models.LadderIssue `json:"issues"`
+}
+
+func NewLadderView(report models.LadderReport) LadderView {
+	return LadderView{
+		SkillID:  report.SkillID,
+		Valid:    report.Valid,
+		MinScore: report.MinScore,
+		MaxScore: report.MaxScore,
+		Badges:   NewSkillBadgeViews(report.Badges),
+		Issues:   report.Issues,
+	}
+}
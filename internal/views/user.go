@@ -0,0 +1,31 @@
+// Package views holds the representations of the models returned by the API.
+// Handlers never serialize models directly, so that fields such as password
+// hashes are only exposed when a view explicitly lists them.
+package views
+
+import (
+	"demerzel-badges/internal/models"
+)
+
+// PublicUserView is what other users may see of a user.
+type PublicUserView struct {
+	ID         string `json:"id"`
+	Username   string `json:"username"`
+	FirstName  string `json:"first_name"`
+	LastName   string `json:"last_name"`
+	ProfilePic string `json:"profile_pic"`
+}
+
+func NewPublicUserView(user *models.User) *PublicUserView {
+	if user == nil {
+		return nil
+	}
+
+	return &PublicUserView{
+		ID:         user.ID,
+		Username:   user.Username,
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		ProfilePic: user.ProfilePic,
+	}
+}
@@ -0,0 +1,119 @@
+package views
+
+import (
+	"demerzel-badges/internal/models"
+	"time"
+)
+
+type AssessmentView struct {
+	ID              uint          `json:"id"`
+	SkillID         uint          `json:"skill_id"`
+	Title           string        `json:"title"`
+	Description     string        `json:"description"`
+	StartDate       time.Time     `json:"start_date"`
+	EndDate         time.Time     `json:"end_date"`
+	DurationMinutes uint          `json:"duration_minutes"`
+	PassScore       uint          `json:"pass_score"`
+	Status          models.Status `json:"status"`
+}
+
+func NewAssessmentView(assessment *models.Assessment) *AssessmentView {
+	if assessment == nil {
+		return nil
+	}
+
+	return &AssessmentView{
+		ID:              assessment.ID,
+		SkillID:         assessment.SkillID,
+		Title:           assessment.Title,
+		Description:     assessment.Description,
+		StartDate:       assessment.StartDate,
+		EndDate:         assessment.EndDate,
+		DurationMinutes: assessment.DurationMinutes,
+		PassScore:       assessment.PassScore,
+		Status:          assessment.Status,
+	}
+}
+
+// UserAssessmentView keeps the misspelt submisssion_date key clients rely on.
+type UserAssessmentView struct {
+	ID             uint            `json:"id"`
+	UserID         string          `json:"user_id"`
+	AssessmentID   uint            `json:"assessment_id"`
+	Score          float64         `json:"score"`
+	TimeSpent      uint            `json:"time_spent"`
+	SubmissionDate time.Time       `json:"submisssion_date"`
+	Status         models.Status   `json:"status"`
+	Assessment     *AssessmentView `json:"Assessment"`
+}
+
+func NewUserAssessmentView(taken *models.UserAssessment) *UserAssessmentView {
+	if taken == nil {
+		return nil
+	}
+
+	view := &UserAssessmentView{
+		ID:             taken.ID,
+		UserID:         taken.UserID,
+		AssessmentID:   taken.AssessmentID,
+		Score:          taken.Score,
+		TimeSpent:      taken.TimeSpent,
+		SubmissionDate: taken.SubmissionDate,
+		Status:         taken.Status,
+	}
+
+	if taken.Assessment.ID != 0 {
+		view.Assessment = NewAssessmentView(&taken.Assessment)
+	}
+
+	return view
+}
+
+type UserBadgeView struct {
+	ID               uint                `json:"id"`
+	UserID           string              `json:"user_id"`
+	BadgeID          uint                `json:"badge_id"`
+	UserAssessmentID uint                `json:"user_assessment_id"`
+	SupersededAt     *time.Time          `json:"superseded_at"`
+	SupersededByID   *uint               `json:"superseded_by_id"`
+	RevokedAt        *time.Time          `json:"revoked_at,omitempty"`
+	RevokedBy        string              `json:"revoked_by,omitempty"`
+	RevocationReason string              `json:"revocation_reason,omitempty"`
+	CreatedAt        time.Time           `json:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at"`
+	User             *PublicUserView     `json:"user,omitempty"`
+	Badge            *SkillBadgeView     `json:"Badge"`
+	UserAssessment   *UserAssessmentView `json:"UserAssessment"`
+}
+
+func NewUserBadgeView(userBadge *models.UserBadge) *UserBadgeView {
+	if userBadge == nil {
+		return nil
+	}
+
+	return &UserBadgeView{
+		ID:               userBadge.ID,
+		UserID:           userBadge.UserID,
+		BadgeID:          userBadge.BadgeID,
+		UserAssessmentID: userBadge.UserAssessmentID,
+		SupersededAt:     userBadge.SupersededAt,
+		SupersededByID:   userBadge.SupersededByID,
+		RevokedAt:        userBadge.RevokedAt,
+		RevokedBy:        userBadge.RevokedBy,
+		RevocationReason: userBadge.RevocationReason,
+		CreatedAt:        userBadge.CreatedAt,
+		UpdatedAt:        userBadge.UpdatedAt,
+		User:             NewPublicUserView(userBadge.User),
+		Badge:            NewSkillBadgeView(userBadge.Badge),
+		UserAssessment:   NewUserAssessmentView(userBadge.UserAssessment),
+	}
+}
+
+func NewUserBadgeViews(userBadges []models.UserBadge) []UserBadgeView {
+	result := make([]UserBadgeView, len(userBadges))
+	for i := range userBadges {
+		result[i] = *NewUserBadgeView(&userBadges[i])
+	}
+
+	return result
+}
@@ -0,0 +1,227 @@
+package views_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"demerzel-badges/api"
+	"demerzel-badges/internal/auth"
+	"demerzel-badges/internal/credentials"
+	"demerzel-badges/internal/handlers"
+	"demerzel-badges/internal/middleware"
+	"demerzel-badges/internal/models"
+	"demerzel-badges/internal/repository"
+	"demerzel-badges/internal/urls"
+	"demerzel-badges/internal/views"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// secrets are set on every sensitive model field, no view may contain them.
+var secrets = []string{"s3cr3t-password", "s3cr3t-refresh-token", "jane@example.com", "s3cr3t-hash", "s3cr3t-section-order"}
+
+// sensitiveKeys must never appear as JSON keys in a response.
+var sensitiveKeys = []string{"password", "refresh_token", "hash", "section_order"}
+
+func testUserBadge() models.UserBadge {
+	now := time.Now()
+
+	return models.UserBadge{
+		ID:               1,
+		UserID:           "user-1",
+		BadgeID:          2,
+		UserAssessmentID: 3,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+		User: &models.User{
+			ID:           "user-1",
+			Username:     "jane",
+			Email:        "jane@example.com",
+			Password:     "s3cr3t-password",
+			RefreshToken: "s3cr3t-refresh-token",
+			SectionOrder: "s3cr3t-section-order",
+		},
+		Badge: &models.SkillBadge{
+			ID:    2,
+			Name:  "Expert",
+			Skill: &models.Skill{ID: 4, CategoryName: "Backend"},
+			Tier:  &models.BadgeTier{ID: 3, Name: "Expert", Rank: 3},
+		},
+		UserAssessment: &models.UserAssessment{
+			ID:         3,
+			UserID:     "user-1",
+			Score:      90,
+			Assessment: models.Assessment{ID: 5, Title: "Go"},
+		},
+	}
+}
+
+func assertNoSecrets(t *testing.T, name string, content []byte) {
+	for _, secret := range secrets {
+		assert.NotContains(t, string(content), secret, name)
+	}
+
+	var decoded interface{}
+	if assert.NoError(t, json.Unmarshal(content, &decoded), name) {
+		assertNoSensitiveKeys(t, name, decoded)
+	}
+}
+
+func assertNoSensitiveKeys(t *testing.T, name string, value interface{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range value {
+			for _, sensitive := range sensitiveKeys {
+				assert.NotEqual(t, sensitive, strings.ToLower(key), name)
+			}
+			assertNoSensitiveKeys(t, name, nested)
+		}
+	case []interface{}:
+		for _, nested := range value {
+			assertNoSensitiveKeys(t, name, nested)
+		}
+	}
+}
+
+// allowAll authorizes every token as user-1.
+type allowAll struct{}
+
+func (allowAll) Authorize(ctx context.Context, token string, permission string) (*auth.Principal, error) {
+	return &auth.Principal{UserID: "user-1"}, nil
+}
+
+// TestRoutesHideSecrets calls the routes served from the store, for a user
+// whose every sensitive field is set, and checks each JSON response.
+func TestRoutesHideSecrets(t *testing.T) {
+	userBadge := testUserBadge()
+
+	store := repository.NewMemoryStore()
+	store.AddUser(*userBadge.User)
+	skill := store.AddSkill(models.Skill{CategoryName: "Backend"})
+
+	tier, err := store.FindTierByName(skill.ID, "beginner")
+	assert.NoError(t, err)
+
+	badge, err := store.CreateBadge(models.SkillBadge{SkillID: skill.ID, TierID: tier.ID, Name: models.Badge(tier.Name), MinScore: 0, MaxScore: 50})
+	assert.NoError(t, err)
+
+	assessment := store.AddAssessment(models.Assessment{SkillID: skill.ID, Title: "Go", Status: models.Complete})
+	taken := store.AddUserAssessment(models.UserAssessment{
+		UserID:         "user-1",
+		AssessmentID:   assessment.ID,
+		Score:          30,
+		Status:         models.Complete,
+		SubmissionDate: time.Now(),
+	})
+
+	assigned, _, err := store.AssignBadge("user-1", taken.ID, nil)
+	assert.NoError(t, err)
+
+	h := &handlers.Handlers{
+		Badges:      store,
+		UserBadges:  store,
+		Skills:      store,
+		Assessments: store,
+
+		Mails:           store,
+		Roles:           store,
+		APIKeys:         store,
+		IdempotencyKeys: store,
+
+		Config: handlers.Config{
+			URLs:     urls.Builder{PortfolioURL: "https://zuri.team", ProfilePath: "/portfolio/{user_id}", APIURL: "http://localhost:8080/api/badges"},
+			Channels: []string{"messaging"},
+		},
+		Credentials: credentials.NewIssuer(ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)), "Zuri Portfolio", "https://zuri.team"),
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := api.SetupRoutes(api.Config{Permissions: middleware.DefaultPermissions()}, allowAll{}, h)
+
+	requests := []struct {
+		method  string
+		path    string
+		payload string
+		status  int
+	}{
+		{http.MethodPost, "/api/badges/user/badges", fmt.Sprintf(`{"assessment_id": %d}`, taken.ID), http.StatusOK},
+		{http.MethodGet, "/api/badges/badges", "", http.StatusOK},
+		{http.MethodGet, fmt.Sprintf("/api/badges/badges/%d", badge.ID), "", http.StatusOK},
+		{http.MethodPatch, fmt.Sprintf("/api/badges/badges/%d", badge.ID), `{"min_score": 0, "max_score": 60}`, http.StatusOK},
+		{http.MethodGet, fmt.Sprintf("/api/badges/skills/%d/ladder", skill.ID), "", http.StatusOK},
+		{http.MethodGet, "/api/badges/tiers", "", http.StatusOK},
+		{http.MethodGet, "/api/badges/user/badges", "", http.StatusOK},
+		{http.MethodGet, fmt.Sprintf("/api/badges/user/badges/skill/%d", skill.ID), "", http.StatusOK},
+		{http.MethodGet, fmt.Sprintf("/api/badges/user/badges/%d", assigned.ID), "", http.StatusOK},
+		{http.MethodGet, fmt.Sprintf("/api/badges/badges/verify/%d", assigned.ID), "", http.StatusOK},
+		{http.MethodGet, "/api/badges/openbadges/issuer", "", http.StatusOK},
+		{http.MethodGet, fmt.Sprintf("/api/badges/openbadges/badges/%d", badge.ID), "", http.StatusOK},
+		{http.MethodGet, fmt.Sprintf("/api/badges/openbadges/assertions/%d", assigned.ID), "", http.StatusOK},
+		{http.MethodGet, fmt.Sprintf("/api/badges/credentials/%d?format=json", assigned.ID), "", http.StatusOK},
+		{http.MethodPost, fmt.Sprintf("/api/badges/user/badges/%d/revoke", assigned.ID), `{"reason": "Assessment was compromised"}`, http.StatusOK},
+		{http.MethodGet, "/api/badges/user/badges?include=revoked", "", http.StatusOK},
+		{http.MethodGet, fmt.Sprintf("/api/badges/openbadges/assertions/%d", assigned.ID), "", http.StatusGone},
+	}
+
+	for _, request := range requests {
+		name := request.method + " " + request.path
+
+		req := httptest.NewRequest(request.method, request.path, bytes.NewBufferString(request.payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer token")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, request.status, w.Code, name)
+		if assert.Contains(t, w.Header().Get("Content-Type"), "json", name) {
+			assertNoSecrets(t, name, w.Body.Bytes())
+		}
+	}
+}
+
+func TestAPIKeyViewHidesHash(t *testing.T) {
+	key := models.APIKey{ID: 1, Name: "assessment-service", Prefix: "3f9a1c2e", Hash: "s3cr3t-hash", Scopes: []string{"badge.update.any"}}
+
+	for _, view := range []interface{}{views.NewAPIKeyView(&key), views.NewAPIKeyViews([]models.APIKey{key})} {
+		content, err := json.Marshal(view)
+		assert.NoError(t, err)
+		assertNoSecrets(t, "api key", content)
+	}
+}
+
+func TestUserBadgeView(t *testing.T) {
+	userBadge := testUserBadge()
+	view := views.NewUserBadgeView(&userBadge)
+
+	assert.Equal(t, "jane", view.User.Username)
+	assert.Equal(t, "expert", view.Badge.Name)
+	assert.Equal(t, "Backend", view.Badge.Skill.CategoryName)
+	assert.Equal(t, 3, view.Badge.Tier.Rank)
+	assert.Equal(t, "Go", view.UserAssessment.Assessment.Title)
+
+	userBadge.User = nil
+	userBadge.UserAssessment = nil
+	view = views.NewUserBadgeView(&userBadge)
+	assert.Nil(t, view.User)
+	assert.Nil(t, view.UserAssessment)
+}
+
+// Models are never meant to be serialized, but a mistake must not leak the
+// credentials of users either.
+func TestUserModelHidesSecrets(t *testing.T) {
+	userBadge := testUserBadge()
+
+	content, err := json.Marshal(userBadge)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(content), "s3cr3t-password")
+	assert.NotContains(t, string(content), "s3cr3t-refresh-token")
+}
@@ -1,22 +1,72 @@
 package main
 
 import (
+	"context"
 	"demerzel-badges/api"
 	"demerzel-badges/configs"
+	"demerzel-badges/internal/apikey"
+	"demerzel-badges/internal/db"
+	"demerzel-badges/internal/handlers"
+	"demerzel-badges/internal/notifier"
+	"demerzel-badges/internal/outbox"
+	"demerzel-badges/internal/rbac"
+	"demerzel-badges/internal/repository"
+	"demerzel-badges/internal/templates"
 	"fmt"
 	"log"
 	"os"
-	"strconv"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
-	configs.Load()
+	// go run . migrate up|down|status manages the schema without serving,
+	// go run . schema check compares it with the models. They only need the
+	// database settings.
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			os.Exit(runMigrate(loadDatabaseConfig(), os.Args[2:]))
+		case "schema":
+			os.Exit(runSchema(loadDatabaseConfig(), os.Args[2:]))
+		}
+	}
+
+	cfg, err := configs.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db.SetupDB(cfg.Database)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	authClient, err := rbac.New(db.DB, cfg.Auth)
+	if err != nil {
+		log.Fatal(fmt.Sprintf("Invalid auth configuration: %v", err))
+	}
+
+	// Services authenticate with API keys, users with their tokens
+	authClient = apikey.NewClient(db.DB, authClient)
 
-	port, err := strconv.Atoi(os.Getenv("PORT"))
+	notifiers, err := notifier.New(cfg.Notifier, templates.FromConfig(cfg.Templates))
 	if err != nil {
-		log.Fatal(fmt.Sprintf("Failed to conver PORT to integer: %v", err))
+		log.Fatal(fmt.Sprintf("Invalid notifier configuration: %v", err))
 	}
 
-	server := api.NewServer(uint16(port), api.SetupRoutes())
+	// Deliver the notifications queued in the outbox in the background
+	go outbox.NewDispatcher(repository.DBStore{DB: db.DB}, outbox.NotifierSender{Notifiers: notifiers}, cfg.Outbox).Run(ctx)
+
+	server := api.NewServer(cfg.Server.Port, api.SetupRoutes(cfg.Server, authClient, handlers.New(db.DB, cfg.Handlers)))
 	server.Listen()
 }
+
+func loadDatabaseConfig() db.Config {
+	cfg, err := configs.LoadDatabase()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return *cfg
+}
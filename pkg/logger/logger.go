@@ -31,6 +31,7 @@ func (f *Formatter) Format(entry *logrus.Entry) ([]byte, error) {
 	b.WriteString(" ")
 	b.WriteString(f.prefix)
 	b.WriteString(entry.Message)
+	b.WriteString("\n")
 
 	return b.Bytes(), nil
 }
@@ -17,3 +17,9 @@ func Error(c *gin.Context, code int, message string, data interface{}) {
 		"errors":  data,
 	})
 }
+
+// LinkedData writes a JSON-LD document as is, without the JSend envelope.
+func LinkedData(c *gin.Context, code int, document interface{}) {
+	c.Header("Content-Type", "application/ld+json; charset=utf-8")
+	c.JSON(code, document)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"demerzel-badges/internal/db"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+const schemaUsage = "usage: schema check"
+
+// runSchema runs the schema command and returns the exit code, 1 when the
+// database has drifted from the models.
+func runSchema(cfg db.Config, args []string) int {
+	if len(args) != 1 || args[0] != "check" {
+		fmt.Fprintln(os.Stderr, schemaUsage)
+		return 2
+	}
+
+	conn, err := db.Connect(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to the database: %v\n", err)
+		return 1
+	}
+
+	issues, err := db.CheckSchema(conn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to check the schema: %v\n", err)
+		return 1
+	}
+
+	if len(issues) == 0 {
+		fmt.Println("schema matches the models")
+		return 0
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TABLE\tCOLUMN\tISSUE\tDETAIL")
+	for _, issue := range issues {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", issue.Table, issue.Column, issue.Kind, issue.Detail)
+	}
+	w.Flush()
+
+	return 1
+}